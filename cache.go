@@ -0,0 +1,299 @@
+package gopom
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"sync"
+)
+
+// cacheFormat is part of the key of the entries stored on disk, so changing
+// it invalidates them when the serialized form changes.
+const cacheFormat = "gopom-cache-3"
+
+// CacheOptions configures a Cache.
+type CacheOptions struct {
+	// MaxEntries bounds the number of projects kept in memory, evicting the
+	// least recently used ones. Zero means no limit.
+	MaxEntries int
+	// ByContent keys the entries by the SHA-256 digest of the file content
+	// instead of its path, modification time and size. Files then have to
+	// be read on every lookup, but identical files share an entry, touching
+	// a file does not invalidate it, and a file rewritten with the same size
+	// within the resolution of modification times is not mistaken for the
+	// cached one.
+	ByContent bool
+	// Dir, if set, is a directory where parsed projects are also stored,
+	// so they can be reused across processes.
+	Dir string
+	// OnInvalidate is called with the path of every file an entry was
+	// parsed from whenever the entry is evicted or invalidated.
+	OnInvalidate func(path string)
+}
+
+// Cache is a concurrency-safe cache of parsed projects.
+//
+// Every call returns its own copy of the cached project, see Project.Clone,
+// so callers can modify it.
+type Cache struct {
+	opts CacheOptions
+
+	mu sync.Mutex
+	// entries are ordered from the most to the least recently used.
+	entries *list.List
+	byKey   map[string]*list.Element
+}
+
+type cacheEntry struct {
+	key string
+	// paths are the files the entry was parsed from, several when keying
+	// by content.
+	paths   []string
+	project *Project
+}
+
+// defaultCache is used by Effective, Resolve and LoadReactor unless they
+// are given another way to parse POMs. Entries are keyed by content, so it
+// never returns outdated projects.
+var defaultCache, _ = NewCache(CacheOptions{MaxEntries: 1000, ByContent: true})
+
+// diskEntry is the serialized form of a cached project. Comments and
+// element positions are not exported by the model, so they are stored
+// separately.
+type diskEntry struct {
+	Project  *Project
+	Comments map[string]Comments
+	Source   *sourceIndex
+}
+
+// NewCache returns an empty cache configured with opts.
+func NewCache(opts CacheOptions) (*Cache, error) {
+	if opts.Dir != "" {
+		if err := os.MkdirAll(opts.Dir, 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create the cache directory: %w", err)
+		}
+	}
+	return &Cache{
+		opts:    opts,
+		entries: list.New(),
+		byKey:   map[string]*list.Element{},
+	}, nil
+}
+
+// Parse returns the project parsed from the file at path, parsing it with
+// Parse only if it is not cached yet.
+func (c *Cache) Parse(path string) (*Project, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	key, content, err := c.key(path)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	if e, ok := c.byKey[key]; ok {
+		c.entries.MoveToFront(e)
+		entry := e.Value.(*cacheEntry)
+		entry.addPath(path)
+		c.mu.Unlock()
+		return entry.project.Clone(), nil
+	}
+	c.mu.Unlock()
+
+	p := c.load(key)
+	if p == nil {
+		if content == nil {
+			if content, err = os.ReadFile(path); err != nil {
+				return nil, err
+			}
+		}
+		if p, err = parseFile(path, content); err != nil {
+			return nil, err
+		}
+		c.store(key, p)
+	}
+	return c.add(key, path, p).Clone(), nil
+}
+
+// ParseAll is like the package level ParseAll, but goes through the cache.
+func (c *Cache) ParseAll(ctx context.Context, paths []string, workers int) ([]*Project, error) {
+	return parseAll(ctx, paths, workers, c.Parse)
+}
+
+// LoadReactor is like the package level LoadReactor, but parses the modules
+// through the cache.
+func (c *Cache) LoadReactor(rootDir string, profiles ...string) (*Reactor, error) {
+	return loadReactor(rootDir, profiles, c.Parse)
+}
+
+// Invalidate drops the entries parsed from the file at path, whether the
+// cache is keyed by path or by content.
+func (c *Cache) Invalidate(path string) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return
+	}
+	var invalidated []string
+	c.mu.Lock()
+	for e := c.entries.Front(); e != nil; {
+		next := e.Next()
+		if entry := e.Value.(*cacheEntry); slices.Contains(entry.paths, path) {
+			c.remove(e)
+			invalidated = append(invalidated, entry.paths...)
+		}
+		e = next
+	}
+	c.mu.Unlock()
+	c.notify(invalidated)
+}
+
+// Purge drops all the entries kept in memory. Entries stored on disk are
+// left untouched.
+func (c *Cache) Purge() {
+	var invalidated []string
+	c.mu.Lock()
+	for e := c.entries.Front(); e != nil; e = e.Next() {
+		invalidated = append(invalidated, e.Value.(*cacheEntry).paths...)
+	}
+	c.entries.Init()
+	c.byKey = map[string]*list.Element{}
+	c.mu.Unlock()
+	c.notify(invalidated)
+}
+
+// Len returns the number of entries kept in memory.
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.entries.Len()
+}
+
+// key returns the cache key for the file at path. When keying by content,
+// it also returns the content it had to read.
+func (c *Cache) key(path string) (string, []byte, error) {
+	if c.opts.ByContent {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return "", nil, err
+		}
+		sum := sha256.Sum256(b)
+		return "sha256:" + hex.EncodeToString(sum[:]), b, nil
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", nil, err
+	}
+	return path + "@" + strconv.FormatInt(info.ModTime().UnixNano(), 10) + ":" + strconv.FormatInt(info.Size(), 10), nil, nil
+}
+
+// add stores p in memory, unless another caller did it first, and returns
+// the cached project.
+func (c *Cache) add(key, path string, p *Project) *Project {
+	var evicted []string
+	c.mu.Lock()
+	if e, ok := c.byKey[key]; ok {
+		c.entries.MoveToFront(e)
+		entry := e.Value.(*cacheEntry)
+		entry.addPath(path)
+		c.mu.Unlock()
+		return entry.project
+	}
+	c.byKey[key] = c.entries.PushFront(&cacheEntry{key: key, paths: []string{path}, project: p})
+	for c.opts.MaxEntries > 0 && c.entries.Len() > c.opts.MaxEntries {
+		oldest := c.entries.Back()
+		c.remove(oldest)
+		evicted = append(evicted, oldest.Value.(*cacheEntry).paths...)
+	}
+	c.mu.Unlock()
+	c.notify(evicted)
+	return p
+}
+
+// addPath records that the entry was also parsed from path. c.mu must be
+// held.
+func (e *cacheEntry) addPath(path string) {
+	if !slices.Contains(e.paths, path) {
+		e.paths = append(e.paths, path)
+	}
+}
+
+// remove drops e from memory. c.mu must be held.
+func (c *Cache) remove(e *list.Element) {
+	c.entries.Remove(e)
+	delete(c.byKey, e.Value.(*cacheEntry).key)
+}
+
+func (c *Cache) notify(paths []string) {
+	if c.opts.OnInvalidate == nil {
+		return
+	}
+	for _, path := range paths {
+		c.opts.OnInvalidate(path)
+	}
+}
+
+func (c *Cache) diskPath(key string) string {
+	sum := sha256.Sum256([]byte(cacheFormat + "\x00" + key))
+	return filepath.Join(c.opts.Dir, hex.EncodeToString(sum[:])+".gob")
+}
+
+// load returns the project stored on disk under key, if any. Unreadable
+// entries are treated as missing.
+func (c *Cache) load(key string) *Project {
+	if c.opts.Dir == "" {
+		return nil
+	}
+	f, err := os.Open(c.diskPath(key))
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	var entry diskEntry
+	if err := gob.NewDecoder(f).Decode(&entry); err != nil || entry.Project == nil {
+		return nil
+	}
+	entry.Project.source = entry.Source
+	targets := commentTargets(entry.Project)
+	for path, comments := range entry.Comments {
+		if t, ok := targets[path]; ok {
+			*t.Comments() = comments
+		}
+	}
+	return entry.Project
+}
+
+// store writes p to disk under key. Failing to do so only means the entry
+// will have to be parsed again, so errors are ignored.
+func (c *Cache) store(key string, p *Project) {
+	if c.opts.Dir == "" {
+		return
+	}
+	entry := diskEntry{Project: p, Comments: map[string]Comments{}, Source: p.source}
+	for path, t := range commentTargets(p) {
+		if comments := t.attached(); comments != nil && (len(comments.Leading) > 0 || len(comments.Trailing) > 0) {
+			entry.Comments[path] = *comments
+		}
+	}
+	f, err := os.CreateTemp(c.opts.Dir, "entry-*")
+	if err != nil {
+		return
+	}
+	defer os.Remove(f.Name())
+	if err := gob.NewEncoder(f).Encode(entry); err != nil {
+		f.Close()
+		return
+	}
+	if err := f.Close(); err != nil {
+		return
+	}
+	_ = os.Rename(f.Name(), c.diskPath(key))
+}
@@ -0,0 +1,237 @@
+package gopom
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func copyFile(t *testing.T, src, dst string) {
+	t.Helper()
+	b, err := os.ReadFile(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(dst, b, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCache(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "pom.xml")
+	copyFile(t, commentsFilename, path)
+
+	var invalidated []string
+	c, err := NewCache(CacheOptions{OnInvalidate: func(path string) {
+		invalidated = append(invalidated, path)
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p1, err := c.Parse(path)
+	if err != nil {
+		t.Fatalf("failed parsing: %v", err)
+	}
+	p2, err := c.Parse(path)
+	if err != nil {
+		t.Fatalf("failed parsing: %v", err)
+	}
+	// Every caller gets its own copy.
+	assert.NotSame(t, p1, p2)
+	assert.Equal(t, p1, p2)
+	p1.Dependencies = nil
+	(*p2.Dependencies)[0].Comments().Leading = []string{"changed"}
+	p4, err := c.Parse(path)
+	if err != nil {
+		t.Fatalf("failed parsing: %v", err)
+	}
+	testComments(t, p4)
+
+	// Touching the file makes it a different entry.
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatal(err)
+	}
+	p3, err := c.Parse(path)
+	if err != nil {
+		t.Fatalf("failed parsing: %v", err)
+	}
+	assert.NotSame(t, p4, p3)
+	assert.Equal(t, p4, p3)
+	assert.Equal(t, 2, c.Len())
+
+	c.Invalidate(filepath.Join(dir, ".", "pom.xml"))
+	assert.Equal(t, 0, c.Len())
+	assert.Equal(t, []string{path, path}, invalidated)
+}
+
+func TestCacheLRU(t *testing.T) {
+	var evicted []string
+	c, err := NewCache(CacheOptions{MaxEntries: 2, OnInvalidate: func(path string) {
+		evicted = append(evicted, filepath.Base(filepath.Dir(path)))
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, module := range []string{"api", "core", "api", "app"} {
+		if _, err := c.Parse(filepath.Join("testdata/reactor", module, "pom.xml")); err != nil {
+			t.Fatalf("failed parsing: %v", err)
+		}
+	}
+	assert.Equal(t, 2, c.Len())
+	assert.Equal(t, []string{"core"}, evicted)
+
+	c.Purge()
+	assert.Equal(t, 0, c.Len())
+	assert.Equal(t, []string{"core", "app", "api"}, evicted)
+}
+
+func TestCacheByContent(t *testing.T) {
+	dir := t.TempDir()
+	a, b := filepath.Join(dir, "a.xml"), filepath.Join(dir, "b.xml")
+	copyFile(t, filename, a)
+	copyFile(t, filename, b)
+
+	var invalidated []string
+	c, err := NewCache(CacheOptions{ByContent: true, OnInvalidate: func(path string) {
+		invalidated = append(invalidated, path)
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pa, err := c.Parse(a)
+	if err != nil {
+		t.Fatalf("failed parsing: %v", err)
+	}
+	pb, err := c.Parse(b)
+	if err != nil {
+		t.Fatalf("failed parsing: %v", err)
+	}
+	assert.Equal(t, pa, pb)
+	assert.Equal(t, 1, c.Len())
+
+	// Invalidating either file drops the shared entry.
+	c.Invalidate(b)
+	assert.Equal(t, 0, c.Len())
+	assert.Equal(t, []string{a, b}, invalidated)
+}
+
+func TestCacheConcurrentCopies(t *testing.T) {
+	c, err := NewCache(CacheOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p, err := c.Parse(commentsFilename)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			// Comment accessors allocate lazily, which is safe on copies.
+			for j := range *p.Dependencies {
+				(*p.Dependencies)[j].Comments()
+			}
+			p.Properties.Comments("missing")
+		}()
+	}
+	wg.Wait()
+}
+
+func TestCacheDisk(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "pom.xml")
+	copyFile(t, commentsFilename, path)
+	cacheDir := filepath.Join(dir, "cache")
+
+	c, err := NewCache(CacheOptions{Dir: cacheDir, ByContent: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := c.Parse(path)
+	if err != nil {
+		t.Fatalf("failed parsing: %v", err)
+	}
+	entries, err := os.ReadDir(cacheDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 1, len(entries))
+
+	// A new cache, as in another process, reads the stored entry.
+	c, err = NewCache(CacheOptions{Dir: cacheDir, ByContent: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := c.Parse(path)
+	if err != nil {
+		t.Fatalf("failed parsing: %v", err)
+	}
+	assert.NotSame(t, want, got)
+	assert.Equal(t, want.Dependencies, got.Dependencies)
+	assert.Equal(t, want.Properties, got.Properties)
+	assert.Equal(t, want.source, got.source)
+	testComments(t, got)
+}
+
+func TestCacheLoadReactor(t *testing.T) {
+	c, err := NewCache(CacheOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := c.LoadReactor("./testdata/reactor")
+	if err != nil {
+		t.Fatalf("failed loading the reactor: %v", err)
+	}
+	assert.Equal(t, 5, c.Len())
+	again, err := c.LoadReactor("./testdata/reactor")
+	if err != nil {
+		t.Fatalf("failed loading the reactor: %v", err)
+	}
+	assert.Equal(t, 5, c.Len())
+	for i := range r.Modules {
+		assert.Equal(t, r.Modules[i].Project, again.Modules[i].Project)
+	}
+}
+
+func TestDefaultCacheSameSizeEdit(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "pom.xml")
+	write := func(version string) {
+		t.Helper()
+		pom := "<project><groupId>com.example</groupId><artifactId>a</artifactId><version>" + version + "</version></project>"
+		if err := os.WriteFile(path, []byte(pom), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("1.0.1")
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := LoadReactor(dir)
+	if err != nil {
+		t.Fatalf("failed loading the reactor: %v", err)
+	}
+	assert.Equal(t, "1.0.1", r.Root.Project.Version)
+
+	// Same size and modification time, as when both writes happen within
+	// the resolution of the file system.
+	write("1.0.2")
+	if err := os.Chtimes(path, info.ModTime(), info.ModTime()); err != nil {
+		t.Fatal(err)
+	}
+	r, err = LoadReactor(dir)
+	if err != nil {
+		t.Fatalf("failed loading the reactor: %v", err)
+	}
+	assert.Equal(t, "1.0.2", r.Root.Project.Version)
+}
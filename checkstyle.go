@@ -0,0 +1,52 @@
+package gopom
+
+import (
+	"encoding/xml"
+)
+
+// checkstyleReport is a checkstyle XML report, as read by CI servers.
+type checkstyleReport struct {
+	XMLName xml.Name         `xml:"checkstyle"`
+	Version string           `xml:"version,attr"`
+	Files   []checkstyleFile `xml:"file"`
+}
+
+type checkstyleFile struct {
+	Name   string            `xml:"name,attr"`
+	Errors []checkstyleError `xml:"error"`
+}
+
+type checkstyleError struct {
+	Line     int    `xml:"line,attr,omitempty"`
+	Column   int    `xml:"column,attr,omitempty"`
+	Severity string `xml:"severity,attr"`
+	Message  string `xml:"message,attr"`
+	Source   string `xml:"source,attr"`
+}
+
+// Checkstyle returns the problems of files in the checkstyle XML format, as
+// read by CI servers like Jenkins. Files without problems are listed too.
+// The source of a problem is its rule prefixed with "gopom.", and it is
+// located like in SARIF.
+func Checkstyle(files []ReportFile) ([]byte, error) {
+	report := checkstyleReport{Version: "4.3"}
+	for _, f := range files {
+		file := checkstyleFile{Name: f.Path}
+		for _, pb := range f.Problems {
+			e := checkstyleError{Severity: pb.Severity.String(), Message: pb.Message, Source: "gopom"}
+			if pb.Rule != "" {
+				e.Source += "." + pb.Rule
+			}
+			if pos, ok := f.Project.locate(pb.Path); ok {
+				e.Line, e.Column = pos.Line, pos.Column
+			}
+			file.Errors = append(file.Errors, e)
+		}
+		report.Files = append(report.Files, file)
+	}
+	b, err := xml.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), b...), nil
+}
@@ -0,0 +1,36 @@
+package gopom
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckstyle(t *testing.T) {
+	invalid, err := Parse("testdata/invalid.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lint, err := Parse("testdata/lint/pom.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	problems, err := lint.Lint(nil, &LintConfig{Rules: map[string]string{"deprecated-element": "off"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := Checkstyle([]ReportFile{
+		{Path: "testdata/invalid.xml", Project: invalid, Problems: invalid.Validate()},
+		{Path: "testdata/lint/pom.xml", Project: lint, Problems: problems},
+		{Path: "testdata/example.xml"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := os.ReadFile("testdata/report/checkstyle.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, string(want), string(b))
+}
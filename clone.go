@@ -0,0 +1,72 @@
+package gopom
+
+import "reflect"
+
+// Clone returns a deep copy of the project, including the comments attached
+// to it and the positions of its elements.
+func (p *Project) Clone() *Project {
+	if p == nil {
+		return nil
+	}
+	c := deepCopy(reflect.ValueOf(p)).Interface().(*Project)
+	// The index is never modified, so it can be shared.
+	c.source = p.source
+	copyComments(commentTargets(c), commentTargets(p))
+	return c
+}
+
+// copyComments copies the comments of the from targets to the to targets
+// with the same path.
+func copyComments(to, from map[string]commentable) {
+	for path, t := range from {
+		if comments := t.attached(); comments != nil {
+			if ct, ok := to[path]; ok {
+				*ct.Comments() = Comments{
+					Leading:  append([]string(nil), comments.Leading...),
+					Trailing: append([]string(nil), comments.Trailing...),
+				}
+			}
+		}
+	}
+}
+
+// deepCopy copies the exported parts of v recursively.
+func deepCopy(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return reflect.Zero(v.Type())
+		}
+		c := reflect.New(v.Type().Elem())
+		c.Elem().Set(deepCopy(v.Elem()))
+		return c
+	case reflect.Struct:
+		c := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.NumField(); i++ {
+			if c.Field(i).CanSet() {
+				c.Field(i).Set(deepCopy(v.Field(i)))
+			}
+		}
+		return c
+	case reflect.Slice:
+		if v.IsNil() {
+			return reflect.Zero(v.Type())
+		}
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(deepCopy(v.Index(i)))
+		}
+		return c
+	case reflect.Map:
+		if v.IsNil() {
+			return reflect.Zero(v.Type())
+		}
+		c := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			c.SetMapIndex(iter.Key(), deepCopy(iter.Value()))
+		}
+		return c
+	}
+	return v
+}
@@ -0,0 +1,24 @@
+package gopom
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClone(t *testing.T) {
+	p, err := Parse(commentsFilename)
+	if err != nil {
+		t.Fatalf("failed parsing the file: %v", err)
+	}
+	c := p.Clone()
+	assert.Equal(t, p, c)
+	testComments(t, c)
+
+	(*c.Dependencies)[0].Version = "changed"
+	c.Properties.Entries["java.version"] = "21"
+	(*c.Dependencies)[0].Comments().Leading[0] = "changed"
+	assert.Equal(t, "${guava.version}", (*p.Dependencies)[0].Version)
+	assert.Equal(t, "17", p.Properties.Entries["java.version"])
+	testComments(t, p)
+}
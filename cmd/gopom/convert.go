@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/chainguard-dev/gopom"
+	"gopkg.in/yaml.v3"
+)
+
+func init() {
+	var to string
+	register(&command{
+		name:    "convert",
+		args:    "[path]",
+		summary: "Convert a POM between its XML, JSON and YAML representations.",
+		flags: func(fs *flag.FlagSet) {
+			fs.StringVar(&to, "to", "json", "output representation: xml, json, yaml or polyglot (polyglot-maven YAML)")
+		},
+		noFormat: true,
+		run: func(e *env, args []string) error {
+			return runConvert(e, args, to)
+		},
+	})
+}
+
+// decodeProject decodes a POM in any of its representations, guessed from
+// its first character.
+func decodeProject(b []byte) (*gopom.Project, error) {
+	switch trimmed := bytes.TrimSpace(b); {
+	case bytes.HasPrefix(trimmed, []byte("<")):
+		return gopom.ParseReader(bytes.NewReader(b))
+	case bytes.HasPrefix(trimmed, []byte("{")):
+		var p gopom.Project
+		return &p, json.Unmarshal(b, &p)
+	default:
+		var p gopom.Project
+		return &p, yaml.Unmarshal(b, &p)
+	}
+}
+
+func runConvert(e *env, args []string, to string) error {
+	path, err := optionalPath(args)
+	if err != nil {
+		return err
+	}
+	var b []byte
+	if file, stdin := pomPath(path); stdin {
+		b, err = io.ReadAll(e.stdin)
+	} else {
+		b, err = os.ReadFile(file)
+	}
+	if err != nil {
+		return err
+	}
+	p, err := decodeProject(b)
+	if err != nil {
+		return fmt.Errorf("failed to decode the POM: %w", err)
+	}
+
+	switch to {
+	case "xml":
+		if p.Xmlns == "" {
+			p.Xmlns = "http://maven.apache.org/POM/4.0.0"
+		}
+		b, err := marshal(p)
+		if err != nil {
+			return err
+		}
+		_, err = e.stdout.Write(b)
+		return err
+	case "polyglot":
+		b, err := p.MarshalPolyglotYAML()
+		if err != nil {
+			return err
+		}
+		_, err = e.stdout.Write(b)
+		return err
+	case "json", "yaml":
+		return (&env{stdout: e.stdout, format: to}).output(p, nil)
+	}
+	return fmt.Errorf("unknown representation %q", to)
+}
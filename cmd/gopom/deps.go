@@ -0,0 +1,93 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/chainguard-dev/gopom"
+)
+
+func init() {
+	var managed, effective bool
+	var profiles stringList
+	register(&command{
+		name:    "deps",
+		args:    "[path]",
+		summary: "List the dependencies of a POM.",
+		flags: func(fs *flag.FlagSet) {
+			fs.BoolVar(&managed, "managed", false, "list the managed dependencies instead")
+			fs.BoolVar(&effective, "effective", false, "list the dependencies of the effective model")
+			fs.Var(&profiles, "P", "profiles to activate with --effective, can be repeated")
+		},
+		run: func(e *env, args []string) error {
+			return runDeps(e, args, managed, effective, profiles)
+		},
+	})
+}
+
+type dependency struct {
+	GroupID    string `json:"groupId" yaml:"groupId"`
+	ArtifactID string `json:"artifactId" yaml:"artifactId"`
+	Version    string `json:"version,omitempty" yaml:"version,omitempty"`
+	Type       string `json:"type,omitempty" yaml:"type,omitempty"`
+	Classifier string `json:"classifier,omitempty" yaml:"classifier,omitempty"`
+	Scope      string `json:"scope,omitempty" yaml:"scope,omitempty"`
+	Optional   bool   `json:"optional,omitempty" yaml:"optional,omitempty"`
+}
+
+// dependencyString formats d as its coordinates followed by its scope.
+func dependencyString(d gopom.Dependency) string {
+	s := d.Coordinate().String()
+	if d.Scope != "" {
+		s += " (" + d.Scope + ")"
+	}
+	return s
+}
+
+func runDeps(e *env, args []string, managed, effective bool, profiles []string) error {
+	path, err := optionalPath(args)
+	if err != nil {
+		return err
+	}
+	p, dir, err := e.readProject(path)
+	if err != nil {
+		return err
+	}
+	if effective {
+		if p, err = effectiveModel(p, dir, profiles); err != nil {
+			return err
+		}
+	}
+	deps := p.Dependencies
+	if managed {
+		deps = nil
+		if p.DependencyManagement != nil {
+			deps = p.DependencyManagement.Dependencies
+		}
+	}
+
+	list := []dependency{}
+	if deps != nil {
+		for _, d := range *deps {
+			list = append(list, dependency{
+				GroupID:    d.GroupID,
+				ArtifactID: d.ArtifactID,
+				Version:    d.Version,
+				Type:       d.Type,
+				Classifier: d.Classifier,
+				Scope:      d.Scope,
+				Optional:   d.Optional == "true",
+			})
+		}
+	}
+	return e.output(list, func(w io.Writer) error {
+		if deps == nil {
+			return nil
+		}
+		for _, d := range *deps {
+			fmt.Fprintln(w, dependencyString(d))
+		}
+		return nil
+	})
+}
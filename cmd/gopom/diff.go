@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/chainguard-dev/gopom"
+)
+
+func init() {
+	register(&command{
+		name:    "diff",
+		args:    "<old> <new>",
+		summary: "List the changes between two POMs, and exit with 1 if there are any.",
+		run:     runDiff,
+	})
+}
+
+func runDiff(e *env, args []string) error {
+	if len(args) != 2 {
+		return errUsage
+	}
+	var projects [2]*gopom.Project
+	for i, arg := range args {
+		p, err := e.readRevision(arg)
+		if err != nil {
+			return err
+		}
+		projects[i] = p
+	}
+
+	changes := gopom.Diff(projects[0], projects[1])
+	if changes == nil {
+		changes = []gopom.Change{}
+	}
+	err := e.output(changes, func(w io.Writer) error {
+		for _, c := range changes {
+			fmt.Fprintln(w, c)
+		}
+		return nil
+	})
+	if err == nil && len(changes) > 0 {
+		return errFindings
+	}
+	return err
+}
+
+// readRevision reads the POM arg refers to: a path accepted by readProject,
+// or a git revision and a path separated by a colon, like HEAD~1:pom.xml,
+// which is read with git show. As with git show, the path is relative to the
+// root of the repository unless it starts with ./ or ../. Arguments whose
+// part before the colon is not a git revision are paths, so missing files
+// are reported as such.
+func (e *env) readRevision(arg string) (*gopom.Project, error) {
+	rev, path, ok := strings.Cut(arg, ":")
+	if _, err := os.Stat(arg); err == nil || !ok || rev == "" || path == "" || !isRevision(rev) {
+		p, _, err := e.readProject(arg)
+		return p, err
+	}
+	var stderr bytes.Buffer
+	cmd := exec.Command("git", "show", arg)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && stderr.Len() > 0 {
+			err = errors.New(strings.TrimSpace(stderr.String()))
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", arg, err)
+	}
+	p, err := gopom.ParseReader(bytes.NewReader(out))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", arg, err)
+	}
+	return p, nil
+}
+
+// isRevision tells whether rev names a commit of the git repository of the
+// working directory.
+func isRevision(rev string) bool {
+	return exec.Command("git", "rev-parse", "--verify", "--quiet", rev+"^{commit}").Run() == nil
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/chainguard-dev/gopom"
+)
+
+func init() {
+	var profiles stringList
+	var settings, globalSettings string
+	register(&command{
+		name:    "effective",
+		args:    "[path]",
+		summary: "Print the effective model of a POM, with its parents and profiles applied.",
+		flags: func(fs *flag.FlagSet) {
+			fs.Var(&profiles, "P", "profiles to activate, can be repeated")
+			fs.StringVar(&settings, "s", "", "user settings file whose profiles apply (default ~/.m2/settings.xml)")
+			fs.StringVar(&globalSettings, "gs", "", "global settings file, merged with the user settings (default $MAVEN_HOME/conf/settings.xml)")
+		},
+		run: func(e *env, args []string) error {
+			return runEffective(e, args, profiles, globalSettings, settings)
+		},
+	})
+
+	var effective bool
+	register(&command{
+		name:    "validate",
+		args:    "[path]",
+		summary: "Check a POM against the rules Maven applies when reading it.",
+		flags: func(fs *flag.FlagSet) {
+			fs.BoolVar(&effective, "effective", false, "validate the effective model")
+		},
+		formats: reportFormats,
+		run: func(e *env, args []string) error {
+			return runValidate(e, args, effective)
+		},
+	})
+}
+
+func runEffective(e *env, args []string, profiles []string, globalSettings, settings string) error {
+	path, err := optionalPath(args)
+	if err != nil {
+		return err
+	}
+	p, dir, err := e.readProject(path)
+	if err != nil {
+		return err
+	}
+	// Like with mvn, -gs and -s replace the settings Maven reads by default.
+	if globalSettings == "" {
+		globalSettings = gopom.GlobalSettingsFile()
+	}
+	if settings == "" {
+		if settings, err = gopom.UserSettingsFile(); err != nil {
+			return err
+		}
+	}
+	s, err := gopom.LoadSettings(globalSettings, settings)
+	if err != nil {
+		return err
+	}
+	repository, err := s.LocalRepositoryPath()
+	if err != nil {
+		return err
+	}
+	p, err = gopom.Effective(p, gopom.EffectiveOptions{Dir: dir, Profiles: profiles, Repository: repository, Settings: s})
+	if err != nil {
+		return err
+	}
+	return e.output(p, func(w io.Writer) error {
+		b, err := marshal(p)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(b)
+		return err
+	})
+}
+
+type problem struct {
+	Severity string `json:"severity" yaml:"severity"`
+	Rule     string `json:"rule" yaml:"rule"`
+	Path     string `json:"path" yaml:"path"`
+	Message  string `json:"message" yaml:"message"`
+}
+
+func runValidate(e *env, args []string, effective bool) error {
+	path, err := optionalPath(args)
+	if err != nil {
+		return err
+	}
+	p, dir, err := e.readProject(path)
+	if err != nil {
+		return err
+	}
+	// The effective model has no source to locate problems in.
+	located := p
+	if effective {
+		if p, err = effectiveModel(p, dir, nil); err != nil {
+			return err
+		}
+		located = nil
+	}
+
+	problems := p.Validate()
+	list := []problem{}
+	failed := false
+	for _, pb := range problems {
+		list = append(list, problem{Severity: pb.Severity.String(), Rule: pb.Rule, Path: pb.Path, Message: pb.Message})
+		failed = failed || pb.Severity == gopom.SeverityError
+	}
+	file, stdin := pomPath(path)
+	if stdin {
+		file = "<stdin>"
+	}
+	reported, err := e.report(gopom.ValidationRules(), []gopom.ReportFile{{Path: file, Project: located, Problems: problems}})
+	if !reported {
+		err = e.output(list, func(w io.Writer) error {
+			for _, pb := range problems {
+				fmt.Fprintf(w, "%s: %s (%s)\n", pb.Severity, pb.Message, pb.Path)
+			}
+			return nil
+		})
+	}
+	if err == nil && failed {
+		return errFindings
+	}
+	return err
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/chainguard-dev/gopom"
+)
+
+func init() {
+	var write, list, check, configuration bool
+	var indent string
+	var sorted stringList
+	register(&command{
+		name:    "fmt",
+		args:    "[path...]",
+		summary: "Reformat POMs in canonical form.",
+		flags: func(fs *flag.FlagSet) {
+			fs.BoolVar(&write, "w", false, "write the result back to the files instead of printing it")
+			fs.BoolVar(&list, "l", false, "list the files whose formatting differs, and exit with 1 if any")
+			fs.BoolVar(&check, "check", false, "report the files that are not formatted, and exit with 1 if any")
+			fs.StringVar(&indent, "indent", "4", `indentation: a number of spaces, or "tab"`)
+			fs.Var(&sorted, "sort", "lists to sort: dependencies, dependencyManagement, properties and modules, or all")
+			fs.BoolVar(&configuration, "configuration", false, "reindent plugin configurations too")
+		},
+		noFormat: true,
+		run: func(e *env, args []string) error {
+			opts := gopom.FormatOptions{Configuration: configuration}
+			switch n, err := strconv.Atoi(indent); {
+			case indent == "tab":
+				opts.Indent = "\t"
+			case err == nil && n > 0:
+				opts.Indent = strings.Repeat(" ", n)
+			default:
+				return fmt.Errorf("invalid indentation %q", indent)
+			}
+			for _, s := range sorted {
+				switch s {
+				case "all":
+					opts.SortDependencies, opts.SortDependencyManagement, opts.SortProperties, opts.SortModules = true, true, true, true
+				case "dependencies":
+					opts.SortDependencies = true
+				case "dependencyManagement":
+					opts.SortDependencyManagement = true
+				case "properties":
+					opts.SortProperties = true
+				case "modules":
+					opts.SortModules = true
+				default:
+					return fmt.Errorf("cannot sort %q", s)
+				}
+			}
+			return runFmt(e, args, opts, write, list, check)
+		},
+	})
+}
+
+// format returns the POM in b in canonical form.
+func format(b []byte, opts gopom.FormatOptions) ([]byte, error) {
+	p, err := gopom.ParseReader(bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	return p.Format(opts)
+}
+
+// marshal is Project.Marshal, with a final newline.
+func marshal(p *gopom.Project) ([]byte, error) {
+	b, err := p.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.HasSuffix(b, []byte("\n")) {
+		b = append(b, '\n')
+	}
+	return b, nil
+}
+
+func runFmt(e *env, args []string, opts gopom.FormatOptions, write, list, check bool) error {
+	if len(args) == 0 {
+		args = []string{"-"}
+	}
+	unformatted := false
+	for _, arg := range args {
+		path, stdin := pomPath(arg)
+		var b []byte
+		var err error
+		if stdin {
+			if write {
+				return fmt.Errorf("cannot use -w with the standard input")
+			}
+			path = "<stdin>"
+			b, err = io.ReadAll(e.stdin)
+		} else {
+			b, err = os.ReadFile(path)
+		}
+		if err != nil {
+			return err
+		}
+		formatted, err := format(b, opts)
+		if err != nil {
+			return fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+
+		changed := !bytes.Equal(b, formatted)
+		unformatted = unformatted || changed
+		switch {
+		case check && changed:
+			fmt.Fprintf(e.stdout, "%s: not formatted\n", path)
+		case check:
+		case list && changed:
+			fmt.Fprintln(e.stdout, path)
+		case list:
+		case write && changed:
+			if err := writeFile(path, formatted); err != nil {
+				return err
+			}
+		case write:
+		default:
+			if _, err := e.stdout.Write(formatted); err != nil {
+				return err
+			}
+		}
+	}
+	if (list || check) && unformatted {
+		return errFindings
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"encoding/xml"
+	"flag"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/chainguard-dev/gopom"
+)
+
+func init() {
+	var positions bool
+	register(&command{
+		name:    "get",
+		args:    "<query> [path]",
+		summary: "Print the elements selected by a query, e.g. dependencies[scope=test].artifactId.",
+		flags: func(fs *flag.FlagSet) {
+			fs.BoolVar(&positions, "n", false, "prefix the results with their line and column")
+		},
+		run: func(e *env, args []string) error {
+			return runGet(e, args, positions)
+		},
+	})
+}
+
+type result struct {
+	Path   string `json:"path" yaml:"path"`
+	Line   int    `json:"line,omitempty" yaml:"line,omitempty"`
+	Column int    `json:"column,omitempty" yaml:"column,omitempty"`
+	Value  any    `json:"value" yaml:"value"`
+}
+
+func runGet(e *env, args []string, positions bool) error {
+	if len(args) < 1 {
+		return errUsage
+	}
+	q, err := gopom.ParseQuery(args[0])
+	if err != nil {
+		return err
+	}
+	file, err := optionalPath(args[1:])
+	if err != nil {
+		return err
+	}
+	p, _, err := e.readProject(file)
+	if err != nil {
+		return err
+	}
+	results, err := q.Eval(p)
+	if err != nil {
+		return err
+	}
+	if len(results) == 0 {
+		fmt.Fprintf(e.stderr, "gopom get: no element matches %s\n", q)
+		return errFindings
+	}
+
+	list := make([]result, 0, len(results))
+	for _, r := range results {
+		list = append(list, result{Path: r.Path, Line: r.Position.Line, Column: r.Position.Column, Value: r.Value})
+	}
+	return e.output(list, func(w io.Writer) error {
+		for _, r := range results {
+			if positions && r.Position.IsValid() {
+				fmt.Fprintf(w, "%s: ", r.Position)
+			}
+			if err := writeResult(w, r); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// writeResult writes the text of r, or its XML for elements with children.
+func writeResult(w io.Writer, r gopom.Result) error {
+	switch v := r.Value.(type) {
+	case string, bool:
+		_, err := fmt.Fprintln(w, v)
+		return err
+	case *gopom.ConfigElement:
+		if len(v.Children) == 0 {
+			_, err := fmt.Fprintln(w, v.Text)
+			return err
+		}
+	}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "    ")
+	if err := encodeResult(enc, r.Value, elementName(r.Path)); err != nil {
+		return err
+	}
+	if err := enc.Close(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintln(w)
+	return err
+}
+
+func encodeResult(enc *xml.Encoder, v any, name string) error {
+	c, ok := v.(*gopom.ConfigElement)
+	if !ok {
+		return enc.EncodeElement(v, xml.StartElement{Name: xml.Name{Local: name}})
+	}
+	start := xml.StartElement{Name: xml.Name{Local: c.Name}, Attr: c.Attrs}
+	if err := enc.EncodeToken(start); err != nil {
+		return err
+	}
+	if len(c.Children) == 0 {
+		if err := enc.EncodeToken(xml.CharData(c.Text)); err != nil {
+			return err
+		}
+	}
+	for _, child := range c.Children {
+		if err := encodeResult(enc, child, child.Name); err != nil {
+			return err
+		}
+	}
+	return enc.EncodeToken(start.End())
+}
+
+// elementName returns the name of the last element of an element path.
+func elementName(p string) string {
+	name, _, _ := strings.Cut(path.Base(p), "[")
+	return name
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/chainguard-dev/gopom"
+)
+
+func init() {
+	var declared bool
+	var profiles stringList
+	var policy, repository string
+	register(&command{
+		name:    "licenses",
+		args:    "[path]",
+		summary: "Print the licenses of a POM as SPDX identifiers, or check its dependencies against a policy.",
+		flags: func(fs *flag.FlagSet) {
+			fs.BoolVar(&declared, "declared", false, "use the licenses as declared instead of the effective model")
+			fs.Var(&profiles, "P", "profiles to activate, can be repeated")
+			fs.StringVar(&policy, "policy", "", "check the licenses of the dependencies against this policy file")
+			fs.StringVar(&repository, "repository", "", "local repository the dependencies are resolved from (default: the localRepository of the Maven settings, or ~/.m2/repository)")
+		},
+		run: func(e *env, args []string) error {
+			if policy != "" {
+				return runLicenseCheck(e, args, policy, repository, profiles)
+			}
+			return runLicenses(e, args, declared, profiles)
+		},
+	})
+}
+
+type licenses struct {
+	Expression string    `json:"expression" yaml:"expression"`
+	Licenses   []license `json:"licenses" yaml:"licenses"`
+}
+
+type license struct {
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+	URL  string `json:"url,omitempty" yaml:"url,omitempty"`
+	SPDX string `json:"spdx,omitempty" yaml:"spdx,omitempty"`
+}
+
+func runLicenses(e *env, args []string, declared bool, profiles []string) error {
+	path, err := optionalPath(args)
+	if err != nil {
+		return err
+	}
+	p, dir, err := e.readProject(path)
+	if err != nil {
+		return err
+	}
+	if !declared {
+		if p, err = effectiveModel(p, dir, profiles); err != nil {
+			return err
+		}
+	}
+
+	l := p.Licensing()
+	out := licenses{Expression: l.Expression, Licenses: []license{}}
+	for _, nl := range l.Licenses {
+		out.Licenses = append(out.Licenses, license{Name: nl.Name, URL: nl.URL, SPDX: nl.SPDX})
+	}
+	err = e.output(out, func(w io.Writer) error {
+		if l.Expression != "" {
+			fmt.Fprintln(w, l.Expression)
+		}
+		for _, u := range l.Unrecognized() {
+			if u.Name != "" && u.URL != "" {
+				fmt.Fprintf(w, "unrecognized license: %s (%s)\n", u.Name, u.URL)
+			} else {
+				fmt.Fprintf(w, "unrecognized license: %s%s\n", u.Name, u.URL)
+			}
+		}
+		return nil
+	})
+	if err == nil && len(l.Unrecognized()) > 0 {
+		return errFindings
+	}
+	return err
+}
+
+type violation struct {
+	Path       []string `json:"path" yaml:"path"`
+	Scope      string   `json:"scope" yaml:"scope"`
+	Expression string   `json:"expression,omitempty" yaml:"expression,omitempty"`
+	Licenses   []string `json:"licenses,omitempty" yaml:"licenses,omitempty"`
+}
+
+func runLicenseCheck(e *env, args []string, policyPath, repository string, profiles []string) error {
+	path, err := optionalPath(args)
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(policyPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	policy, err := gopom.ParseLicensePolicy(f)
+	if err != nil {
+		return err
+	}
+	graph, err := e.resolveProject(path, repository, profiles)
+	if err != nil {
+		return err
+	}
+
+	violations := policy.Check(graph)
+	list := []violation{}
+	for _, v := range violations {
+		out := violation{Scope: v.Dependency.Scope, Expression: v.Expression, Licenses: v.Licenses}
+		for _, c := range v.Path {
+			out.Path = append(out.Path, c.String())
+		}
+		list = append(list, out)
+	}
+	err = e.output(list, func(w io.Writer) error {
+		for _, v := range violations {
+			fmt.Fprintln(w, v)
+		}
+		return nil
+	})
+	if err == nil && len(violations) > 0 {
+		return errFindings
+	}
+	return err
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/chainguard-dev/gopom"
+)
+
+func init() {
+	var config string
+	var list bool
+	register(&command{
+		name:    "lint",
+		args:    "[path...]",
+		summary: "Check POMs against best practices, and exit with 1 on errors.",
+		flags: func(fs *flag.FlagSet) {
+			fs.StringVar(&config, "config", "", "YAML file setting the severity of rules, or disabling them")
+			fs.BoolVar(&list, "rules", false, "list the rules instead")
+		},
+		formats: reportFormats,
+		run: func(e *env, args []string) error {
+			if list {
+				return runLintRules(e)
+			}
+			return runLint(e, args, config)
+		},
+	})
+}
+
+type lintProblem struct {
+	File     string `json:"file" yaml:"file"`
+	Line     int    `json:"line,omitempty" yaml:"line,omitempty"`
+	Column   int    `json:"column,omitempty" yaml:"column,omitempty"`
+	Severity string `json:"severity" yaml:"severity"`
+	Rule     string `json:"rule" yaml:"rule"`
+	Path     string `json:"path" yaml:"path"`
+	Message  string `json:"message" yaml:"message"`
+}
+
+type lintRule struct {
+	ID          string `json:"id" yaml:"id"`
+	Severity    string `json:"severity" yaml:"severity"`
+	Description string `json:"description" yaml:"description"`
+}
+
+func runLint(e *env, args []string, configPath string) error {
+	var config *gopom.LintConfig
+	if configPath != "" {
+		f, err := os.Open(configPath)
+		if err != nil {
+			return err
+		}
+		config, err = gopom.ParseLintConfig(f)
+		f.Close()
+		if err != nil {
+			return err
+		}
+	}
+	if len(args) == 0 {
+		args = []string{"-"}
+	}
+
+	var files []gopom.ReportFile
+	list := []lintProblem{}
+	failed := false
+	for _, arg := range args {
+		p, _, err := e.readProject(arg)
+		if err != nil {
+			return err
+		}
+		problems, err := p.Lint(nil, config)
+		if err != nil {
+			return err
+		}
+		path, stdin := pomPath(arg)
+		if stdin {
+			path = "<stdin>"
+		}
+		files = append(files, gopom.ReportFile{Path: path, Project: p, Problems: problems})
+		for _, pb := range problems {
+			pos, _ := p.Position(pb.Path)
+			list = append(list, lintProblem{File: path, Line: pos.Line, Column: pos.Column, Severity: pb.Severity.String(), Rule: pb.Rule, Path: pb.Path, Message: pb.Message})
+			failed = failed || pb.Severity == gopom.SeverityError
+		}
+	}
+
+	var rules []gopom.Rule
+	for _, r := range gopom.LintRules() {
+		rules = append(rules, r.Rule)
+	}
+	reported, err := e.report(rules, files)
+	if !reported {
+		err = e.output(list, func(w io.Writer) error {
+			for _, pb := range list {
+				location := pb.File
+				if pb.Line > 0 {
+					location = fmt.Sprintf("%s:%d:%d", pb.File, pb.Line, pb.Column)
+				}
+				fmt.Fprintf(w, "%s: %s: %s [%s]\n", location, pb.Severity, pb.Message, pb.Rule)
+			}
+			return nil
+		})
+	}
+	if err == nil && failed {
+		return errFindings
+	}
+	return err
+}
+
+func runLintRules(e *env) error {
+	rules := gopom.LintRules()
+	list := []lintRule{}
+	for _, r := range rules {
+		list = append(list, lintRule{ID: r.ID, Severity: r.Severity.String(), Description: r.Description})
+	}
+	return e.output(list, func(w io.Writer) error {
+		for _, r := range list {
+			fmt.Fprintf(w, "%-20s %-7s %s\n", r.ID, r.Severity, r.Description)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,316 @@
+// Command gopom reads, queries and edits Maven pom.xml files.
+//
+// Usage:
+//
+//	gopom <command> [flags] [arguments]
+//
+// Commands that read a single POM take its path as argument, which can also
+// be a directory containing a pom.xml, or a pom.yaml in polyglot builds.
+// Without a path, or with "-", the POM is read from the standard input.
+//
+// Exit codes are the same for all commands: 0 on success, 1 when the
+// command completed but found something to report (validation errors,
+// differences, files that are not formatted, queries without results), and
+// 2 on usage or other errors.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/chainguard-dev/gopom"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	exitOK       = 0
+	exitFindings = 1
+	exitError    = 2
+)
+
+// errFindings is returned by commands that completed but found something
+// to report. They print their findings themselves.
+var errFindings = errors.New("findings reported")
+
+// errUsage is returned for invalid command lines.
+var errUsage = errors.New("invalid usage")
+
+type command struct {
+	name    string
+	args    string
+	summary string
+	// flags registers the command's own flags, besides --format.
+	flags func(fs *flag.FlagSet)
+	// formats are the output formats the command supports besides text,
+	// json and yaml.
+	formats []string
+	// noFormat leaves out --format, for commands whose output has a fixed
+	// or its own format.
+	noFormat bool
+	run      func(e *env, args []string) error
+}
+
+var commands []*command
+
+func register(c *command) {
+	commands = append(commands, c)
+}
+
+// env is the environment commands run in.
+type env struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+	format string
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		usage(stderr)
+		if len(args) == 0 {
+			return exitError
+		}
+		return exitOK
+	}
+	var cmd *command
+	for _, c := range commands {
+		if c.name == args[0] {
+			cmd = c
+		}
+	}
+	if cmd == nil {
+		fmt.Fprintf(stderr, "gopom: unknown command %q\n", args[0])
+		usage(stderr)
+		return exitError
+	}
+
+	e := &env{stdin: stdin, stdout: stdout, stderr: stderr}
+	fs := flag.NewFlagSet("gopom "+cmd.name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	e.format = "text"
+	formats := append([]string{"text", "json", "yaml"}, cmd.formats...)
+	if !cmd.noFormat {
+		fs.StringVar(&e.format, "format", "text", "output format: "+strings.Join(formats[:len(formats)-1], ", ")+" or "+formats[len(formats)-1])
+	}
+	if cmd.flags != nil {
+		cmd.flags(fs)
+	}
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "usage: gopom %s [flags] %s\n\n%s\n\nflags:\n", cmd.name, cmd.args, cmd.summary)
+		fs.PrintDefaults()
+	}
+	positional, err := parseFlags(fs, args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return exitOK
+	}
+	if err != nil {
+		return exitError
+	}
+	if !slices.Contains(formats, e.format) {
+		fmt.Fprintf(stderr, "gopom %s: unknown format %q\n", cmd.name, e.format)
+		return exitError
+	}
+
+	err = cmd.run(e, positional)
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.Is(err, errFindings):
+		return exitFindings
+	case errors.Is(err, errUsage):
+		fs.Usage()
+		return exitError
+	default:
+		fmt.Fprintf(stderr, "gopom %s: %v\n", cmd.name, err)
+		return exitError
+	}
+}
+
+func usage(w io.Writer) {
+	sort.Slice(commands, func(i, j int) bool { return commands[i].name < commands[j].name })
+	fmt.Fprintf(w, "usage: gopom <command> [flags] [arguments]\n\ncommands:\n")
+	for _, c := range commands {
+		fmt.Fprintf(w, "  %-10s %s\n", c.name, c.summary)
+	}
+	fmt.Fprintf(w, "\nRun 'gopom <command> -h' for the flags of a command.\n")
+}
+
+// parseFlags parses the flags in args, which may come after positional
+// arguments, and returns the positional arguments.
+func parseFlags(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// pomPath returns the POM file path refers to, and whether it designates the
+// standard input.
+func pomPath(path string) (string, bool) {
+	if path == "" || path == "-" {
+		return "", true
+	}
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		path = gopom.ProjectFile(path)
+	}
+	return path, false
+}
+
+// readProject reads the POM at path, or from the standard input, and returns
+// it along with the directory it belongs to.
+func (e *env) readProject(path string) (*gopom.Project, string, error) {
+	path, stdin := pomPath(path)
+	if stdin {
+		p, err := gopom.ParseReader(e.stdin)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to parse the standard input: %w", err)
+		}
+		return p, ".", nil
+	}
+	p, err := gopom.Parse(path)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return p, filepath.Dir(path), nil
+}
+
+// resolveProject reads the project at path and resolves the dependency graph
+// of its effective model from a local repository, the one of the Maven
+// settings unless repository is set.
+func (e *env) resolveProject(path, repository string, profiles []string) ([]*gopom.DependencyNode, error) {
+	p, dir, err := e.readProject(path)
+	if err != nil {
+		return nil, err
+	}
+	settings, repository, err := mavenSettings(repository)
+	if err != nil {
+		return nil, err
+	}
+	opts := gopom.EffectiveOptions{Dir: dir, Profiles: profiles, Repository: repository, Settings: settings}
+	if p, err = gopom.Effective(p, opts); err != nil {
+		return nil, err
+	}
+	return gopom.Resolve(p, gopom.ResolveOptions{Repository: repository})
+}
+
+// effectiveModel returns the effective model of p, read from dir, with the Maven
+// settings applied and the parents not found on disk looked up in their
+// local repository.
+func effectiveModel(p *gopom.Project, dir string, profiles []string) (*gopom.Project, error) {
+	settings, repository, err := mavenSettings("")
+	if err != nil {
+		return nil, err
+	}
+	return gopom.Effective(p, gopom.EffectiveOptions{Dir: dir, Profiles: profiles, Repository: repository, Settings: settings})
+}
+
+// mavenSettings loads the settings Maven uses, from the installation
+// MAVEN_HOME points at and from ~/.m2, and returns them along with
+// repository, or their local repository when it is empty.
+func mavenSettings(repository string) (*gopom.Settings, string, error) {
+	user, err := gopom.UserSettingsFile()
+	if err != nil {
+		return nil, "", err
+	}
+	settings, err := gopom.LoadSettings(gopom.GlobalSettingsFile(), user)
+	if err != nil {
+		return nil, "", err
+	}
+	if repository == "" {
+		if repository, err = settings.LocalRepositoryPath(); err != nil {
+			return nil, "", err
+		}
+	}
+	return settings, repository, nil
+}
+
+// optionalPath returns the only argument of args, if any.
+func optionalPath(args []string) (string, error) {
+	switch len(args) {
+	case 0:
+		return "", nil
+	case 1:
+		return args[0], nil
+	}
+	return "", errUsage
+}
+
+// output writes v in the requested format, using text for the text format.
+func (e *env) output(v any, text func(w io.Writer) error) error {
+	switch e.format {
+	case "json":
+		enc := json.NewEncoder(e.stdout)
+		enc.SetIndent("", "  ")
+		enc.SetEscapeHTML(false)
+		return enc.Encode(v)
+	case "yaml":
+		enc := yaml.NewEncoder(e.stdout)
+		enc.SetIndent(2)
+		if err := enc.Encode(v); err != nil {
+			return err
+		}
+		return enc.Close()
+	}
+	return text(e.stdout)
+}
+
+// reportFormats are the formats of the commands reporting problems, see
+// report.
+var reportFormats = []string{"sarif", "checkstyle"}
+
+// report writes the problems of files as SARIF or checkstyle XML, when
+// requested by --format, and tells whether it did.
+func (e *env) report(rules []gopom.Rule, files []gopom.ReportFile) (bool, error) {
+	var b []byte
+	var err error
+	switch e.format {
+	case "sarif":
+		b, err = gopom.SARIF(rules, files)
+	case "checkstyle":
+		b, err = gopom.Checkstyle(files)
+	default:
+		return false, nil
+	}
+	if err != nil {
+		return true, err
+	}
+	_, err = fmt.Fprintf(e.stdout, "%s\n", b)
+	return true, err
+}
+
+// stringList is a flag that can be repeated, and also accepts comma
+// separated values.
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(v string) error {
+	for _, s := range strings.Split(v, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			*l = append(*l, s)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+func TestCommands(t *testing.T) {
+	tests := []struct {
+		name  string
+		args  []string
+		stdin string
+		// file, when set, is copied to a temporary directory, replacing
+		// "FILE" in args, and its content after the run is compared too.
+		file string
+		// home is the home directory, holding the user settings. It
+		// defaults to an empty directory.
+		home string
+		exit int
+	}{
+		{name: "show", args: []string{"show", "testdata/project"}},
+		{name: "show-json", args: []string{"show", "--format", "json", "testdata/project/app/pom.xml"}},
+		{name: "show-yaml", args: []string{"show", "testdata/project/lib", "--format=yaml"}},
+		{name: "show-stdin", args: []string{"show"}, stdin: "testdata/old.xml"},
+		{name: "get", args: []string{"get", "dependencies[1].version", "testdata/old.xml"}},
+		{name: "get-property", args: []string{"get", "properties.junit.version", "testdata/project"}},
+		{name: "get-element", args: []string{"get", "build.plugins[0]", "testdata/project"}},
+		{name: "get-list", args: []string{"get", "modules", "testdata/project"}},
+		{name: "get-missing", args: []string{"get", "dependencies[5]", "testdata/old.xml"}, exit: exitFindings},
+		{name: "get-filter", args: []string{"get", "dependencies[scope=test].artifactId", "testdata/query.xml"}},
+		{name: "get-configuration", args: []string{"get", "-n", "build.plugins[artifactId=maven-compiler-plugin].configuration.*", "testdata/query.xml"}},
+		{name: "get-json", args: []string{"get", "--format", "json", "profiles[id=jdk21].properties.java.version", "testdata/query.xml"}},
+		{name: "get-invalid", args: []string{"get", "dependencies[scope=test", "testdata/query.xml"}, exit: exitError},
+		{name: "set", args: []string{"set", "FILE", "dependencies[artifactId=guava].version", "33.2.0-jre"}, file: "testdata/query.xml"},
+		{name: "set-property", args: []string{"set", "FILE", "properties.java.version", "21"}, file: "testdata/query.xml"},
+		{name: "set-create", args: []string{"set", "FILE", "scm.url", "https://example.com/query"}, file: "testdata/query.xml"},
+		{name: "set-batch", args: []string{"set", "-f", "testdata/edits.txt", "FILE"}, file: "testdata/query.xml"},
+		{name: "set-stdin", args: []string{"set", "-", "scm.url", "https://example.com/demo"}, stdin: "testdata/old.xml"},
+		{name: "set-invalid", args: []string{"set", "FILE", "dependencies[0]", "x"}, file: "testdata/query.xml", exit: exitError},
+		{name: "deps", args: []string{"deps", "testdata/project/app"}},
+		{name: "deps-managed", args: []string{"deps", "--managed", "testdata/project", "--format", "json"}},
+		{name: "deps-effective", args: []string{"deps", "--effective", "testdata/project/app"}},
+		{name: "deps-settings", args: []string{"deps", "--effective", "testdata/project/app"}, home: "testdata/home"},
+		{name: "effective-settings", args: []string{"effective", "-gs", "../../testdata/settings/global.xml", "-s", "../../testdata/settings/settings.xml", "../../testdata/settings"}},
+		{name: "effective", args: []string{"effective", "testdata/project/lib"}},
+		{name: "effective-profile", args: []string{"effective", "-P", "release", "testdata/project"}},
+		{name: "validate", args: []string{"validate", "testdata/project"}},
+		{name: "lint", args: []string{"lint", "../../testdata/lint/pom.xml"}, exit: exitFindings},
+		{name: "lint-clean", args: []string{"lint", "testdata/project/lib"}},
+		{name: "lint-config", args: []string{"lint", "--config", "testdata/lint.yaml", "--format", "json", "../../testdata/lint/pom.xml"}, exit: exitFindings},
+		{name: "lint-sarif", args: []string{"lint", "--format", "sarif", "../../testdata/lint/pom.xml"}, exit: exitFindings},
+		{name: "lint-checkstyle", args: []string{"lint", "--format", "checkstyle", "../../testdata/lint/pom.xml", "testdata/project/lib"}, exit: exitFindings},
+		{name: "lint-rules", args: []string{"lint", "--rules"}},
+		{name: "validate-invalid", args: []string{"validate", "testdata/invalid.xml"}, exit: exitFindings},
+		{name: "validate-json", args: []string{"validate", "--format", "json", "testdata/invalid.xml"}, exit: exitFindings},
+		{name: "validate-sarif", args: []string{"validate", "--format", "sarif", "testdata/invalid.xml"}, exit: exitFindings},
+		{name: "validate-checkstyle", args: []string{"validate", "--format", "checkstyle", "testdata/invalid.xml"}, exit: exitFindings},
+		{name: "fmt", args: []string{"fmt", "testdata/unformatted.xml"}},
+		{name: "fmt-list", args: []string{"fmt", "-l", "testdata/unformatted.xml", "testdata/old.xml"}, exit: exitFindings},
+		{name: "fmt-write", args: []string{"fmt", "-w", "FILE"}, file: "testdata/unformatted.xml"},
+		{name: "fmt-check", args: []string{"fmt", "--check", "testdata/unformatted.xml", "../../testdata/format/canonical.xml"}, exit: exitFindings},
+		{name: "fmt-sort", args: []string{"fmt", "--indent", "2", "--sort", "dependencies,properties", "../../testdata/format/pom.xml"}},
+		{name: "fmt-invalid-sort", args: []string{"fmt", "--sort", "plugins", "../../testdata/format/pom.xml"}, exit: exitError},
+		{name: "diff", args: []string{"diff", "../../testdata/diff/old.xml", "../../testdata/diff/new.xml"}, exit: exitFindings},
+		{name: "diff-same", args: []string{"diff", "testdata/old.xml", "-"}, stdin: "testdata/old.xml"},
+		{name: "diff-json", args: []string{"diff", "--format", "json", "testdata/old.xml", "testdata/new.xml"}, exit: exitFindings},
+		{name: "convert", args: []string{"convert", "testdata/query.xml"}},
+		{name: "convert-yaml", args: []string{"convert", "--to", "yaml", "testdata/old.xml"}},
+		{name: "convert-xml", args: []string{"convert", "--to", "xml"}, stdin: "testdata/query.yaml"},
+		{name: "convert-polyglot", args: []string{"convert", "--to", "polyglot", "testdata/old.xml"}},
+		{name: "convert-invalid", args: []string{"convert", "--to", "toml", "testdata/old.xml"}, exit: exitError},
+		{name: "sbom", args: []string{"sbom", "testdata/project/app"}},
+		{name: "sbom-xml", args: []string{"sbom", "--to", "cyclonedx-xml", "testdata/sbom.xml"}},
+		{name: "sbom-spdx", args: []string{"sbom", "--to", "spdx", "testdata/sbom.xml"}},
+		{name: "sbom-spdx-json", args: []string{"sbom", "--to", "spdx-json", "--declared", "testdata/project/app"}},
+		{name: "sbom-invalid", args: []string{"sbom", "--to", "swid", "testdata/sbom.xml"}, exit: exitError},
+		{name: "licenses", args: []string{"licenses", "testdata/sbom.xml"}},
+		{name: "licenses-unrecognized", args: []string{"licenses", "testdata/licenses.xml"}, exit: exitFindings},
+		{name: "licenses-json", args: []string{"licenses", "--format", "json", "testdata/licenses.xml"}, exit: exitFindings},
+		{name: "licenses-policy", args: []string{"licenses", "--policy", "../../testdata/policy/policy.yaml", "--repository", "../../testdata/repository", "../../testdata/policy"}, exit: exitFindings},
+		{name: "licenses-policy-json", args: []string{"licenses", "--format", "json", "--policy", "../../testdata/policy/policy.yaml", "--repository", "../../testdata/repository", "../../testdata/policy"}, exit: exitFindings},
+		{name: "vulns", args: []string{"vulns", "--db", "../../testdata/osv", "--repository", "../../testdata/repository", "../../testdata/policy"}, exit: exitFindings},
+		{name: "vulns-yaml", args: []string{"vulns", "--format", "yaml", "--db", "../../testdata/osv", "--repository", "../../testdata/repository", "../../testdata/policy"}, exit: exitFindings},
+		{name: "vulns-usage", args: []string{"vulns", "../../testdata/policy"}, exit: exitError},
+		{name: "updates", args: []string{"updates", "--repository", "../../testdata/updates/repository", "../../testdata/updates"}},
+		{name: "updates-json", args: []string{"updates", "--format", "json", "--repository", "../../testdata/updates/repository", "--rules", "../../testdata/updates/rules.xml", "--pre-releases", "../../testdata/updates"}},
+		{name: "updates-settings", args: []string{"updates", "../../testdata/updates"}, home: "testdata/home"},
+		{name: "updates-none", args: []string{"updates", "--repository", "../../testdata/updates/repository", "testdata/old.xml"}},
+		{name: "modules", args: []string{"modules", "testdata/project"}},
+		{name: "modules-json", args: []string{"modules", "--format", "json", "testdata/project"}},
+		{name: "unknown-command", args: []string{"frobnicate"}, exit: exitError},
+		{name: "unknown-format", args: []string{"show", "--format", "toml", "testdata/old.xml"}, exit: exitError},
+		{name: "missing-file", args: []string{"show", "testdata/missing.xml"}, exit: exitError},
+		{name: "merge", args: []string{"merge", "../../testdata/merge/base.xml", "../../testdata/merge/ours.xml", "../../testdata/merge/theirs.xml"}, exit: exitFindings},
+		{name: "merge-write", args: []string{"merge", "-w", "--format", "json", "../../testdata/merge/base.xml", "FILE", "../../testdata/merge/theirs.xml"}, file: "../../testdata/merge/ours.xml", exit: exitFindings},
+		{name: "merge-clean", args: []string{"merge", "-w", "../../testdata/merge/base.xml", "FILE", "../../testdata/merge/base.xml"}, file: "../../testdata/merge/ours.xml"},
+		{name: "fmt-format", args: []string{"fmt", "--format", "json", "testdata/unformatted.xml"}, exit: exitError},
+		{name: "usage", args: []string{"diff", "testdata/old.xml"}, exit: exitError},
+	}
+	t.Setenv("SOURCE_DATE_EPOCH", "1714564800")
+	t.Setenv("MAVEN_HOME", "")
+	t.Setenv("M2_HOME", "")
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			home := t.TempDir()
+			if test.home != "" {
+				var err error
+				home, err = filepath.Abs(test.home)
+				assert.NoError(t, err)
+			}
+			t.Setenv("HOME", home)
+			var stdin []byte
+			if test.stdin != "" {
+				var err error
+				stdin, err = os.ReadFile(test.stdin)
+				assert.NoError(t, err)
+			}
+			args := test.args
+			var file string
+			if test.file != "" {
+				content, err := os.ReadFile(test.file)
+				assert.NoError(t, err)
+				file = filepath.Join(t.TempDir(), "pom.xml")
+				assert.NoError(t, os.WriteFile(file, content, 0o644))
+				args = nil
+				for _, arg := range test.args {
+					args = append(args, strings.ReplaceAll(arg, "FILE", file))
+				}
+			}
+
+			var stdout, stderr bytes.Buffer
+			exit := run(args, bytes.NewReader(stdin), &stdout, &stderr)
+			assert.Equal(t, test.exit, exit)
+
+			got := stdout.String()
+			if stderr.Len() > 0 {
+				got += "-- stderr --\n" + stderr.String()
+			}
+			if file != "" {
+				content, err := os.ReadFile(file)
+				assert.NoError(t, err)
+				got += "-- pom.xml --\n" + string(content)
+			}
+			golden := filepath.Join("testdata", test.name+".golden")
+			if *update {
+				assert.NoError(t, os.WriteFile(golden, []byte(got), 0o644))
+			}
+			want, err := os.ReadFile(golden)
+			assert.NoError(t, err)
+			assert.Equal(t, string(want), got)
+		})
+	}
+}
+
+func TestSchema(t *testing.T) {
+	want, err := os.ReadFile("../../pom.schema.json")
+	assert.NoError(t, err)
+	var stdout, stderr bytes.Buffer
+	assert.Equal(t, 0, run([]string{"schema"}, nil, &stdout, &stderr))
+	assert.Equal(t, string(want), stdout.String())
+	assert.Empty(t, stderr.String())
+}
+
+func TestDiffRevision(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	old, err := os.ReadFile("testdata/old.xml")
+	assert.NoError(t, err)
+	changed, err := os.ReadFile("testdata/new.xml")
+	assert.NoError(t, err)
+	want, err := os.ReadFile("testdata/diff-json.golden")
+	assert.NoError(t, err)
+
+	dir := t.TempDir()
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		assert.NoError(t, err, string(out))
+	}
+	git("init", "-q")
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "pom.xml"), old, 0o644))
+	git("add", "pom.xml")
+	git("commit", "-q", "-m", "old")
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "pom.xml"), changed, 0o644))
+	git("commit", "-q", "-a", "-m", "new")
+
+	wd, err := os.Getwd()
+	assert.NoError(t, err)
+	assert.NoError(t, os.Chdir(dir))
+	t.Cleanup(func() { os.Chdir(wd) })
+
+	var stdout, stderr bytes.Buffer
+	assert.Equal(t, exitFindings, run([]string{"diff", "--format", "json", "HEAD~1:pom.xml", "pom.xml"}, nil, &stdout, &stderr))
+	assert.Equal(t, string(want), stdout.String())
+	assert.Empty(t, stderr.String())
+
+	stdout.Reset()
+	assert.Equal(t, 0, run([]string{"diff", "HEAD:./pom.xml", "pom.xml"}, nil, &stdout, &stderr))
+	assert.Empty(t, stdout.String())
+
+	stderr.Reset()
+	assert.Equal(t, exitError, run([]string{"diff", "HEAD:missing.xml", "pom.xml"}, nil, &stdout, &stderr))
+	assert.Contains(t, stderr.String(), "failed to read HEAD:missing.xml")
+
+	// Arguments that do not start with a revision are missing files.
+	for _, arg := range []string{"HEAD~5:pom.xml", "old:pom.xml"} {
+		stderr.Reset()
+		assert.Equal(t, exitError, run([]string{"diff", arg, "pom.xml"}, nil, &stdout, &stderr))
+		assert.Contains(t, stderr.String(), "failed to parse "+arg)
+		assert.Contains(t, stderr.String(), "no such file or directory")
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/chainguard-dev/gopom"
+)
+
+func init() {
+	var write bool
+	register(&command{
+		name:    "merge",
+		args:    "<base> <ours> <theirs>",
+		summary: "Merge the changes of two POMs to a common base, and exit with 1 on conflicts.",
+		flags: func(fs *flag.FlagSet) {
+			fs.BoolVar(&write, "w", false, "write the result to ours instead of printing it, as a git merge driver does")
+		},
+		run: func(e *env, args []string) error {
+			return runMerge(e, args, write)
+		},
+	})
+}
+
+type conflict struct {
+	Path   string `json:"path" yaml:"path"`
+	Base   string `json:"base,omitempty" yaml:"base,omitempty"`
+	Ours   string `json:"ours,omitempty" yaml:"ours,omitempty"`
+	Theirs string `json:"theirs,omitempty" yaml:"theirs,omitempty"`
+}
+
+// runMerge prints the merged POM, or writes it to ours with write. The
+// changes are applied to the source of ours, which keeps its formatting and
+// comments. Conflicts are reported on the standard output with write, and on
+// the standard error otherwise.
+func runMerge(e *env, args []string, write bool) error {
+	if len(args) != 3 {
+		return errUsage
+	}
+	var projects [3]*gopom.Project
+	for i, arg := range args {
+		if _, stdin := pomPath(arg); stdin {
+			return fmt.Errorf("cannot merge the standard input")
+		}
+		p, _, err := e.readProject(arg)
+		if err != nil {
+			return err
+		}
+		projects[i] = p
+	}
+
+	merged, conflicts := gopom.Merge(projects[0], projects[1], projects[2])
+	path, _ := pomPath(args[1])
+	ours, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	b, err := gopom.ApplyProject(ours, merged)
+	if err != nil {
+		return fmt.Errorf("failed to apply the merge to %s: %w", path, err)
+	}
+	report := e
+	if write {
+		// Ours is left alone when nothing changes.
+		if !bytes.Equal(b, ours) {
+			if err := writeFile(path, b); err != nil {
+				return err
+			}
+		}
+	} else {
+		if _, err := e.stdout.Write(b); err != nil {
+			return err
+		}
+		report = &env{stdin: e.stdin, stdout: e.stderr, stderr: e.stderr, format: e.format}
+	}
+	if len(conflicts) == 0 {
+		return nil
+	}
+
+	list := []conflict{}
+	for _, c := range conflicts {
+		list = append(list, conflict{Path: c.Path, Base: c.Base, Ours: c.Ours, Theirs: c.Theirs})
+	}
+	err = report.output(list, func(w io.Writer) error {
+		for _, c := range conflicts {
+			fmt.Fprintln(w, "conflict:", c)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return errFindings
+}
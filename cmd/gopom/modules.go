@@ -0,0 +1,67 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/chainguard-dev/gopom"
+)
+
+func init() {
+	var profiles stringList
+	register(&command{
+		name:    "modules",
+		args:    "[dir]",
+		summary: "List the modules of a multi-module project in build order.",
+		flags: func(fs *flag.FlagSet) {
+			fs.Var(&profiles, "P", "profiles to activate, can be repeated")
+		},
+		run: func(e *env, args []string) error {
+			return runModules(e, args, profiles)
+		},
+	})
+}
+
+type module struct {
+	GroupID    string `json:"groupId" yaml:"groupId"`
+	ArtifactID string `json:"artifactId" yaml:"artifactId"`
+	Version    string `json:"version" yaml:"version"`
+	Path       string `json:"path" yaml:"path"`
+}
+
+func runModules(e *env, args []string, profiles []string) error {
+	dir, err := optionalPath(args)
+	if err != nil {
+		return err
+	}
+	if dir == "" || dir == "-" {
+		dir = "."
+	}
+	r, err := gopom.LoadReactor(dir, profiles...)
+	if err != nil {
+		return err
+	}
+
+	root := r.Root.Dir()
+	list := []module{}
+	for _, m := range r.Modules {
+		rel, err := filepath.Rel(root, m.Path)
+		if err != nil {
+			rel = m.Path
+		}
+		list = append(list, module{
+			GroupID:    m.GroupID(),
+			ArtifactID: m.Project.ArtifactID,
+			Version:    m.Version(),
+			Path:       filepath.ToSlash(rel),
+		})
+	}
+	return e.output(list, func(w io.Writer) error {
+		for _, m := range list {
+			fmt.Fprintf(w, "%s:%s:%s\t%s\n", m.GroupID, m.ArtifactID, m.Version, m.Path)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/chainguard-dev/gopom"
+)
+
+func init() {
+	var to string
+	var declared bool
+	var profiles stringList
+	register(&command{
+		name:    "sbom",
+		args:    "[path]",
+		summary: "Print the software bill of materials of a POM.",
+		flags: func(fs *flag.FlagSet) {
+			fs.StringVar(&to, "to", "cyclonedx-json", "document format: cyclonedx-json, cyclonedx-xml, spdx-json or spdx (tag-value)")
+			fs.BoolVar(&declared, "declared", false, "use the dependencies as declared instead of the effective model")
+			fs.Var(&profiles, "P", "profiles to activate, can be repeated")
+		},
+		noFormat: true,
+		run: func(e *env, args []string) error {
+			return runSBOM(e, args, to, declared, profiles)
+		},
+	})
+}
+
+func runSBOM(e *env, args []string, to string, declared bool, profiles []string) error {
+	path, err := optionalPath(args)
+	if err != nil {
+		return err
+	}
+	generate := map[string]func(*gopom.Project, gopom.SBOMOptions) ([]byte, error){
+		"cyclonedx-json": gopom.CycloneDXJSON,
+		"cyclonedx-xml":  gopom.CycloneDXXML,
+		"spdx-json":      gopom.SPDXJSON,
+		"spdx":           gopom.SPDXTagValue,
+	}[to]
+	if generate == nil {
+		return fmt.Errorf("unknown document format %q", to)
+	}
+	p, dir, err := e.readProject(path)
+	if err != nil {
+		return err
+	}
+	if !declared {
+		if p, err = effectiveModel(p, dir, profiles); err != nil {
+			return err
+		}
+	}
+	timestamp, err := buildTime()
+	if err != nil {
+		return err
+	}
+	b, err := generate(p, gopom.SBOMOptions{Timestamp: timestamp})
+	if err != nil {
+		return err
+	}
+	_, err = e.stdout.Write(b)
+	return err
+}
+
+// buildTime returns the time set by SOURCE_DATE_EPOCH for reproducible
+// builds, or the current time.
+func buildTime() (time.Time, error) {
+	epoch := os.Getenv("SOURCE_DATE_EPOCH")
+	if epoch == "" {
+		return time.Now(), nil
+	}
+	seconds, err := strconv.ParseInt(epoch, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid SOURCE_DATE_EPOCH: %w", err)
+	}
+	return time.Unix(seconds, 0), nil
+}
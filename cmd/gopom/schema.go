@@ -0,0 +1,26 @@
+package main
+
+import (
+	"github.com/chainguard-dev/gopom"
+)
+
+func init() {
+	register(&command{
+		name:     "schema",
+		summary:  "Print the JSON Schema of the JSON and YAML representations of a POM.",
+		noFormat: true,
+		run:      runSchema,
+	})
+}
+
+func runSchema(e *env, args []string) error {
+	if len(args) != 0 {
+		return errUsage
+	}
+	b, err := gopom.JSONSchema()
+	if err != nil {
+		return err
+	}
+	_, err = e.stdout.Write(b)
+	return err
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/chainguard-dev/gopom"
+)
+
+func init() {
+	var editsFile string
+	register(&command{
+		name:    "set",
+		args:    "<path> [<query> <value>]",
+		summary: "Set the elements selected by a query, creating them when missing, and write the POM back.",
+		flags: func(fs *flag.FlagSet) {
+			fs.StringVar(&editsFile, "f", "", `file of edits to apply, one "<query> <value>" per line`)
+		},
+		noFormat: true,
+		run: func(e *env, args []string) error {
+			return runSet(e, args, editsFile)
+		},
+	})
+}
+
+func runSet(e *env, args []string, editsFile string) error {
+	var edits []gopom.Edit
+	if editsFile != "" {
+		f, err := os.Open(editsFile)
+		if err != nil {
+			return err
+		}
+		edits, err = gopom.ParseEdits(f)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %w", editsFile, err)
+		}
+	}
+	switch len(args) {
+	case 1:
+		if editsFile == "" {
+			return errUsage
+		}
+	case 3:
+		edits = append(edits, gopom.Edit{Query: args[1], Value: args[2]})
+	default:
+		return errUsage
+	}
+
+	path, stdin := pomPath(args[0])
+	if !stdin {
+		return gopom.EditFile(path, edits...)
+	}
+	b, err := io.ReadAll(e.stdin)
+	if err != nil {
+		return err
+	}
+	if b, err = gopom.ApplyEdits(b, edits...); err != nil {
+		return err
+	}
+	_, err = e.stdout.Write(b)
+	return err
+}
+
+// writeFile replaces the content of the file at path, keeping its mode.
+func writeFile(path string, b []byte) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, b, info.Mode().Perm())
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/chainguard-dev/gopom"
+)
+
+func init() {
+	register(&command{
+		name:    "show",
+		args:    "[path]",
+		summary: "Print a summary of a POM.",
+		run:     runShow,
+	})
+}
+
+type property struct {
+	Name  string `json:"name" yaml:"name"`
+	Value string `json:"value" yaml:"value"`
+}
+
+type summary struct {
+	GroupID      string     `json:"groupId,omitempty" yaml:"groupId,omitempty"`
+	ArtifactID   string     `json:"artifactId,omitempty" yaml:"artifactId,omitempty"`
+	Version      string     `json:"version,omitempty" yaml:"version,omitempty"`
+	Packaging    string     `json:"packaging" yaml:"packaging"`
+	Name         string     `json:"name,omitempty" yaml:"name,omitempty"`
+	Description  string     `json:"description,omitempty" yaml:"description,omitempty"`
+	Parent       string     `json:"parent,omitempty" yaml:"parent,omitempty"`
+	Modules      []string   `json:"modules,omitempty" yaml:"modules,omitempty"`
+	Properties   []property `json:"properties,omitempty" yaml:"properties,omitempty"`
+	Dependencies []string   `json:"dependencies,omitempty" yaml:"dependencies,omitempty"`
+	Plugins      []string   `json:"plugins,omitempty" yaml:"plugins,omitempty"`
+	Profiles     []string   `json:"profiles,omitempty" yaml:"profiles,omitempty"`
+}
+
+// coordinates joins the non-empty parts of a Maven coordinate.
+func coordinates(parts ...string) string {
+	var nonEmpty []string
+	for _, p := range parts {
+		if p != "" {
+			nonEmpty = append(nonEmpty, p)
+		}
+	}
+	return strings.Join(nonEmpty, ":")
+}
+
+func summarize(p *gopom.Project) summary {
+	s := summary{
+		GroupID:     p.GroupID,
+		ArtifactID:  p.ArtifactID,
+		Version:     p.Version,
+		Packaging:   p.Packaging,
+		Name:        p.Name,
+		Description: p.Description,
+	}
+	if s.Packaging == "" {
+		s.Packaging = "jar"
+	}
+	if p.Parent != nil {
+		s.Parent = coordinates(p.Parent.GroupID, p.Parent.ArtifactID, p.Parent.Version)
+		if s.GroupID == "" {
+			s.GroupID = p.Parent.GroupID
+		}
+		if s.Version == "" {
+			s.Version = p.Parent.Version
+		}
+	}
+	if p.Modules != nil {
+		s.Modules = *p.Modules
+	}
+	if p.Properties != nil {
+		for _, key := range p.Properties.Order {
+			s.Properties = append(s.Properties, property{Name: key, Value: p.Properties.Entries[key]})
+		}
+	}
+	if p.Dependencies != nil {
+		for _, d := range *p.Dependencies {
+			s.Dependencies = append(s.Dependencies, dependencyString(d))
+		}
+	}
+	if p.Build != nil && p.Build.Plugins != nil {
+		for _, plugin := range *p.Build.Plugins {
+			s.Plugins = append(s.Plugins, coordinates(plugin.GroupID, plugin.ArtifactID, plugin.Version))
+		}
+	}
+	if p.Profiles != nil {
+		for _, profile := range *p.Profiles {
+			s.Profiles = append(s.Profiles, profile.ID)
+		}
+	}
+	return s
+}
+
+func runShow(e *env, args []string) error {
+	path, err := optionalPath(args)
+	if err != nil {
+		return err
+	}
+	p, _, err := e.readProject(path)
+	if err != nil {
+		return err
+	}
+	s := summarize(p)
+	return e.output(s, func(w io.Writer) error {
+		fmt.Fprintf(w, "%s (%s)\n", coordinates(s.GroupID, s.ArtifactID, s.Version), s.Packaging)
+		if s.Name != "" {
+			fmt.Fprintf(w, "name: %s\n", s.Name)
+		}
+		if s.Description != "" {
+			fmt.Fprintf(w, "description: %s\n", s.Description)
+		}
+		if s.Parent != "" {
+			fmt.Fprintf(w, "parent: %s\n", s.Parent)
+		}
+		list := func(title string, items []string) {
+			if len(items) == 0 {
+				return
+			}
+			fmt.Fprintf(w, "%s:\n", title)
+			for _, item := range items {
+				fmt.Fprintf(w, "  %s\n", item)
+			}
+		}
+		list("modules", s.Modules)
+		var properties []string
+		for _, p := range s.Properties {
+			properties = append(properties, p.Name+" = "+p.Value)
+		}
+		list("properties", properties)
+		list("dependencies", s.Dependencies)
+		list("plugins", s.Plugins)
+		list("profiles", s.Profiles)
+		return nil
+	})
+}
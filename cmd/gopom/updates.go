@@ -0,0 +1,105 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/chainguard-dev/gopom"
+)
+
+func init() {
+	var repository, rules string
+	var preReleases bool
+	register(&command{
+		name:    "updates",
+		args:    "[path]",
+		summary: "List the newer versions of the dependencies, plugins, extensions and parent of a POM.",
+		flags: func(fs *flag.FlagSet) {
+			fs.StringVar(&repository, "repository", "", "local repository the metadata is read from (default: the localRepository of the Maven settings, or ~/.m2/repository)")
+			fs.StringVar(&rules, "rules", "", "versions-maven-plugin rules.xml file of versions to ignore")
+			fs.BoolVar(&preReleases, "pre-releases", false, "include alpha, beta, milestone, release candidate and SNAPSHOT versions")
+		},
+		run: func(e *env, args []string) error {
+			return runUpdates(e, args, repository, rules, preReleases)
+		},
+	})
+}
+
+type versionUpdate struct {
+	Kind       string `json:"kind" yaml:"kind"`
+	Path       string `json:"path" yaml:"path"`
+	GroupID    string `json:"groupId" yaml:"groupId"`
+	ArtifactID string `json:"artifactId" yaml:"artifactId"`
+	Version    string `json:"version" yaml:"version"`
+	Patch      string `json:"patch,omitempty" yaml:"patch,omitempty"`
+	Minor      string `json:"minor,omitempty" yaml:"minor,omitempty"`
+	Major      string `json:"major,omitempty" yaml:"major,omitempty"`
+	Latest     string `json:"latest" yaml:"latest"`
+	Segment    string `json:"segment" yaml:"segment"`
+}
+
+func runUpdates(e *env, args []string, repository, rulesPath string, preReleases bool) error {
+	path, err := optionalPath(args)
+	if err != nil {
+		return err
+	}
+	if _, repository, err = mavenSettings(repository); err != nil {
+		return err
+	}
+	opts := gopom.UpdateOptions{Repository: repository, PreReleases: preReleases}
+	if rulesPath != "" {
+		f, err := os.Open(rulesPath)
+		if err != nil {
+			return err
+		}
+		opts.Rules, err = gopom.ParseUpdateRules(f)
+		f.Close()
+		if err != nil {
+			return err
+		}
+	}
+	p, _, err := e.readProject(path)
+	if err != nil {
+		return err
+	}
+	updates, err := p.Updates(opts)
+	if err != nil {
+		return err
+	}
+
+	list := []versionUpdate{}
+	for _, u := range updates {
+		list = append(list, versionUpdate{
+			Kind:       u.Kind,
+			Path:       u.Path,
+			GroupID:    u.GroupID,
+			ArtifactID: u.ArtifactID,
+			Version:    u.Version,
+			Patch:      u.Patch,
+			Minor:      u.Minor,
+			Major:      u.Major,
+			Latest:     u.Latest(),
+			Segment:    string(u.Segment()),
+		})
+	}
+	return e.output(list, func(w io.Writer) error {
+		if len(updates) == 0 {
+			return nil
+		}
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "KIND\tARTIFACT\tCURRENT\tPATCH\tMINOR\tMAJOR")
+		orNone := func(s string) string {
+			if s == "" {
+				return "-"
+			}
+			return s
+		}
+		for _, u := range updates {
+			fmt.Fprintf(tw, "%s\t%s:%s\t%s\t%s\t%s\t%s\n", u.Kind, u.GroupID, u.ArtifactID, u.Version, orNone(u.Patch), orNone(u.Minor), orNone(u.Major))
+		}
+		return tw.Flush()
+	})
+}
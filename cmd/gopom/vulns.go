@@ -0,0 +1,80 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/chainguard-dev/gopom"
+)
+
+func init() {
+	var db, repository string
+	var profiles stringList
+	register(&command{
+		name:    "vulns",
+		args:    "--db path [path]",
+		summary: "Report the dependencies affected by advisories of an OSV database snapshot.",
+		flags: func(fs *flag.FlagSet) {
+			fs.StringVar(&db, "db", "", "OSV database: the Maven ecosystem zip or a directory of JSON advisories")
+			fs.StringVar(&repository, "repository", "", "local repository the dependencies are resolved from (default: the localRepository of the Maven settings, or ~/.m2/repository)")
+			fs.Var(&profiles, "P", "profiles to activate, can be repeated")
+		},
+		run: func(e *env, args []string) error {
+			return runVulns(e, args, db, repository, profiles)
+		},
+	})
+}
+
+type vulnerability struct {
+	ID           string   `json:"id" yaml:"id"`
+	Aliases      []string `json:"aliases,omitempty" yaml:"aliases,omitempty"`
+	Summary      string   `json:"summary,omitempty" yaml:"summary,omitempty"`
+	Path         []string `json:"path" yaml:"path"`
+	Scope        string   `json:"scope" yaml:"scope"`
+	FixedVersion string   `json:"fixedVersion,omitempty" yaml:"fixedVersion,omitempty"`
+}
+
+func runVulns(e *env, args []string, dbPath, repository string, profiles []string) error {
+	if dbPath == "" {
+		return errUsage
+	}
+	path, err := optionalPath(args)
+	if err != nil {
+		return err
+	}
+	db, err := gopom.LoadOSVDatabase(dbPath)
+	if err != nil {
+		return err
+	}
+	graph, err := e.resolveProject(path, repository, profiles)
+	if err != nil {
+		return err
+	}
+
+	found := db.Check(graph)
+	list := []vulnerability{}
+	for _, v := range found {
+		out := vulnerability{
+			ID:           v.Vulnerability.ID,
+			Aliases:      v.Vulnerability.Aliases,
+			Summary:      v.Vulnerability.Summary,
+			Scope:        v.Dependency.Scope,
+			FixedVersion: v.FixedVersion,
+		}
+		for _, c := range v.Path {
+			out.Path = append(out.Path, c.String())
+		}
+		list = append(list, out)
+	}
+	err = e.output(list, func(w io.Writer) error {
+		for _, v := range found {
+			fmt.Fprintln(w, v)
+		}
+		return nil
+	})
+	if err == nil && len(found) > 0 {
+		return errFindings
+	}
+	return err
+}
@@ -0,0 +1,300 @@
+package gopom
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strconv"
+	"sync"
+)
+
+// Comments holds the XML comments attached to an element of the POM.
+// Leading comments are the ones directly preceding the element, trailing
+// comments the ones following its end tag on the same line, or closing the
+// enclosing list after the last element. Comment text is stored without the
+// `<!--`/`-->` delimiters and surrounding whitespace.
+type Comments struct {
+	Leading  []string
+	Trailing []string
+}
+
+// Comments returns the comments attached to the dependency. The result is
+// never nil, so it can be used to add new annotations.
+func (d *Dependency) Comments() *Comments {
+	if d.comments == nil {
+		d.comments = &Comments{}
+	}
+	return d.comments
+}
+
+// Comments returns the comments attached to the plugin. The result is never
+// nil, so it can be used to add new annotations.
+func (p *Plugin) Comments() *Comments {
+	if p.comments == nil {
+		p.comments = &Comments{}
+	}
+	return p.comments
+}
+
+// Comments returns the comments attached to the profile. The result is never
+// nil, so it can be used to add new annotations.
+func (p *Profile) Comments() *Comments {
+	if p.comments == nil {
+		p.comments = &Comments{}
+	}
+	return p.comments
+}
+
+// Comments returns the comments attached to the property entry with the given
+// key. The result is never nil, so it can be used to add new annotations.
+func (p *Properties) Comments(key string) *Comments {
+	if p.comments == nil {
+		p.comments = map[string]*Comments{}
+	}
+	c, ok := p.comments[key]
+	if !ok {
+		c = &Comments{}
+		p.comments[key] = c
+	}
+	return c
+}
+
+func (d Dependency) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	type dependency Dependency
+	return encodeCommented(e, start, dependency(d), d.comments)
+}
+
+func (p Plugin) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	type plugin Plugin
+	return encodeCommented(e, start, plugin(p), p.comments)
+}
+
+func (p Profile) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	type profile Profile
+	return encodeCommented(e, start, profile(p), p.comments)
+}
+
+// The encoder does not indent comments, so leading comments are written
+// right after the preceding tag, and trailing ones after a space, which
+// keeps the output valid whatever encodes it.
+//
+// leadingWriter is the writer of marshalIndent. It records where the
+// leading comments are written, for marshalIndent to put them on their own
+// lines once the indentation of the element they precede is known.
+type leadingWriter struct {
+	bytes.Buffer
+	runs [][2]int
+}
+
+// leadingWriters maps the encoders of marshalIndent to their writer.
+var leadingWriters sync.Map
+
+// marshalIndent runs encode on an encoder indenting like xml.MarshalIndent
+// and returns what it wrote, with the leading comments on their own lines.
+// The other comments, like the ones of raw configurations, are written as
+// they are.
+func marshalIndent(prefix, indent string, encode func(e *xml.Encoder) error) ([]byte, error) {
+	var w leadingWriter
+	e := xml.NewEncoder(&w)
+	e.Indent(prefix, indent)
+	leadingWriters.Store(e, &w)
+	defer leadingWriters.Delete(e)
+	if err := encode(e); err != nil {
+		return nil, err
+	}
+	if err := e.Flush(); err != nil {
+		return nil, err
+	}
+
+	b := w.Bytes()
+	var out []byte
+	last := 0
+	for _, run := range w.runs {
+		// The encoder indents the element following the comments.
+		i := run[1]
+		if i == len(b) || b[i] != '\n' {
+			continue
+		}
+		for i++; i < len(b) && (b[i] == ' ' || b[i] == '\t'); i++ {
+		}
+		indentation := b[run[1]:i]
+		out = append(out, b[last:run[0]]...)
+		for _, c := range bytes.SplitAfter(b[run[0]:run[1]], []byte("-->")) {
+			if len(c) > 0 {
+				out = append(append(out, indentation...), c...)
+			}
+		}
+		last = run[1]
+	}
+	return append(out, b[last:]...), nil
+}
+
+func (c *Comments) leadingTokens() []xml.Token {
+	if c == nil {
+		return nil
+	}
+	var tokens []xml.Token
+	for _, text := range c.Leading {
+		tokens = append(tokens, xml.Comment(" "+text+" "))
+	}
+	return tokens
+}
+
+func (c *Comments) trailingTokens() []xml.Token {
+	if c == nil {
+		return nil
+	}
+	var tokens []xml.Token
+	for _, text := range c.Trailing {
+		tokens = append(tokens, xml.CharData(" "), xml.Comment(" "+text+" "))
+	}
+	return tokens
+}
+
+// encodeLeading writes the leading comments c, recording where they are
+// when e is an encoder of marshalIndent.
+func encodeLeading(e *xml.Encoder, c *Comments) error {
+	tokens := c.leadingTokens()
+	if len(tokens) == 0 {
+		return nil
+	}
+	w, _ := leadingWriters.Load(e)
+	lw, _ := w.(*leadingWriter)
+	start := 0
+	if lw != nil {
+		if err := e.Flush(); err != nil {
+			return err
+		}
+		start = lw.Len()
+	}
+	for _, t := range tokens {
+		if err := e.EncodeToken(t); err != nil {
+			return err
+		}
+	}
+	if lw == nil {
+		return nil
+	}
+	if err := e.Flush(); err != nil {
+		return err
+	}
+	lw.runs = append(lw.runs, [2]int{start, lw.Len()})
+	return nil
+}
+
+func encodeCommented(e *xml.Encoder, start xml.StartElement, v any, c *Comments) error {
+	if err := encodeLeading(e, c); err != nil {
+		return err
+	}
+	if err := e.EncodeElement(v, start); err != nil {
+		return err
+	}
+	for _, t := range c.trailingTokens() {
+		if err := e.EncodeToken(t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// commentable is implemented by the model elements comments can be attached
+// to.
+type commentable interface {
+	Comments() *Comments
+	// attached returns the comments without allocating them, so it is nil
+	// when there are none.
+	attached() *Comments
+}
+
+func (d *Dependency) attached() *Comments { return d.comments }
+
+func (p *Plugin) attached() *Comments { return p.comments }
+
+func (p *Profile) attached() *Comments { return p.comments }
+
+type propertyComments struct {
+	properties *Properties
+	key        string
+}
+
+func (p propertyComments) Comments() *Comments {
+	return p.properties.Comments(p.key)
+}
+
+func (p propertyComments) attached() *Comments {
+	return p.properties.comments[p.key]
+}
+
+// elementPath returns the path of the i-th child element called name under
+// the element at parent. The paths identify elements in the XML document
+// independently of the model, e.g. "project/dependencies[0]/dependency[1]".
+func elementPath(parent, name string, i int) string {
+	return parent + "/" + name + "[" + strconv.Itoa(i) + "]"
+}
+
+// commentTargets maps the element paths of p that can hold comments to the
+// model values they belong to.
+func commentTargets(p *Project) map[string]commentable {
+	targets := map[string]commentable{}
+	root := "project"
+	addDependencyTargets(targets, root+"/dependencies[0]", p.Dependencies)
+	if p.DependencyManagement != nil {
+		addDependencyTargets(targets, root+"/dependencyManagement[0]/dependencies[0]", p.DependencyManagement.Dependencies)
+	}
+	addPropertyTargets(targets, root+"/properties[0]", p.Properties)
+	if p.Build != nil {
+		addBuildTargets(targets, root+"/build[0]", &p.Build.BuildBase)
+	}
+	if p.Profiles != nil {
+		for i := range *p.Profiles {
+			profile := &(*p.Profiles)[i]
+			path := elementPath(root+"/profiles[0]", "profile", i)
+			targets[path] = profile
+			addDependencyTargets(targets, path+"/dependencies[0]", profile.Dependencies)
+			if profile.DependencyManagement != nil {
+				addDependencyTargets(targets, path+"/dependencyManagement[0]/dependencies[0]", profile.DependencyManagement.Dependencies)
+			}
+			addPropertyTargets(targets, path+"/properties[0]", profile.Properties)
+			if profile.Build != nil {
+				addBuildTargets(targets, path+"/build[0]", profile.Build)
+			}
+		}
+	}
+	return targets
+}
+
+func addDependencyTargets(targets map[string]commentable, parent string, deps *[]Dependency) {
+	if deps == nil {
+		return
+	}
+	for i := range *deps {
+		targets[elementPath(parent, "dependency", i)] = &(*deps)[i]
+	}
+}
+
+func addPluginTargets(targets map[string]commentable, parent string, plugins *[]Plugin) {
+	if plugins == nil {
+		return
+	}
+	for i := range *plugins {
+		plugin := &(*plugins)[i]
+		path := elementPath(parent, "plugin", i)
+		targets[path] = plugin
+		addDependencyTargets(targets, path+"/dependencies[0]", plugin.Dependencies)
+	}
+}
+
+func addBuildTargets(targets map[string]commentable, parent string, b *BuildBase) {
+	addPluginTargets(targets, parent+"/plugins[0]", b.Plugins)
+	if b.PluginManagement != nil {
+		addPluginTargets(targets, parent+"/pluginManagement[0]/plugins[0]", b.PluginManagement.Plugins)
+	}
+}
+
+func addPropertyTargets(targets map[string]commentable, parent string, p *Properties) {
+	if p == nil {
+		return
+	}
+	for _, key := range p.Order {
+		targets[elementPath(parent, key, 0)] = propertyComments{properties: p, key: key}
+	}
+}
@@ -0,0 +1,135 @@
+package gopom
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const commentsFilename = "./testdata/comments.xml"
+
+func TestParseComments(t *testing.T) {
+	p, err := Parse(commentsFilename)
+	if err != nil {
+		t.Fatalf("failed parsing the file: %v", err)
+	}
+	testComments(t, p)
+}
+
+func TestMarshalComments(t *testing.T) {
+	p, err := Parse(commentsFilename)
+	if err != nil {
+		t.Fatalf("failed parsing the file: %v", err)
+	}
+	out, err := p.Marshal()
+	if err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+	assert.Contains(t, string(out), "<dependencies>\n        <!-- pinned for CVE-2022-1234 -->\n        <!-- do not upgrade without QA sign-off -->\n        <dependency>")
+	assert.Contains(t, string(out), "<guava.version>32.1.2-jre</guava.version> <!-- pinned for CVE-2023-2976 -->")
+
+	roundTripped, err := parse(out)
+	if err != nil {
+		t.Fatalf("failed parsing the marshalled output: %v", err)
+	}
+	testComments(t, roundTripped)
+}
+
+func TestUpdateComments(t *testing.T) {
+	p, err := Parse(filename)
+	if err != nil {
+		t.Fatalf("failed parsing the file: %v", err)
+	}
+	d := &(*p.Dependencies)[0]
+	d.Comments().Leading = append(d.Comments().Leading, "added by tooling")
+	p.Properties.Comments("key2").Trailing = []string{"see docs"}
+
+	out, err := p.Marshal()
+	if err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+	roundTripped, err := parse(out)
+	if err != nil {
+		t.Fatalf("failed parsing the marshalled output: %v", err)
+	}
+	assert.Equal(t, []string{"added by tooling"}, (*roundTripped.Dependencies)[0].Comments().Leading)
+	assert.Equal(t, []string{"see docs"}, roundTripped.Properties.Comments("key2").Trailing)
+	assert.Empty(t, roundTripped.Properties.Comments("key").Trailing)
+	assert.True(t, strings.Count(string(out), "<!--") == 2)
+}
+
+func testComments(t *testing.T, p *Project) {
+	guava := (*p.Dependencies)[0].Comments()
+	assert.Equal(t, []string{"pinned for CVE-2022-1234", "do not upgrade without QA sign-off"}, guava.Leading)
+	assert.Empty(t, guava.Trailing)
+
+	junit := (*p.Dependencies)[1].Comments()
+	assert.Empty(t, junit.Leading)
+	assert.Equal(t, []string{"end of test dependencies"}, junit.Trailing)
+
+	compiler := &(*p.Build.Plugins)[0]
+	assert.Equal(t, []string{"keep in sync with the parent"}, compiler.Comments().Trailing)
+	assert.Equal(t, []string{"compiler plugin dependency"}, (*compiler.Dependencies)[0].Comments().Leading)
+
+	assert.Equal(t, []string{"only used on CI"}, (*p.Profiles)[0].Comments().Leading)
+
+	assert.Equal(t, []string{"bump together with the toolchain"}, p.Properties.Comments("java.version").Leading)
+	assert.Equal(t, []string{"pinned for CVE-2023-2976"}, p.Properties.Comments("guava.version").Trailing)
+}
+
+func TestEncodeCommentedDependency(t *testing.T) {
+	d := Dependency{GroupID: "com.google.guava", ArtifactID: "guava"}
+	d.Comments().Leading = []string{"pinned"}
+	d.Comments().Trailing = []string{"reviewed"}
+
+	var buf bytes.Buffer
+	e := xml.NewEncoder(&buf)
+	e.Indent("", "  ")
+	if err := e.Encode(d); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	assert.Equal(t, "<!-- pinned --><Dependency>\n  <groupId>com.google.guava</groupId>\n  <artifactId>guava</artifactId>\n</Dependency> <!-- reviewed -->", out)
+
+	// The output is well-formed XML.
+	dec := xml.NewDecoder(strings.NewReader("<dependencies>" + out + "</dependencies>"))
+	for {
+		_, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestMarshalKeepsConfigurationComments(t *testing.T) {
+	raw := "\n<a>1</a><!-- keep me here -->\n    <b>2</b>\n"
+	d := Dependency{GroupID: "org.a", ArtifactID: "a"}
+	d.Comments().Leading = []string{"pinned"}
+	p := &Project{
+		Dependencies: &[]Dependency{d},
+		Build: &Build{BuildBase: BuildBase{Plugins: &[]Plugin{{
+			ArtifactID:    "maven-compiler-plugin",
+			Configuration: &Configuration{RawConfiguration: raw},
+		}}}},
+	}
+
+	out, err := p.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Contains(t, string(out), "<configuration>"+raw+"</configuration>")
+	assert.Contains(t, string(out), "<dependencies>\n        <!-- pinned -->\n        <dependency>")
+
+	out, err = p.Format(FormatOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Contains(t, string(out), "<configuration>"+raw+"</configuration>")
+}
@@ -0,0 +1,237 @@
+package gopom
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Coordinate identifies a Maven artifact. An empty Type stands for the
+// default, jar.
+type Coordinate struct {
+	GroupID    string
+	ArtifactID string
+	Version    string
+	Type       string
+	Classifier string
+}
+
+// ParseCoordinate parses coordinates written as
+// groupId:artifactId[:type[:classifier]]:version, or groupId:artifactId
+// without a version. The default type, jar, is normalized to an empty Type,
+// like ParsePackageURL does, so g:a:jar:1 and g:a:1 parse to the same value.
+func ParseCoordinate(s string) (Coordinate, error) {
+	parts := strings.Split(s, ":")
+	for _, part := range parts {
+		if part == "" {
+			return Coordinate{}, fmt.Errorf("invalid coordinate %q: empty part", s)
+		}
+	}
+	c := Coordinate{}
+	switch len(parts) {
+	case 2:
+		c.GroupID, c.ArtifactID = parts[0], parts[1]
+	case 3:
+		c.GroupID, c.ArtifactID, c.Version = parts[0], parts[1], parts[2]
+	case 4:
+		c.GroupID, c.ArtifactID, c.Type, c.Version = parts[0], parts[1], parts[2], parts[3]
+	case 5:
+		c.GroupID, c.ArtifactID, c.Type, c.Classifier, c.Version = parts[0], parts[1], parts[2], parts[3], parts[4]
+	default:
+		return Coordinate{}, fmt.Errorf("invalid coordinate %q: expected groupId:artifactId[:type[:classifier]]:version", s)
+	}
+	c.Type = coordinateType(c.Type)
+	return c, nil
+}
+
+// coordinateType returns the Type of coordinates of type typ: empty for the
+// default, jar.
+func coordinateType(typ string) string {
+	if typ == "jar" {
+		return ""
+	}
+	return typ
+}
+
+// String formats c as groupId:artifactId[:type[:classifier]]:version,
+// without the version when it is empty.
+func (c Coordinate) String() string {
+	s := c.GroupID + ":" + c.ArtifactID
+	if c.Type != "" || c.Classifier != "" {
+		typ := c.Type
+		if typ == "" {
+			typ = "jar"
+		}
+		s += ":" + typ
+		if c.Classifier != "" {
+			s += ":" + c.Classifier
+		}
+	}
+	if c.Version != "" {
+		s += ":" + c.Version
+	}
+	return s
+}
+
+// PURL returns the package URL of c, see PackageURL.
+func (c Coordinate) PURL() string {
+	return PackageURL{Coordinate: c}.String()
+}
+
+// PackageURL is the package URL, or purl, of a Maven artifact, like
+// pkg:maven/org.apache.commons/commons-lang3@3.14.0?type=pom.
+type PackageURL struct {
+	Coordinate
+	// RepositoryURL is the repository the artifact comes from, when it is
+	// not Maven Central.
+	RepositoryURL string
+}
+
+// ParsePackageURL parses a Maven package URL. Qualifiers other than
+// classifier, type and repository_url are ignored, and so is the subpath.
+// The default type, jar, is normalized to an empty Type, so equivalent
+// package URLs parse to the same value.
+func ParsePackageURL(s string) (PackageURL, error) {
+	invalid := func(reason string) (PackageURL, error) {
+		return PackageURL{}, fmt.Errorf("invalid package URL %q: %s", s, reason)
+	}
+	scheme, rest, ok := strings.Cut(s, ":")
+	if !ok || !strings.EqualFold(scheme, "pkg") {
+		return invalid("missing pkg scheme")
+	}
+	rest = strings.TrimLeft(rest, "/")
+	rest, _, _ = strings.Cut(rest, "#")
+	rest, query, _ := strings.Cut(rest, "?")
+
+	var p PackageURL
+	if i := strings.LastIndexByte(rest, '@'); i >= 0 {
+		version, err := url.PathUnescape(rest[i+1:])
+		if err != nil {
+			return invalid("bad version escape")
+		}
+		p.Version = version
+		rest = rest[:i]
+	}
+	segments := strings.Split(strings.Trim(rest, "/"), "/")
+	if !strings.EqualFold(segments[0], "maven") {
+		return invalid("not a maven package")
+	}
+	if len(segments) != 3 {
+		return invalid("expected pkg:maven/groupId/artifactId")
+	}
+	var err error
+	if p.GroupID, err = url.PathUnescape(segments[1]); err != nil {
+		return invalid("bad groupId escape")
+	}
+	if p.ArtifactID, err = url.PathUnescape(segments[2]); err != nil {
+		return invalid("bad artifactId escape")
+	}
+	if p.GroupID == "" || p.ArtifactID == "" {
+		return invalid("empty groupId or artifactId")
+	}
+
+	if query != "" {
+		for _, q := range strings.Split(query, "&") {
+			key, value, ok := strings.Cut(q, "=")
+			if !ok {
+				return invalid("qualifier without value")
+			}
+			if value, err = url.PathUnescape(value); err != nil {
+				return invalid("bad qualifier escape")
+			}
+			switch strings.ToLower(key) {
+			case "classifier":
+				p.Classifier = value
+			case "type":
+				if value != "jar" {
+					p.Type = value
+				}
+			case "repository_url":
+				p.RepositoryURL = value
+			}
+		}
+	}
+	return p, nil
+}
+
+// String formats p in canonical form: the type qualifier is omitted when it
+// is the default, jar, and qualifiers are sorted by key. Package URLs that
+// are not canonical, like ones with type=jar or other qualifiers, do not
+// round-trip byte for byte through ParsePackageURL and String.
+func (p PackageURL) String() string {
+	s := "pkg:maven/" + purlEscape(p.GroupID, "") + "/" + purlEscape(p.ArtifactID, "")
+	if p.Version != "" {
+		s += "@" + purlEscape(p.Version, ":")
+	}
+	qualifiers := map[string]string{"classifier": p.Classifier, "repository_url": p.RepositoryURL}
+	if p.Type != "jar" {
+		qualifiers["type"] = p.Type
+	}
+	var keys []string
+	for key, value := range qualifiers {
+		if value != "" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for i, key := range keys {
+		sep := "&"
+		if i == 0 {
+			sep = "?"
+		}
+		s += sep + key + "=" + purlEscape(qualifiers[key], ":/")
+	}
+	return s
+}
+
+// purlEscape percent-encodes the characters of s that are neither
+// unreserved nor in keep.
+func purlEscape(s, keep string) string {
+	const hex = "0123456789ABCDEF"
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || strings.IndexByte(".-_~"+keep, c) >= 0 {
+			b.WriteByte(c)
+			continue
+		}
+		b.WriteByte('%')
+		b.WriteByte(hex[c>>4])
+		b.WriteByte(hex[c&15])
+	}
+	return b.String()
+}
+
+// Coordinate returns the coordinates of the project, with the groupId and
+// version inherited from the parent when not set, and the packaging as
+// type, empty for jar.
+func (p *Project) Coordinate() Coordinate {
+	return Coordinate{GroupID: effectiveGroupID(p), ArtifactID: p.ArtifactID, Version: effectiveVersion(p), Type: coordinateType(p.Packaging)}
+}
+
+// Coordinate returns the coordinates of the parent POM.
+func (p Parent) Coordinate() Coordinate {
+	return Coordinate{GroupID: p.GroupID, ArtifactID: p.ArtifactID, Version: p.Version, Type: "pom"}
+}
+
+// Coordinate returns the coordinates of the dependency, with an empty type
+// for jar.
+func (d Dependency) Coordinate() Coordinate {
+	return Coordinate{GroupID: d.GroupID, ArtifactID: d.ArtifactID, Version: d.Version, Type: coordinateType(d.Type), Classifier: d.Classifier}
+}
+
+// Coordinate returns the coordinates of the plugin, whose groupId defaults
+// to org.apache.maven.plugins.
+func (p Plugin) Coordinate() Coordinate {
+	groupID := p.GroupID
+	if groupID == "" {
+		groupID = "org.apache.maven.plugins"
+	}
+	return Coordinate{GroupID: groupID, ArtifactID: p.ArtifactID, Version: p.Version, Type: "maven-plugin"}
+}
+
+// Coordinate returns the coordinates of the build extension.
+func (e Extension) Coordinate() Coordinate {
+	return Coordinate{GroupID: e.GroupID, ArtifactID: e.ArtifactID, Version: e.Version}
+}
@@ -0,0 +1,128 @@
+package gopom
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCoordinate(t *testing.T) {
+	for _, test := range []struct {
+		s    string
+		want Coordinate
+	}{
+		{"junit:junit", Coordinate{GroupID: "junit", ArtifactID: "junit"}},
+		{"junit:junit:4.13.2", Coordinate{GroupID: "junit", ArtifactID: "junit", Version: "4.13.2"}},
+		{"com.example:bom:pom:1.0", Coordinate{GroupID: "com.example", ArtifactID: "bom", Type: "pom", Version: "1.0"}},
+		{"io.netty:netty-tcnative:jar:linux-x86_64:2.0.61.Final", Coordinate{GroupID: "io.netty", ArtifactID: "netty-tcnative", Classifier: "linux-x86_64", Version: "2.0.61.Final"}},
+	} {
+		c, err := ParseCoordinate(test.s)
+		if assert.NoError(t, err, test.s) {
+			assert.Equal(t, test.want, c)
+			assert.Equal(t, test.s, c.String())
+		}
+	}
+
+	for _, s := range []string{"junit", "junit::4.13.2", "a:b:c:d:e:f", ""} {
+		_, err := ParseCoordinate(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestCoordinateDefaultType(t *testing.T) {
+	explicit, err := ParseCoordinate("com.example:lib:jar:1.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	implicit, err := ParseCoordinate("com.example:lib:1.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, implicit, explicit)
+
+	purl, err := ParsePackageURL("pkg:maven/com.example/lib@1.0?type=jar")
+	if assert.NoError(t, err) {
+		assert.Equal(t, explicit, purl.Coordinate)
+	}
+	p := &Project{GroupID: "com.example", ArtifactID: "lib", Version: "1.0", Packaging: "jar"}
+	assert.Equal(t, explicit, p.Coordinate())
+	assert.Equal(t, explicit, Dependency{GroupID: "com.example", ArtifactID: "lib", Version: "1.0", Type: "jar"}.Coordinate())
+}
+
+func TestPackageURL(t *testing.T) {
+	for _, test := range []struct {
+		p    PackageURL
+		want string
+	}{
+		{PackageURL{Coordinate: Coordinate{GroupID: "junit", ArtifactID: "junit", Version: "4.13.2"}}, "pkg:maven/junit/junit@4.13.2"},
+		{PackageURL{Coordinate: Coordinate{GroupID: "junit", ArtifactID: "junit", Version: "4.13.2", Type: "jar"}}, "pkg:maven/junit/junit@4.13.2"},
+		{PackageURL{Coordinate: Coordinate{GroupID: "com.example", ArtifactID: "bom", Version: "1.0", Type: "pom"}}, "pkg:maven/com.example/bom@1.0?type=pom"},
+		{PackageURL{Coordinate: Coordinate{GroupID: "com.example", ArtifactID: "lib"}}, "pkg:maven/com.example/lib"},
+		{
+			PackageURL{Coordinate: Coordinate{GroupID: "com.example", ArtifactID: "lib", Version: "1.0+build 7", Type: "test-jar", Classifier: "tests"}},
+			"pkg:maven/com.example/lib@1.0%2Bbuild%207?classifier=tests&type=test-jar",
+		},
+		{
+			PackageURL{Coordinate: Coordinate{GroupID: "org.apache.xmlgraphics", ArtifactID: "batik-anim", Version: "1.9.1"}, RepositoryURL: "https://repo.spring.io/release?a=b&c"},
+			"pkg:maven/org.apache.xmlgraphics/batik-anim@1.9.1?repository_url=https://repo.spring.io/release%3Fa%3Db%26c",
+		},
+	} {
+		assert.Equal(t, test.want, test.p.String())
+
+		parsed, err := ParsePackageURL(test.want)
+		if assert.NoError(t, err, test.want) {
+			want := test.p
+			if want.Type == "jar" {
+				want.Type = ""
+			}
+			assert.Equal(t, want, parsed)
+		}
+	}
+}
+
+func TestParsePackageURL(t *testing.T) {
+	p, err := ParsePackageURL("PKG:Maven/org.example/a%2Db@1.0:rc1?Classifier=sources&checksum=sha1:abc#sub/path")
+	if assert.NoError(t, err) {
+		assert.Equal(t, PackageURL{Coordinate: Coordinate{GroupID: "org.example", ArtifactID: "a-b", Version: "1.0:rc1", Classifier: "sources"}}, p)
+	}
+
+	// Non-canonical package URLs parse to the same value as their canonical
+	// form, which String returns.
+	for s, canonical := range map[string]string{
+		"pkg:maven/junit/junit@4.13.2?type=jar":                                     "pkg:maven/junit/junit@4.13.2",
+		"pkg:maven/com.example/lib@1.0?type=test-jar&classifier=tests":              "pkg:maven/com.example/lib@1.0?classifier=tests&type=test-jar",
+		"pkg:maven/com.example/lib@1.0?checksum=sha1:abc&type=jar&classifier=tests": "pkg:maven/com.example/lib@1.0?classifier=tests",
+	} {
+		p, err := ParsePackageURL(s)
+		if assert.NoError(t, err, s) {
+			want, err := ParsePackageURL(canonical)
+			assert.NoError(t, err, canonical)
+			assert.Equal(t, want, p, s)
+			assert.Equal(t, canonical, p.String(), s)
+		}
+	}
+
+	for _, s := range []string{
+		"maven/junit/junit@4.13.2",
+		"pkg:npm/left-pad@1.3.0",
+		"pkg:maven/junit@4.13.2",
+		"pkg:maven/junit/junit@4.13.2?type",
+		"pkg:maven/junit/ju%zznit",
+	} {
+		_, err := ParsePackageURL(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestCoordinateAccessors(t *testing.T) {
+	p, err := Parse("testdata/polyglot/lib/pom.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, Coordinate{GroupID: "com.example", ArtifactID: "lib", Version: "1.0.0"}, p.Coordinate())
+	assert.Equal(t, "pkg:maven/com.example/polyglot@1.0.0?type=pom", p.Parent.Coordinate().PURL())
+
+	assert.Equal(t, "org.apache.maven.plugins:maven-compiler-plugin:maven-plugin:3.11.0", Plugin{ArtifactID: "maven-compiler-plugin", Version: "3.11.0"}.Coordinate().String())
+	assert.Equal(t, "com.example:ext:1.0", Extension{GroupID: "com.example", ArtifactID: "ext", Version: "1.0"}.Coordinate().String())
+	assert.Equal(t, "io.netty:netty-tcnative:jar:linux-x86_64:2.0.61.Final", Dependency{GroupID: "io.netty", ArtifactID: "netty-tcnative", Version: "2.0.61.Final", Classifier: "linux-x86_64"}.Coordinate().String())
+}
@@ -0,0 +1,234 @@
+package gopom
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	cycloneDXSchema    = "http://cyclonedx.org/schema/bom-1.5.schema.json"
+	cycloneDXNamespace = "http://cyclonedx.org/schema/bom/1.5"
+)
+
+// cdxBOM is a CycloneDX 1.5 document, with the fields gopom writes.
+type cdxBOM struct {
+	XMLName      xml.Name        `json:"-" xml:"bom"`
+	XMLNS        string          `json:"-" xml:"xmlns,attr"`
+	Schema       string          `json:"$schema" xml:"-"`
+	BOMFormat    string          `json:"bomFormat" xml:"-"`
+	SpecVersion  string          `json:"specVersion" xml:"-"`
+	SerialNumber string          `json:"serialNumber,omitempty" xml:"serialNumber,attr,omitempty"`
+	Version      int             `json:"version" xml:"version,attr"`
+	Metadata     cdxMetadata     `json:"metadata" xml:"metadata"`
+	Components   []cdxComponent  `json:"components" xml:"components>component"`
+	Dependencies []cdxDependency `json:"dependencies" xml:"dependencies>dependency"`
+}
+
+type cdxMetadata struct {
+	Timestamp string       `json:"timestamp,omitempty" xml:"timestamp,omitempty"`
+	Tools     cdxTools     `json:"tools" xml:"tools"`
+	Component cdxComponent `json:"component" xml:"component"`
+}
+
+type cdxTools struct {
+	Components []cdxComponent `json:"components" xml:"components>component"`
+}
+
+type cdxComponent struct {
+	Type     string      `json:"type" xml:"type,attr"`
+	BOMRef   string      `json:"bom-ref,omitempty" xml:"bom-ref,attr,omitempty"`
+	Group    string      `json:"group,omitempty" xml:"group,omitempty"`
+	Name     string      `json:"name" xml:"name"`
+	Version  string      `json:"version,omitempty" xml:"version,omitempty"`
+	Scope    string      `json:"scope,omitempty" xml:"scope,omitempty"`
+	Hashes   cdxHashes   `json:"hashes,omitempty" xml:"hashes,omitempty"`
+	Licenses cdxLicenses `json:"licenses,omitempty" xml:"licenses,omitempty"`
+	PURL     string      `json:"purl,omitempty" xml:"purl,omitempty"`
+}
+
+// cdxHashes and cdxLicenses marshal as wrapping elements in XML, which
+// encoding/xml only omits when empty for fields of their own type.
+type cdxHashes []cdxHash
+
+func (h cdxHashes) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return e.EncodeElement(struct {
+		Hashes []cdxHash `xml:"hash"`
+	}{h}, start)
+}
+
+type cdxHash struct {
+	Algorithm string `json:"alg" xml:"alg,attr"`
+	Content   string `json:"content" xml:",chardata"`
+}
+
+// cdxLicenses wraps each license in a "license" object in JSON. In XML,
+// licenses are directly the children of the "licenses" element. A license
+// expression is the only item of its list.
+type cdxLicenses []cdxLicenseChoice
+
+func (l cdxLicenses) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	v := struct {
+		Licenses   []*cdxLicense `xml:"license"`
+		Expression string        `xml:"expression,omitempty"`
+	}{}
+	for _, c := range l {
+		if c.License != nil {
+			v.Licenses = append(v.Licenses, c.License)
+		}
+		if c.Expression != "" {
+			v.Expression = c.Expression
+		}
+	}
+	return e.EncodeElement(v, start)
+}
+
+type cdxLicenseChoice struct {
+	License    *cdxLicense `json:"license,omitempty"`
+	Expression string      `json:"expression,omitempty"`
+}
+
+// cdxLicense has the SPDX identifier of a recognized license, or the name
+// of another.
+type cdxLicense struct {
+	ID   string `json:"id,omitempty" xml:"id,omitempty"`
+	Name string `json:"name,omitempty" xml:"name,omitempty"`
+	URL  string `json:"url,omitempty" xml:"url,omitempty"`
+}
+
+// cdxDependency lists the references a component depends on: as a
+// "dependsOn" array in JSON, and as nested "dependency" elements in XML.
+type cdxDependency struct {
+	Ref       string   `json:"ref"`
+	DependsOn []string `json:"dependsOn"`
+}
+
+func (d cdxDependency) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	type ref struct {
+		Ref string `xml:"ref,attr"`
+	}
+	v := struct {
+		Ref       string `xml:"ref,attr"`
+		DependsOn []ref  `xml:"dependency"`
+	}{Ref: d.Ref}
+	for _, r := range d.DependsOn {
+		v.DependsOn = append(v.DependsOn, ref{r})
+	}
+	return e.EncodeElement(v, start)
+}
+
+// CycloneDXJSON returns the CycloneDX 1.5 bill of materials of p, in JSON.
+// Components are identified by their package URL, and sorted so the same
+// project and options always give the same document. Their licenses have
+// SPDX identifiers when NormalizeLicense recognizes them.
+func CycloneDXJSON(p *Project, opts SBOMOptions) ([]byte, error) {
+	b, err := json.MarshalIndent(cycloneDX(p, opts), "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal: %w", err)
+	}
+	return append(b, '\n'), nil
+}
+
+// CycloneDXXML returns the CycloneDX 1.5 bill of materials of p, in XML,
+// see CycloneDXJSON.
+func CycloneDXXML(p *Project, opts SBOMOptions) ([]byte, error) {
+	b, err := xml.MarshalIndent(cycloneDX(p, opts), "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal: %w", err)
+	}
+	return append(append([]byte(xml.Header), b...), '\n'), nil
+}
+
+func cycloneDX(p *Project, opts SBOMOptions) *cdxBOM {
+	s := newSBOM(p, opts)
+	bom := &cdxBOM{
+		XMLNS:        cycloneDXNamespace,
+		Schema:       cycloneDXSchema,
+		BOMFormat:    "CycloneDX",
+		SpecVersion:  "1.5",
+		SerialNumber: opts.SerialNumber,
+		Version:      1,
+		Metadata: cdxMetadata{
+			Tools:     cdxTools{Components: []cdxComponent{{Type: "application", Name: "gopom"}}},
+			Component: cdxPackage(s.root, "application"),
+		},
+		Components:   []cdxComponent{},
+		Dependencies: []cdxDependency{},
+	}
+	if !opts.Timestamp.IsZero() {
+		bom.Metadata.Timestamp = opts.Timestamp.UTC().Format(time.RFC3339)
+	}
+	for _, pkg := range s.packages {
+		c := cdxPackage(pkg, "library")
+		switch {
+		case pkg.scope == "test":
+			c.Scope = "excluded"
+		case pkg.optional:
+			c.Scope = "optional"
+		default:
+			c.Scope = "required"
+		}
+		bom.Components = append(bom.Components, c)
+	}
+
+	// Every component gets an entry, even without dependencies, to tell
+	// they have none.
+	dependsOn := map[string][]string{s.root.ref: {}}
+	for _, pkg := range s.packages {
+		dependsOn[pkg.ref] = []string{}
+	}
+	for i, e := range s.edges {
+		if i > 0 && s.edges[i-1].from == e.from && s.edges[i-1].to == e.to {
+			continue
+		}
+		dependsOn[e.from] = append(dependsOn[e.from], e.to)
+	}
+	bom.Dependencies = append(bom.Dependencies, cdxDependency{Ref: s.root.ref, DependsOn: dependsOn[s.root.ref]})
+	for _, pkg := range s.packages {
+		bom.Dependencies = append(bom.Dependencies, cdxDependency{Ref: pkg.ref, DependsOn: dependsOn[pkg.ref]})
+	}
+	return bom
+}
+
+// cdxLicenseChoices returns the licenses of a component: the SPDX
+// identifier of each recognized license, or its name otherwise. When one
+// of them is recognized as a compound expression, like a dual license,
+// which CycloneDX cannot list next to other licenses, the component has
+// the expression of all its licenses instead, see NormalizeLicenses.
+func cdxLicenseChoices(licenses []License) cdxLicenses {
+	l := NormalizeLicenses(licenses)
+	var choices cdxLicenses
+	for _, n := range l.Licenses {
+		if strings.Contains(n.SPDX, " ") {
+			return cdxLicenses{{Expression: l.Expression}}
+		}
+		name := n.Name
+		if name == "" {
+			name = n.URL
+		}
+		license := &cdxLicense{ID: n.SPDX, URL: n.URL}
+		if n.SPDX == "" {
+			license.Name = name
+		}
+		choices = append(choices, cdxLicenseChoice{License: license})
+	}
+	return choices
+}
+
+func cdxPackage(pkg sbomPackage, typ string) cdxComponent {
+	c := cdxComponent{
+		Type:    typ,
+		BOMRef:  pkg.ref,
+		Group:   pkg.GroupID,
+		Name:    pkg.ArtifactID,
+		Version: pkg.Version,
+		PURL:    pkg.ref,
+	}
+	for _, h := range pkg.hashes {
+		c.Hashes = append(c.Hashes, cdxHash{Algorithm: h.Algorithm, Content: h.Value})
+	}
+	c.Licenses = cdxLicenseChoices(pkg.licenses)
+	return c
+}
@@ -0,0 +1,220 @@
+package gopom
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const sbomFilename = "testdata/sbom/pom.xml"
+
+// sbomGraph returns a resolved dependency graph of testdata/sbom/pom.xml.
+func sbomGraph(t *testing.T, p *Project) []*DependencyNode {
+	t.Helper()
+	deps := *p.Dependencies
+	failureAccess := &DependencyNode{
+		Dependency: Dependency{GroupID: "com.google.guava", ArtifactID: "failureaccess", Version: "1.0.2"},
+		Hashes:     []Hash{{Algorithm: "SHA-1", Value: "c4a06a64e650562f30b7bf9aaec1bfed43aca12b"}},
+		Licenses:   []License{{Name: "The Apache Software License, Version 2.0", URL: "http://www.apache.org/licenses/LICENSE-2.0.txt"}},
+	}
+	opentest4j := &DependencyNode{
+		Dependency: Dependency{GroupID: "org.opentest4j", ArtifactID: "opentest4j", Version: "1.3.0", Scope: "test"},
+	}
+	return []*DependencyNode{
+		{
+			Dependency:   deps[0],
+			Hashes:       []Hash{{Algorithm: "SHA-256", Value: "a3a6bfd3e5b5a0e8e4bda4b0da0c0a0c80b0f7d9a3e5e9f0f2c9a1d3a5b8c7e6"}},
+			Dependencies: []*DependencyNode{failureAccess},
+		},
+		{Dependency: deps[1]},
+		{
+			Dependency: deps[2],
+			Dependencies: []*DependencyNode{
+				opentest4j,
+				// Guava is also a transitive test dependency: it stays
+				// required through the direct dependency.
+				{Dependency: Dependency{GroupID: "com.google.guava", ArtifactID: "guava", Version: "33.0.0-jre", Scope: "test"}},
+			},
+		},
+	}
+}
+
+func TestCycloneDXJSON(t *testing.T) {
+	p, err := Parse(sbomFilename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := SBOMOptions{
+		Graph:        sbomGraph(t, p),
+		SerialNumber: "urn:uuid:3e671687-395b-41f5-a30f-a58921a69b79",
+		Timestamp:    time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+	}
+	b, err := CycloneDXJSON(p, opts)
+	if err != nil {
+		t.Fatalf("failed to generate the SBOM: %v", err)
+	}
+	want, err := os.ReadFile("testdata/sbom/bom.cdx.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, string(want), string(b))
+
+	// The output does not depend on the order of the graph.
+	opts.Graph[0], opts.Graph[2] = opts.Graph[2], opts.Graph[0]
+	again, err := CycloneDXJSON(p, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, string(b), string(again))
+
+	checkSchemaFile(t, "testdata/cyclonedx-1.5.schema.json", b)
+}
+
+func TestCycloneDXJSONDeclared(t *testing.T) {
+	p, err := Parse(sbomFilename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := CycloneDXJSON(p, SBOMOptions{})
+	if err != nil {
+		t.Fatalf("failed to generate the SBOM: %v", err)
+	}
+	checkSchemaFile(t, "testdata/cyclonedx-1.5.schema.json", b)
+
+	var bom struct {
+		Metadata struct {
+			Timestamp string
+			Component struct {
+				PURL     string
+				Licenses []map[string]map[string]string
+			}
+		}
+		Components []struct {
+			PURL  string
+			Scope string
+		}
+		Dependencies []struct {
+			Ref       string
+			DependsOn []string
+		}
+	}
+	if err := json.Unmarshal(b, &bom); err != nil {
+		t.Fatal(err)
+	}
+	assert.Empty(t, bom.Metadata.Timestamp)
+	assert.Equal(t, "pkg:maven/com.example/service@2.0.0", bom.Metadata.Component.PURL)
+	assert.Equal(t, "Apache-2.0", bom.Metadata.Component.Licenses[0]["license"]["id"])
+
+	var components []string
+	for _, c := range bom.Components {
+		components = append(components, c.PURL+" "+c.Scope)
+	}
+	assert.Equal(t, []string{
+		"pkg:maven/com.google.guava/guava@33.0.0-jre required",
+		"pkg:maven/io.netty/netty-transport-native-epoll@4.1.100.Final?classifier=linux-x86_64 optional",
+		"pkg:maven/org.junit.jupiter/junit-jupiter@5.10.0 excluded",
+	}, components)
+	assert.Equal(t, "pkg:maven/com.example/service@2.0.0", bom.Dependencies[0].Ref)
+	assert.Len(t, bom.Dependencies[0].DependsOn, 3)
+}
+
+func TestCycloneDXXML(t *testing.T) {
+	p, err := Parse(sbomFilename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := CycloneDXXML(p, SBOMOptions{
+		Graph:        sbomGraph(t, p),
+		SerialNumber: "urn:uuid:3e671687-395b-41f5-a30f-a58921a69b79",
+		Timestamp:    time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+	})
+	if err != nil {
+		t.Fatalf("failed to generate the SBOM: %v", err)
+	}
+	want, err := os.ReadFile("testdata/sbom/bom.cdx.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, string(want), string(b))
+
+	var bom struct {
+		XMLName    xml.Name
+		Components []struct {
+			Purl string `xml:"purl"`
+		} `xml:"components>component"`
+		Dependencies []struct {
+			Ref       string `xml:"ref,attr"`
+			DependsOn []struct {
+				Ref string `xml:"ref,attr"`
+			} `xml:"dependency"`
+		} `xml:"dependencies>dependency"`
+	}
+	if err := xml.Unmarshal(b, &bom); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, xml.Name{Space: "http://cyclonedx.org/schema/bom/1.5", Local: "bom"}, bom.XMLName)
+	assert.Len(t, bom.Components, 5)
+	assert.Len(t, bom.Dependencies, 6)
+}
+
+// TestCycloneDXXMLSchema validates the output TestCycloneDXXML checks.
+func TestCycloneDXXMLSchema(t *testing.T) {
+	b, err := os.ReadFile("testdata/sbom/bom.cdx.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkXSDFile(t, "testdata/cyclonedx-1.5.xsd", b)
+}
+
+func TestCycloneDXLicenses(t *testing.T) {
+	assert.Equal(t, cdxLicenses{
+		{License: &cdxLicense{ID: "Apache-2.0", URL: "https://www.apache.org/licenses/LICENSE-2.0.txt"}},
+		{License: &cdxLicense{Name: "Example Commercial License"}},
+	}, cdxLicenseChoices([]License{
+		{Name: "The Apache Software License, Version 2.0", URL: "https://www.apache.org/licenses/LICENSE-2.0.txt"},
+		{Name: "Example Commercial License"},
+	}))
+	assert.Equal(t, cdxLicenses{
+		{Expression: "MIT OR (CDDL-1.1 OR GPL-2.0-only WITH Classpath-exception-2.0)"},
+	}, cdxLicenseChoices([]License{{Name: "MIT License"}, {Name: "CDDL + GPLv2 with classpath exception"}}))
+
+	p := &Project{
+		GroupID:    "com.example",
+		ArtifactID: "app",
+		Version:    "1.0",
+		Licenses:   &[]License{{Name: "CDDL + GPLv2 with classpath exception"}},
+	}
+	b, err := CycloneDXJSON(p, SBOMOptions{})
+	if err != nil {
+		t.Fatalf("failed to generate the SBOM: %v", err)
+	}
+	checkSchemaFile(t, "testdata/cyclonedx-1.5.schema.json", b)
+	b, err = CycloneDXXML(p, SBOMOptions{})
+	if err != nil {
+		t.Fatalf("failed to generate the SBOM: %v", err)
+	}
+	assert.Contains(t, string(b), "<expression>CDDL-1.1 OR GPL-2.0-only WITH Classpath-exception-2.0</expression>")
+	checkXSDFile(t, "testdata/cyclonedx-1.5.xsd", b)
+}
+
+// checkXSDFile validates b against the XML schema at path with xmllint. The
+// test is skipped when xmllint is not installed.
+func checkXSDFile(t *testing.T, path string, b []byte) {
+	t.Helper()
+	xmllint, err := exec.LookPath("xmllint")
+	if err != nil {
+		t.Skipf("xmllint is not installed, cannot validate against %s", path)
+	}
+	doc := filepath.Join(t.TempDir(), "doc.xml")
+	if err := os.WriteFile(doc, b, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	out, err := exec.Command(xmllint, "--noout", "--schema", path, doc).CombinedOutput()
+	assert.NoError(t, err, string(out))
+}
@@ -0,0 +1,1207 @@
+package gopom
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Section selects parts of a POM to decode with Decode.
+type Section uint
+
+const (
+	// SectionCoordinates covers modelVersion, groupId, artifactId, version,
+	// packaging and parent.
+	SectionCoordinates Section = 1 << iota
+	// SectionMetadata covers the descriptive elements: name, description,
+	// url, inceptionYear, organization, licenses, developers, contributors,
+	// mailingLists, prerequisites, scm, issueManagement and ciManagement.
+	SectionMetadata
+	SectionProperties
+	SectionModules
+	SectionDependencies
+	SectionDependencyManagement
+	// SectionRepositories covers repositories, pluginRepositories and
+	// distributionManagement.
+	SectionRepositories
+	SectionBuild
+	SectionReporting
+	SectionProfiles
+
+	// SectionAll decodes the whole POM.
+	SectionAll = SectionCoordinates | SectionMetadata | SectionProperties |
+		SectionModules | SectionDependencies | SectionDependencyManagement |
+		SectionRepositories | SectionBuild | SectionReporting | SectionProfiles
+)
+
+// projectSections maps the children of the project element to the section
+// they belong to.
+var projectSections = map[string]Section{
+	"modelVersion":           SectionCoordinates,
+	"groupId":                SectionCoordinates,
+	"artifactId":             SectionCoordinates,
+	"version":                SectionCoordinates,
+	"packaging":              SectionCoordinates,
+	"parent":                 SectionCoordinates,
+	"name":                   SectionMetadata,
+	"description":            SectionMetadata,
+	"url":                    SectionMetadata,
+	"inceptionYear":          SectionMetadata,
+	"organization":           SectionMetadata,
+	"licenses":               SectionMetadata,
+	"developers":             SectionMetadata,
+	"contributors":           SectionMetadata,
+	"mailingLists":           SectionMetadata,
+	"prerequisites":          SectionMetadata,
+	"scm":                    SectionMetadata,
+	"issueManagement":        SectionMetadata,
+	"ciManagement":           SectionMetadata,
+	"properties":             SectionProperties,
+	"modules":                SectionModules,
+	"dependencies":           SectionDependencies,
+	"dependencyManagement":   SectionDependencyManagement,
+	"repositories":           SectionRepositories,
+	"pluginRepositories":     SectionRepositories,
+	"distributionManagement": SectionRepositories,
+	"build":                  SectionBuild,
+	"reporting":              SectionReporting,
+	"profiles":               SectionProfiles,
+}
+
+// ParseSections loads the file at path and decodes the requested sections
+// of the POM with Decode.
+func ParseSections(path string, sections Section) (*Project, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Decode(b, sections)
+}
+
+// Decode decodes the requested sections of the POM in b, skipping over the
+// other ones. The fields of the returned project that belong to sections
+// that were not requested are left empty.
+//
+// Decode walks the XML tokens directly instead of relying on reflection like
+// xml.Unmarshal, which makes it noticeably cheaper, especially when only a
+// few sections are needed. Unlike Parse, it does not attach comments to the
+// model.
+func Decode(b []byte, sections Section) (*Project, error) {
+	d := &decoder{
+		r: &byteReader{b: b},
+		b: b,
+	}
+	d.d = xml.NewDecoder(d.r)
+	for {
+		tok, err := d.d.RawToken()
+		if err == io.EOF {
+			return nil, io.EOF
+		}
+		if err != nil {
+			return nil, err
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		if start.Name.Local != "project" {
+			return nil, fmt.Errorf("expected element type <project> but have <%s>", start.Name.Local)
+		}
+		p := &Project{}
+		for _, attr := range start.Attr {
+			switch attr.Name.Local {
+			case "xmlns":
+				p.Xmlns = attr.Value
+			case "xsi":
+				p.Xsi = attr.Value
+			case "schemaLocation":
+				p.SchemaLocation = attr.Value
+			}
+		}
+		// The raw element name is not translated, so use the default
+		// namespace as xml.Unmarshal would.
+		p.XMLName = xml.Name{Space: p.Xmlns, Local: start.Name.Local}
+		if err := d.project(start, p, sections); err != nil {
+			return nil, err
+		}
+		return p, nil
+	}
+}
+
+// decoder reads the POM model from raw XML tokens. As raw tokens are not
+// checked for matching start and end elements, the decoder does it itself.
+type decoder struct {
+	d *xml.Decoder
+	r *byteReader
+	b []byte
+	// skipped counts the bytes jumped over by skip without going through d.
+	skipped int64
+}
+
+// byteReader feeds b to the XML decoder one byte at a time, which keeps the
+// decoder from reading ahead and lets skip move past elements directly.
+type byteReader struct {
+	b   []byte
+	pos int
+}
+
+func (r *byteReader) Read(p []byte) (int, error) {
+	if r.pos >= len(r.b) {
+		return 0, io.EOF
+	}
+	n := copy(p, r.b[r.pos:])
+	r.pos += n
+	return n, nil
+}
+
+func (r *byteReader) ReadByte() (byte, error) {
+	if r.pos >= len(r.b) {
+		return 0, io.EOF
+	}
+	c := r.b[r.pos]
+	r.pos++
+	return c, nil
+}
+
+// offset returns the offset in b of the decoder's current position.
+func (d *decoder) offset() int64 {
+	return d.d.InputOffset() + d.skipped
+}
+
+func (d *decoder) token() (xml.Token, error) {
+	tok, err := d.d.RawToken()
+	if err == io.EOF {
+		return nil, io.ErrUnexpectedEOF
+	}
+	return tok, err
+}
+
+func checkEnd(start xml.StartElement, end xml.EndElement) error {
+	if start.Name != end.Name {
+		return fmt.Errorf("element <%s> closed by </%s>", start.Name.Local, end.Name.Local)
+	}
+	return nil
+}
+
+// elements calls fn for every child element of start, which must consume
+// the whole child element.
+func (d *decoder) elements(start xml.StartElement, fn func(child xml.StartElement) error) error {
+	for {
+		tok, err := d.token()
+		if err != nil {
+			return err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if err := fn(t); err != nil {
+				return err
+			}
+		case xml.EndElement:
+			return checkEnd(start, t)
+		}
+	}
+}
+
+// skip consumes the rest of the element start. Rather than decoding the
+// tokens of the element, it scans the bytes for the matching end tag, so the
+// content of skipped elements is not checked beyond that.
+func (d *decoder) skip(start xml.StartElement) error {
+	pos := d.r.pos
+	if bytes.HasSuffix(d.b[:pos], []byte("/>")) {
+		// The decoder still has to return the end of self-closing elements.
+		return d.skipTokens(start)
+	}
+	end, err := skipElement(d.b, pos, start.Name)
+	if err != nil {
+		return err
+	}
+	d.skipped += int64(end - pos)
+	d.r.pos = end
+	return nil
+}
+
+func (d *decoder) skipTokens(start xml.StartElement) error {
+	open := []xml.Name{start.Name}
+	for len(open) > 0 {
+		tok, err := d.token()
+		if err != nil {
+			return err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			open = append(open, t.Name)
+		case xml.EndElement:
+			if err := checkEnd(xml.StartElement{Name: open[len(open)-1]}, t); err != nil {
+				return err
+			}
+			open = open[:len(open)-1]
+		}
+	}
+	return nil
+}
+
+// skipElement returns the offset following the end tag of the element
+// called name whose content starts at pos in b.
+func skipElement(b []byte, pos int, name xml.Name) (int, error) {
+	depth := 1
+	for {
+		i := bytes.IndexByte(b[pos:], '<')
+		if i < 0 {
+			return 0, io.ErrUnexpectedEOF
+		}
+		pos += i
+		rest := b[pos:]
+		var end []byte
+		switch {
+		case bytes.HasPrefix(rest, []byte("<!--")):
+			end = []byte("-->")
+		case bytes.HasPrefix(rest, []byte("<![CDATA[")):
+			end = []byte("]]>")
+		case bytes.HasPrefix(rest, []byte("<?")):
+			end = []byte("?>")
+		case bytes.HasPrefix(rest, []byte("</")):
+			depth--
+			j := bytes.IndexByte(rest, '>')
+			if j < 0 {
+				return 0, io.ErrUnexpectedEOF
+			}
+			if depth == 0 {
+				got := string(bytes.TrimSpace(rest[2:j]))
+				want := name.Local
+				if name.Space != "" {
+					want = name.Space + ":" + want
+				}
+				if got != want {
+					return 0, fmt.Errorf("element <%s> closed by </%s>", want, got)
+				}
+				return pos + j + 1, nil
+			}
+			pos += j + 1
+			continue
+		default:
+			j, err := tagEnd(rest)
+			if err != nil {
+				return 0, err
+			}
+			if rest[j-1] != '/' {
+				depth++
+			}
+			pos += j + 1
+			continue
+		}
+		j := bytes.Index(rest, end)
+		if j < 0 {
+			return 0, io.ErrUnexpectedEOF
+		}
+		pos += j + len(end)
+	}
+}
+
+// tagEnd returns the index of the '>' closing the start tag at the beginning
+// of b, ignoring the ones in quoted attribute values.
+func tagEnd(b []byte) (int, error) {
+	var quote byte
+	for i, c := range b {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '>':
+			return i, nil
+		}
+	}
+	return 0, io.ErrUnexpectedEOF
+}
+
+// text returns the character data directly contained in start, skipping
+// nested elements the same way xml.Unmarshal does.
+func (d *decoder) text(start xml.StartElement) (string, error) {
+	var (
+		s     string
+		extra []byte
+	)
+	for {
+		tok, err := d.token()
+		if err != nil {
+			return "", err
+		}
+		switch t := tok.(type) {
+		case xml.CharData:
+			switch {
+			case s == "" && extra == nil:
+				s = string(t)
+			default:
+				extra = append(append(extra, s...), t...)
+				s = ""
+			}
+		case xml.StartElement:
+			if err := d.skip(t); err != nil {
+				return "", err
+			}
+		case xml.EndElement:
+			if err := checkEnd(start, t); err != nil {
+				return "", err
+			}
+			if extra != nil {
+				return string(extra), nil
+			}
+			return s, nil
+		}
+	}
+}
+
+func (d *decoder) bool(start xml.StartElement) (bool, error) {
+	s, err := d.text(start)
+	if err != nil {
+		return false, err
+	}
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return false, nil
+	}
+	return strconv.ParseBool(s)
+}
+
+// strings decodes a list of strings wrapped in start, such as modules.
+func (d *decoder) strings(start xml.StartElement, name string, list **[]string) error {
+	return d.elements(start, func(child xml.StartElement) error {
+		if child.Name.Local != name {
+			return d.skip(child)
+		}
+		s, err := d.text(child)
+		if err != nil {
+			return err
+		}
+		if *list == nil {
+			*list = &[]string{}
+		}
+		**list = append(**list, s)
+		return nil
+	})
+}
+
+// decodeList decodes a list of elements called name wrapped in start,
+// decoding each of them with fn.
+func decodeList[T any](d *decoder, start xml.StartElement, name string, list **[]T, fn func(xml.StartElement, *T) error) error {
+	return d.elements(start, func(child xml.StartElement) error {
+		if child.Name.Local != name {
+			return d.skip(child)
+		}
+		if *list == nil {
+			*list = &[]T{}
+		}
+		var zero T
+		**list = append(**list, zero)
+		return fn(child, &(**list)[len(**list)-1])
+	})
+}
+
+// decodeStruct decodes start into the struct pointed to by *v, allocating it
+// if needed.
+func decodeStruct[T any](start xml.StartElement, v **T, fn func(xml.StartElement, *T) error) error {
+	if *v == nil {
+		*v = new(T)
+	}
+	return fn(start, *v)
+}
+
+func (d *decoder) project(start xml.StartElement, p *Project, sections Section) error {
+	return d.elements(start, func(child xml.StartElement) (err error) {
+		if sections&projectSections[child.Name.Local] == 0 {
+			return d.skip(child)
+		}
+		switch child.Name.Local {
+		case "modelVersion":
+			p.ModelVersion, err = d.text(child)
+		case "groupId":
+			p.GroupID, err = d.text(child)
+		case "artifactId":
+			p.ArtifactID, err = d.text(child)
+		case "version":
+			p.Version, err = d.text(child)
+		case "packaging":
+			p.Packaging, err = d.text(child)
+		case "name":
+			p.Name, err = d.text(child)
+		case "description":
+			p.Description, err = d.text(child)
+		case "url":
+			p.URL, err = d.text(child)
+		case "inceptionYear":
+			p.InceptionYear, err = d.text(child)
+		case "organization":
+			err = decodeStruct(child, &p.Organization, d.organization)
+		case "licenses":
+			err = decodeList(d, child, "license", &p.Licenses, d.license)
+		case "developers":
+			err = decodeList(d, child, "developer", &p.Developers, d.developer)
+		case "contributors":
+			err = decodeList(d, child, "contributor", &p.Contributors, d.contributor)
+		case "mailingLists":
+			err = decodeList(d, child, "mailingList", &p.MailingLists, d.mailingList)
+		case "prerequisites":
+			err = decodeStruct(child, &p.Prerequisites, d.prerequisites)
+		case "properties":
+			err = decodeStruct(child, &p.Properties, d.properties)
+		case "parent":
+			err = decodeStruct(child, &p.Parent, d.parent)
+		case "modules":
+			err = d.strings(child, "module", &p.Modules)
+		case "scm":
+			err = decodeStruct(child, &p.SCM, d.scm)
+		case "issueManagement":
+			err = decodeStruct(child, &p.IssueManagement, d.issueManagement)
+		case "ciManagement":
+			err = decodeStruct(child, &p.CIManagement, d.ciManagement)
+		case "distributionManagement":
+			err = decodeStruct(child, &p.DistributionManagement, d.distributionManagement)
+		case "dependencyManagement":
+			err = decodeStruct(child, &p.DependencyManagement, d.dependencyManagement)
+		case "dependencies":
+			err = decodeList(d, child, "dependency", &p.Dependencies, d.dependency)
+		case "repositories":
+			err = decodeList(d, child, "repository", &p.Repositories, d.repository)
+		case "pluginRepositories":
+			err = decodeList(d, child, "pluginRepository", &p.PluginRepositories, d.pluginRepository)
+		case "build":
+			err = decodeStruct(child, &p.Build, d.build)
+		case "reporting":
+			err = decodeStruct(child, &p.Reporting, d.reporting)
+		case "profiles":
+			err = decodeList(d, child, "profile", &p.Profiles, d.profile)
+		default:
+			err = d.skip(child)
+		}
+		return err
+	})
+}
+
+func (d *decoder) properties(start xml.StartElement, p *Properties) error {
+	if p.Entries == nil {
+		p.Entries = map[string]string{}
+	}
+	return d.elements(start, func(child xml.StartElement) error {
+		value, err := d.text(child)
+		if err != nil {
+			return err
+		}
+		key := child.Name.Local
+		p.Entries[key] = value
+		p.Order = append(p.Order, key)
+		return nil
+	})
+}
+
+func (d *decoder) parent(start xml.StartElement, p *Parent) error {
+	return d.elements(start, func(child xml.StartElement) (err error) {
+		switch child.Name.Local {
+		case "groupId":
+			p.GroupID, err = d.text(child)
+		case "artifactId":
+			p.ArtifactID, err = d.text(child)
+		case "version":
+			p.Version, err = d.text(child)
+		case "relativePath":
+			p.RelativePath, err = d.text(child)
+		default:
+			err = d.skip(child)
+		}
+		return err
+	})
+}
+
+func (d *decoder) organization(start xml.StartElement, o *Organization) error {
+	return d.elements(start, func(child xml.StartElement) (err error) {
+		switch child.Name.Local {
+		case "name":
+			o.Name, err = d.text(child)
+		case "url":
+			o.URL, err = d.text(child)
+		default:
+			err = d.skip(child)
+		}
+		return err
+	})
+}
+
+func (d *decoder) license(start xml.StartElement, l *License) error {
+	return d.elements(start, func(child xml.StartElement) (err error) {
+		switch child.Name.Local {
+		case "name":
+			l.Name, err = d.text(child)
+		case "url":
+			l.URL, err = d.text(child)
+		case "distribution":
+			l.Distribution, err = d.text(child)
+		case "comments":
+			l.Comments, err = d.text(child)
+		default:
+			err = d.skip(child)
+		}
+		return err
+	})
+}
+
+func (d *decoder) developer(start xml.StartElement, dev *Developer) error {
+	return d.elements(start, func(child xml.StartElement) (err error) {
+		switch child.Name.Local {
+		case "id":
+			dev.ID, err = d.text(child)
+		case "name":
+			dev.Name, err = d.text(child)
+		case "email":
+			dev.Email, err = d.text(child)
+		case "url":
+			dev.URL, err = d.text(child)
+		case "organization":
+			dev.Organization, err = d.text(child)
+		case "organizationUrl":
+			dev.OrganizationURL, err = d.text(child)
+		case "roles":
+			err = d.strings(child, "role", &dev.Roles)
+		case "timezone":
+			dev.Timezone, err = d.text(child)
+		case "properties":
+			err = decodeStruct(child, &dev.Properties, d.properties)
+		default:
+			err = d.skip(child)
+		}
+		return err
+	})
+}
+
+func (d *decoder) contributor(start xml.StartElement, c *Contributor) error {
+	return d.elements(start, func(child xml.StartElement) (err error) {
+		switch child.Name.Local {
+		case "name":
+			c.Name, err = d.text(child)
+		case "email":
+			c.Email, err = d.text(child)
+		case "url":
+			c.URL, err = d.text(child)
+		case "organization":
+			c.Organization, err = d.text(child)
+		case "organizationUrl":
+			c.OrganizationURL, err = d.text(child)
+		case "roles":
+			err = d.strings(child, "role", &c.Roles)
+		case "timezone":
+			c.Timezone, err = d.text(child)
+		case "properties":
+			err = decodeStruct(child, &c.Properties, d.properties)
+		default:
+			err = d.skip(child)
+		}
+		return err
+	})
+}
+
+func (d *decoder) mailingList(start xml.StartElement, m *MailingList) error {
+	return d.elements(start, func(child xml.StartElement) (err error) {
+		switch child.Name.Local {
+		case "name":
+			m.Name, err = d.text(child)
+		case "subscribe":
+			m.Subscribe, err = d.text(child)
+		case "unsubscribe":
+			m.Unsubscribe, err = d.text(child)
+		case "post":
+			m.Post, err = d.text(child)
+		case "archive":
+			m.Archive, err = d.text(child)
+		case "otherArchives":
+			err = d.strings(child, "otherArchive", &m.OtherArchives)
+		default:
+			err = d.skip(child)
+		}
+		return err
+	})
+}
+
+func (d *decoder) prerequisites(start xml.StartElement, p *Prerequisites) error {
+	return d.elements(start, func(child xml.StartElement) (err error) {
+		switch child.Name.Local {
+		case "maven":
+			p.Maven, err = d.text(child)
+		default:
+			err = d.skip(child)
+		}
+		return err
+	})
+}
+
+func (d *decoder) scm(start xml.StartElement, s *Scm) error {
+	return d.elements(start, func(child xml.StartElement) (err error) {
+		switch child.Name.Local {
+		case "connection":
+			s.Connection, err = d.text(child)
+		case "developerConnection":
+			s.DeveloperConnection, err = d.text(child)
+		case "tag":
+			s.Tag, err = d.text(child)
+		case "url":
+			s.URL, err = d.text(child)
+		default:
+			err = d.skip(child)
+		}
+		return err
+	})
+}
+
+func (d *decoder) issueManagement(start xml.StartElement, i *IssueManagement) error {
+	return d.elements(start, func(child xml.StartElement) (err error) {
+		switch child.Name.Local {
+		case "system":
+			i.System, err = d.text(child)
+		case "url":
+			i.URL, err = d.text(child)
+		default:
+			err = d.skip(child)
+		}
+		return err
+	})
+}
+
+func (d *decoder) ciManagement(start xml.StartElement, c *CIManagement) error {
+	return d.elements(start, func(child xml.StartElement) (err error) {
+		switch child.Name.Local {
+		case "system":
+			c.System, err = d.text(child)
+		case "url":
+			c.URL, err = d.text(child)
+		case "notifiers":
+			err = decodeList(d, child, "notifier", &c.Notifiers, d.notifier)
+		default:
+			err = d.skip(child)
+		}
+		return err
+	})
+}
+
+func (d *decoder) notifier(start xml.StartElement, n *Notifier) error {
+	return d.elements(start, func(child xml.StartElement) (err error) {
+		switch child.Name.Local {
+		case "type":
+			n.Type, err = d.text(child)
+		case "sendOnError":
+			n.SendOnError, err = d.bool(child)
+		case "sendOnFailure":
+			n.SendOnFailure, err = d.bool(child)
+		case "sendOnSuccess":
+			n.SendOnSuccess, err = d.bool(child)
+		case "sendOnWarning":
+			n.SendOnWarning, err = d.bool(child)
+		case "address":
+			n.Address, err = d.text(child)
+		case "configuration":
+			err = decodeStruct(child, &n.Configuration, d.configuration)
+		default:
+			err = d.skip(child)
+		}
+		return err
+	})
+}
+
+func (d *decoder) distributionManagement(start xml.StartElement, dm *DistributionManagement) error {
+	return d.elements(start, func(child xml.StartElement) (err error) {
+		switch child.Name.Local {
+		case "repository":
+			err = decodeStruct(child, &dm.Repository, d.repository)
+		case "snapshotRepository":
+			err = decodeStruct(child, &dm.SnapshotRepository, d.repository)
+		case "site":
+			err = decodeStruct(child, &dm.Site, d.site)
+		case "downloadUrl":
+			dm.DownloadURL, err = d.text(child)
+		case "relocation":
+			err = decodeStruct(child, &dm.Relocation, d.relocation)
+		case "status":
+			dm.Status, err = d.text(child)
+		default:
+			err = d.skip(child)
+		}
+		return err
+	})
+}
+
+func (d *decoder) site(start xml.StartElement, s *Site) error {
+	return d.elements(start, func(child xml.StartElement) (err error) {
+		switch child.Name.Local {
+		case "id":
+			s.ID, err = d.text(child)
+		case "name":
+			s.Name, err = d.text(child)
+		case "url":
+			s.URL, err = d.text(child)
+		default:
+			err = d.skip(child)
+		}
+		return err
+	})
+}
+
+func (d *decoder) relocation(start xml.StartElement, r *Relocation) error {
+	return d.elements(start, func(child xml.StartElement) (err error) {
+		switch child.Name.Local {
+		case "groupId":
+			r.GroupID, err = d.text(child)
+		case "artifactId":
+			r.ArtifactID, err = d.text(child)
+		case "version":
+			r.Version, err = d.text(child)
+		case "message":
+			r.Message, err = d.text(child)
+		default:
+			err = d.skip(child)
+		}
+		return err
+	})
+}
+
+func (d *decoder) dependencyManagement(start xml.StartElement, dm *DependencyManagement) error {
+	return d.elements(start, func(child xml.StartElement) error {
+		if child.Name.Local != "dependencies" {
+			return d.skip(child)
+		}
+		return decodeList(d, child, "dependency", &dm.Dependencies, d.dependency)
+	})
+}
+
+func (d *decoder) dependency(start xml.StartElement, dep *Dependency) error {
+	return d.elements(start, func(child xml.StartElement) (err error) {
+		switch child.Name.Local {
+		case "groupId":
+			dep.GroupID, err = d.text(child)
+		case "artifactId":
+			dep.ArtifactID, err = d.text(child)
+		case "version":
+			dep.Version, err = d.text(child)
+		case "type":
+			dep.Type, err = d.text(child)
+		case "classifier":
+			dep.Classifier, err = d.text(child)
+		case "scope":
+			dep.Scope, err = d.text(child)
+		case "systemPath":
+			dep.SystemPath, err = d.text(child)
+		case "exclusions":
+			err = decodeList(d, child, "exclusion", &dep.Exclusions, d.exclusion)
+		case "optional":
+			dep.Optional, err = d.text(child)
+		default:
+			err = d.skip(child)
+		}
+		return err
+	})
+}
+
+func (d *decoder) exclusion(start xml.StartElement, e *Exclusion) error {
+	return d.elements(start, func(child xml.StartElement) (err error) {
+		switch child.Name.Local {
+		case "groupId":
+			e.GroupID, err = d.text(child)
+		case "artifactId":
+			e.ArtifactID, err = d.text(child)
+		default:
+			err = d.skip(child)
+		}
+		return err
+	})
+}
+
+func (d *decoder) repository(start xml.StartElement, r *Repository) error {
+	return d.elements(start, func(child xml.StartElement) (err error) {
+		switch child.Name.Local {
+		case "uniqueVersion":
+			r.UniqueVersion, err = d.bool(child)
+		case "releases":
+			err = decodeStruct(child, &r.Releases, d.repositoryPolicy)
+		case "snapshots":
+			err = decodeStruct(child, &r.Snapshots, d.repositoryPolicy)
+		case "id":
+			r.ID, err = d.text(child)
+		case "name":
+			r.Name, err = d.text(child)
+		case "url":
+			r.URL, err = d.text(child)
+		case "layout":
+			r.Layout, err = d.text(child)
+		default:
+			err = d.skip(child)
+		}
+		return err
+	})
+}
+
+func (d *decoder) repositoryPolicy(start xml.StartElement, r *RepositoryPolicy) error {
+	return d.elements(start, func(child xml.StartElement) (err error) {
+		switch child.Name.Local {
+		case "enabled":
+			r.Enabled, err = d.text(child)
+		case "updatePolicy":
+			r.UpdatePolicy, err = d.text(child)
+		case "checksumPolicy":
+			r.ChecksumPolicy, err = d.text(child)
+		default:
+			err = d.skip(child)
+		}
+		return err
+	})
+}
+
+func (d *decoder) pluginRepository(start xml.StartElement, r *PluginRepository) error {
+	return d.elements(start, func(child xml.StartElement) (err error) {
+		switch child.Name.Local {
+		case "releases":
+			err = decodeStruct(child, &r.Releases, d.repositoryPolicy)
+		case "snapshots":
+			err = decodeStruct(child, &r.Snapshots, d.repositoryPolicy)
+		case "id":
+			r.ID, err = d.text(child)
+		case "name":
+			r.Name, err = d.text(child)
+		case "url":
+			r.URL, err = d.text(child)
+		case "layout":
+			r.Layout, err = d.text(child)
+		default:
+			err = d.skip(child)
+		}
+		return err
+	})
+}
+
+func (d *decoder) build(start xml.StartElement, b *Build) error {
+	return d.elements(start, func(child xml.StartElement) (err error) {
+		switch child.Name.Local {
+		case "sourceDirectory":
+			b.SourceDirectory, err = d.text(child)
+		case "scriptSourceDirectory":
+			b.ScriptSourceDirectory, err = d.text(child)
+		case "testSourceDirectory":
+			b.TestSourceDirectory, err = d.text(child)
+		case "outputDirectory":
+			b.OutputDirectory, err = d.text(child)
+		case "testOutputDirectory":
+			b.TestOutputDirectory, err = d.text(child)
+		case "extensions":
+			err = decodeList(d, child, "extension", &b.Extensions, d.extension)
+		default:
+			err = d.buildBaseElement(child, &b.BuildBase)
+		}
+		return err
+	})
+}
+
+func (d *decoder) buildBase(start xml.StartElement, b *BuildBase) error {
+	return d.elements(start, func(child xml.StartElement) error {
+		return d.buildBaseElement(child, b)
+	})
+}
+
+func (d *decoder) buildBaseElement(child xml.StartElement, b *BuildBase) (err error) {
+	switch child.Name.Local {
+	case "defaultGoal":
+		b.DefaultGoal, err = d.text(child)
+	case "resources":
+		err = decodeList(d, child, "resource", &b.Resources, d.resource)
+	case "testResources":
+		err = decodeList(d, child, "testResource", &b.TestResources, d.resource)
+	case "directory":
+		b.Directory, err = d.text(child)
+	case "finalName":
+		b.FinalName, err = d.text(child)
+	case "filters":
+		err = d.strings(child, "filter", &b.Filters)
+	case "pluginManagement":
+		err = decodeStruct(child, &b.PluginManagement, d.pluginManagement)
+	case "plugins":
+		err = decodeList(d, child, "plugin", &b.Plugins, d.plugin)
+	default:
+		err = d.skip(child)
+	}
+	return err
+}
+
+func (d *decoder) extension(start xml.StartElement, e *Extension) error {
+	return d.elements(start, func(child xml.StartElement) (err error) {
+		switch child.Name.Local {
+		case "groupId":
+			e.GroupID, err = d.text(child)
+		case "artifactId":
+			e.ArtifactID, err = d.text(child)
+		case "version":
+			e.Version, err = d.text(child)
+		default:
+			err = d.skip(child)
+		}
+		return err
+	})
+}
+
+func (d *decoder) resource(start xml.StartElement, r *Resource) error {
+	return d.elements(start, func(child xml.StartElement) (err error) {
+		switch child.Name.Local {
+		case "targetPath":
+			r.TargetPath, err = d.text(child)
+		case "filtering":
+			r.Filtering, err = d.text(child)
+		case "directory":
+			r.Directory, err = d.text(child)
+		case "includes":
+			err = d.strings(child, "include", &r.Includes)
+		case "excludes":
+			err = d.strings(child, "exclude", &r.Excludes)
+		default:
+			err = d.skip(child)
+		}
+		return err
+	})
+}
+
+func (d *decoder) pluginManagement(start xml.StartElement, pm *PluginManagement) error {
+	return d.elements(start, func(child xml.StartElement) error {
+		if child.Name.Local != "plugins" {
+			return d.skip(child)
+		}
+		return decodeList(d, child, "plugin", &pm.Plugins, d.plugin)
+	})
+}
+
+// configuration keeps the raw XML between the start and end tags, like the
+// innerxml field of Configuration does with xml.Unmarshal.
+func (d *decoder) configuration(start xml.StartElement, c *Configuration) error {
+	for _, attr := range start.Attr {
+		switch attr.Name.Local {
+		case "combine.children":
+			c.Children = attr.Value
+		case "combine.self":
+			c.Self = attr.Value
+		}
+	}
+	begin := d.offset()
+	open := []xml.Name{start.Name}
+	for {
+		end := d.offset()
+		tok, err := d.token()
+		if err != nil {
+			return err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			open = append(open, t.Name)
+		case xml.EndElement:
+			if err := checkEnd(xml.StartElement{Name: open[len(open)-1]}, t); err != nil {
+				return err
+			}
+			open = open[:len(open)-1]
+			if len(open) == 0 {
+				c.RawConfiguration = string(d.b[begin:end])
+				return nil
+			}
+		}
+	}
+}
+
+func (d *decoder) plugin(start xml.StartElement, p *Plugin) error {
+	return d.elements(start, func(child xml.StartElement) (err error) {
+		switch child.Name.Local {
+		case "groupId":
+			p.GroupID, err = d.text(child)
+		case "artifactId":
+			p.ArtifactID, err = d.text(child)
+		case "version":
+			p.Version, err = d.text(child)
+		case "extensions":
+			p.Extensions, err = d.text(child)
+		case "executions":
+			err = decodeList(d, child, "execution", &p.Executions, d.pluginExecution)
+		case "dependencies":
+			err = decodeList(d, child, "dependency", &p.Dependencies, d.dependency)
+		case "inherited":
+			p.Inherited, err = d.text(child)
+		case "configuration":
+			err = decodeStruct(child, &p.Configuration, d.configuration)
+		default:
+			err = d.skip(child)
+		}
+		return err
+	})
+}
+
+func (d *decoder) pluginExecution(start xml.StartElement, e *PluginExecution) error {
+	return d.elements(start, func(child xml.StartElement) (err error) {
+		switch child.Name.Local {
+		case "id":
+			e.ID, err = d.text(child)
+		case "phase":
+			e.Phase, err = d.text(child)
+		case "goals":
+			err = d.strings(child, "goal", &e.Goals)
+		case "inherited":
+			e.Inherited, err = d.text(child)
+		case "configuration":
+			err = decodeStruct(child, &e.Configuration, d.configuration)
+		default:
+			err = d.skip(child)
+		}
+		return err
+	})
+}
+
+func (d *decoder) reporting(start xml.StartElement, r *Reporting) error {
+	return d.elements(start, func(child xml.StartElement) (err error) {
+		switch child.Name.Local {
+		case "excludeDefaults":
+			r.ExcludeDefaults, err = d.text(child)
+		case "outputDirectory":
+			r.OutputDirectory, err = d.text(child)
+		case "plugins":
+			err = decodeList(d, child, "plugin", &r.Plugins, d.reportingPlugin)
+		default:
+			err = d.skip(child)
+		}
+		return err
+	})
+}
+
+func (d *decoder) reportingPlugin(start xml.StartElement, p *ReportingPlugin) error {
+	return d.elements(start, func(child xml.StartElement) (err error) {
+		switch child.Name.Local {
+		case "groupId":
+			p.GroupID, err = d.text(child)
+		case "artifactId":
+			p.ArtifactID, err = d.text(child)
+		case "version":
+			p.Version, err = d.text(child)
+		case "inherited":
+			p.Inherited, err = d.text(child)
+		case "reportSets":
+			err = decodeList(d, child, "reportSet", &p.ReportSets, d.reportSet)
+		default:
+			err = d.skip(child)
+		}
+		return err
+	})
+}
+
+func (d *decoder) reportSet(start xml.StartElement, r *ReportSet) error {
+	return d.elements(start, func(child xml.StartElement) (err error) {
+		switch child.Name.Local {
+		case "id":
+			r.ID, err = d.text(child)
+		case "reports":
+			err = d.strings(child, "report", &r.Reports)
+		case "inherited":
+			r.Inherited, err = d.text(child)
+		default:
+			err = d.skip(child)
+		}
+		return err
+	})
+}
+
+func (d *decoder) profile(start xml.StartElement, p *Profile) error {
+	return d.elements(start, func(child xml.StartElement) (err error) {
+		switch child.Name.Local {
+		case "id":
+			p.ID, err = d.text(child)
+		case "activation":
+			err = decodeStruct(child, &p.Activation, d.activation)
+		case "build":
+			err = decodeStruct(child, &p.Build, d.buildBase)
+		case "modules":
+			err = d.strings(child, "module", &p.Modules)
+		case "distributionManagement":
+			err = decodeStruct(child, &p.DistributionManagement, d.distributionManagement)
+		case "properties":
+			err = decodeStruct(child, &p.Properties, d.properties)
+		case "dependencyManagement":
+			err = decodeStruct(child, &p.DependencyManagement, d.dependencyManagement)
+		case "dependencies":
+			err = decodeList(d, child, "dependency", &p.Dependencies, d.dependency)
+		case "repositories":
+			err = decodeList(d, child, "repository", &p.Repositories, d.repository)
+		case "pluginRepositories":
+			err = decodeList(d, child, "pluginRepository", &p.PluginRepositories, d.pluginRepository)
+		case "reporting":
+			err = decodeStruct(child, &p.Reporting, d.reporting)
+		default:
+			err = d.skip(child)
+		}
+		return err
+	})
+}
+
+func (d *decoder) activation(start xml.StartElement, a *Activation) error {
+	return d.elements(start, func(child xml.StartElement) (err error) {
+		switch child.Name.Local {
+		case "activeByDefault":
+			a.ActiveByDefault, err = d.bool(child)
+		case "jdk":
+			a.JDK, err = d.text(child)
+		case "os":
+			err = decodeStruct(child, &a.OS, d.activationOS)
+		case "property":
+			err = decodeStruct(child, &a.Property, d.activationProperty)
+		case "file":
+			err = decodeStruct(child, &a.File, d.activationFile)
+		default:
+			err = d.skip(child)
+		}
+		return err
+	})
+}
+
+func (d *decoder) activationOS(start xml.StartElement, o *ActivationOS) error {
+	return d.elements(start, func(child xml.StartElement) (err error) {
+		switch child.Name.Local {
+		case "name":
+			o.Name, err = d.text(child)
+		case "family":
+			o.Family, err = d.text(child)
+		case "arch":
+			o.Arch, err = d.text(child)
+		case "version":
+			o.Version, err = d.text(child)
+		default:
+			err = d.skip(child)
+		}
+		return err
+	})
+}
+
+func (d *decoder) activationProperty(start xml.StartElement, p *ActivationProperty) error {
+	return d.elements(start, func(child xml.StartElement) (err error) {
+		switch child.Name.Local {
+		case "name":
+			p.Name, err = d.text(child)
+		case "value":
+			p.Value, err = d.text(child)
+		default:
+			err = d.skip(child)
+		}
+		return err
+	})
+}
+
+func (d *decoder) activationFile(start xml.StartElement, f *ActivationFile) error {
+	return d.elements(start, func(child xml.StartElement) (err error) {
+		switch child.Name.Local {
+		case "missing":
+			f.Missing, err = d.text(child)
+		case "exists":
+			f.Exists, err = d.text(child)
+		default:
+			err = d.skip(child)
+		}
+		return err
+	})
+}
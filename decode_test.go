@@ -0,0 +1,150 @@
+package gopom
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func unmarshalFile(tb testing.TB, path string) ([]byte, *Project) {
+	tb.Helper()
+	b, err := os.ReadFile(path)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	var p Project
+	if err := xml.Unmarshal(b, &p); err != nil {
+		tb.Fatal(err)
+	}
+	return b, &p
+}
+
+func TestDecode(t *testing.T) {
+	for _, path := range []string{filename, commentsFilename, "./testdata/reactor/pom.xml"} {
+		t.Run(path, func(t *testing.T) {
+			b, want := unmarshalFile(t, path)
+			got, err := Decode(b, SectionAll)
+			if err != nil {
+				t.Fatalf("failed decoding: %v", err)
+			}
+			assert.Equal(t, want, got)
+		})
+	}
+}
+
+func TestDecodeSections(t *testing.T) {
+	p, err := ParseSections(filename, SectionCoordinates|SectionDependencies)
+	if err != nil {
+		t.Fatalf("failed decoding: %v", err)
+	}
+	testParent(t, p)
+	testDependencies(t, p)
+	assert.Equal(t, "test-application", p.ArtifactID)
+	assert.Equal(t, "war", p.Packaging)
+	assert.Empty(t, p.Name)
+	assert.Nil(t, p.Properties)
+	assert.Nil(t, p.DependencyManagement)
+	assert.Nil(t, p.Build)
+	assert.Nil(t, p.Profiles)
+	assert.Nil(t, p.Modules)
+}
+
+func TestDecodeErrors(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		pom  string
+		// malformed documents fail even when the broken part is skipped.
+		malformed bool
+	}{
+		{"wrong root", `<settings></settings>`, true},
+		{"mismatched", `<project><groupId>g</artifactId></project>`, true},
+		{"unterminated", `<project><dependencies><dependency>`, true},
+		{"skipped", `<project><build></plugins></project>`, true},
+		{"bad bool", `<project><repositories><repository><uniqueVersion>maybe</uniqueVersion></repository></repositories></project>`, false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Decode([]byte(tt.pom), SectionAll)
+			assert.Error(t, err)
+			_, err = Decode([]byte(tt.pom), SectionCoordinates)
+			assert.Equal(t, tt.malformed, err != nil)
+		})
+	}
+}
+
+// largePOM returns a POM in the style of a platform BOM, with n managed
+// dependencies and a version property for each of them.
+func largePOM(tb testing.TB, n int) []byte {
+	tb.Helper()
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	var props, deps strings.Builder
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&props, "    <lib%d.version>1.%d.0</lib%[1]d.version>\n", i, i)
+		fmt.Fprintf(&deps, `      <dependency>
+        <groupId>com.example.lib%d</groupId>
+        <artifactId>lib%[1]d-core</artifactId>
+        <version>${lib%[1]d.version}</version>
+      </dependency>
+`, i)
+	}
+	pom := string(b)
+	pom = strings.Replace(pom, "<properties>\n", "<properties>\n"+props.String(), 1)
+	pom = strings.Replace(pom, "<dependencyManagement>\n    <dependencies>\n", "<dependencyManagement>\n    <dependencies>\n"+deps.String(), 1)
+	return []byte(pom)
+}
+
+func TestDecodeLargePOM(t *testing.T) {
+	b := largePOM(t, 100)
+	var want Project
+	if err := xml.Unmarshal(b, &want); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 101, len(*want.DependencyManagement.Dependencies))
+	got, err := Decode(b, SectionAll)
+	if err != nil {
+		t.Fatalf("failed decoding: %v", err)
+	}
+	assert.Equal(t, &want, got)
+}
+
+func benchmarkDecoders(b *testing.B, pom []byte) {
+	b.Run("Unmarshal", func(b *testing.B) {
+		b.SetBytes(int64(len(pom)))
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			var p Project
+			if err := xml.Unmarshal(pom, &p); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	for name, sections := range map[string]Section{
+		"All":          SectionAll,
+		"Dependencies": SectionCoordinates | SectionDependencies,
+	} {
+		b.Run("Decode"+name, func(b *testing.B) {
+			b.SetBytes(int64(len(pom)))
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := Decode(pom, sections); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkDecodeExample(b *testing.B) {
+	pom, _ := unmarshalFile(b, filename)
+	benchmarkDecoders(b, pom)
+}
+
+func BenchmarkDecodeLarge(b *testing.B) {
+	benchmarkDecoders(b, largePOM(b, 1000))
+}
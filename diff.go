@@ -0,0 +1,401 @@
+package gopom
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+// ChangeKind tells how an element changed between two projects.
+type ChangeKind string
+
+const (
+	ChangeAdded      ChangeKind = "added"
+	ChangeRemoved    ChangeKind = "removed"
+	ChangeUpgraded   ChangeKind = "upgraded"
+	ChangeDowngraded ChangeKind = "downgraded"
+	ChangeModified   ChangeKind = "changed"
+)
+
+// Change is a semantic difference between two projects.
+type Change struct {
+	Kind ChangeKind `json:"kind" yaml:"kind"`
+	// Element is the kind of element that changed: project, parent,
+	// property, module, dependency, managed dependency, plugin, managed
+	// plugin, extension or profile.
+	Element string `json:"element" yaml:"element"`
+	// Key identifies the element: the coordinates without version of
+	// dependencies, plugins and extensions, the name of properties, the
+	// path of modules and the id of profiles.
+	Key string `json:"key,omitempty" yaml:"key,omitempty"`
+	// Field is the changed field of the element, like version or scope, for
+	// upgrades, downgrades and other changes.
+	Field string `json:"field,omitempty" yaml:"field,omitempty"`
+	// Old and New are the values before and after the change. For added
+	// and removed elements, they hold their version or value, if any.
+	Old string `json:"old,omitempty" yaml:"old,omitempty"`
+	New string `json:"new,omitempty" yaml:"new,omitempty"`
+	// Profile is the id of the profile the element belongs to, if any.
+	Profile string `json:"profile,omitempty" yaml:"profile,omitempty"`
+}
+
+func (c Change) String() string {
+	var b strings.Builder
+	if c.Profile != "" {
+		b.WriteString("profile " + c.Profile + ": ")
+	}
+	b.WriteString(c.Element)
+	if c.Key != "" {
+		b.WriteString(" " + c.Key)
+	}
+	b.WriteString(": ")
+	if c.Field != "" {
+		b.WriteString(c.Field + " ")
+	}
+	b.WriteString(string(c.Kind))
+	value := func(s string) string {
+		if s == "" {
+			return "(none)"
+		}
+		return s
+	}
+	switch c.Kind {
+	case ChangeAdded:
+		if c.New != "" {
+			b.WriteString(" " + c.New)
+		}
+	case ChangeRemoved:
+		if c.Old != "" {
+			b.WriteString(" " + c.Old)
+		}
+	default:
+		b.WriteString(" from " + value(c.Old) + " to " + value(c.New))
+	}
+	return b.String()
+}
+
+// Diff returns the semantic differences between the projects a and b: the
+// changes of their coordinates, parent, properties, modules, dependencies,
+// managed dependencies, plugins, managed plugins, build extensions and
+// profiles. Version changes are upgrades or downgrades according to
+// CompareVersions, unless a version is missing or uses a property.
+//
+// Formatting, comments and the order of elements do not matter, and
+// neither do defaults: a dependency without scope is the same as one in
+// compile scope. Diff compares the POMs as written; compare effective
+// models, see Effective, to see what changes for the build.
+func Diff(a, b *Project) []Change {
+	d := &differ{}
+	d.value("project", "", "groupId", a.GroupID, b.GroupID)
+	d.value("project", "", "artifactId", a.ArtifactID, b.ArtifactID)
+	d.version("project", "", a.Version, b.Version)
+	d.value("project", "", "packaging", a.Packaging, b.Packaging)
+	d.value("project", "", "name", a.Name, b.Name)
+	d.value("project", "", "description", a.Description, b.Description)
+	d.value("project", "", "url", a.URL, b.URL)
+	d.parent(a.Parent, b.Parent)
+	d.properties(a.Properties, b.Properties)
+	d.modules(a.Modules, b.Modules)
+	d.dependencies("managed dependency", managedDependencies(a.DependencyManagement), managedDependencies(b.DependencyManagement))
+	d.dependencies("dependency", a.Dependencies, b.Dependencies)
+	var buildA, buildB *BuildBase
+	var extensionsA, extensionsB *[]Extension
+	if a.Build != nil {
+		buildA, extensionsA = &a.Build.BuildBase, a.Build.Extensions
+	}
+	if b.Build != nil {
+		buildB, extensionsB = &b.Build.BuildBase, b.Build.Extensions
+	}
+	d.build(buildA, buildB)
+	diffList(d, "extension", extensionsA, extensionsB, func(e Extension) string {
+		return e.GroupID + ":" + e.ArtifactID
+	}, func(e Extension) string { return e.Version }, func(key string, a, b Extension) {
+		d.version("extension", key, a.Version, b.Version)
+	})
+	d.profiles(a.Profiles, b.Profiles)
+	return d.changes
+}
+
+type differ struct {
+	changes []Change
+	// profile is the id of the profile being compared, if any.
+	profile string
+}
+
+func (d *differ) add(c Change) {
+	c.Profile = d.profile
+	d.changes = append(d.changes, c)
+}
+
+// value records the change of a field, if any.
+func (d *differ) value(element, key, field, from, to string) {
+	if from != to {
+		d.add(Change{Kind: ChangeModified, Element: element, Key: key, Field: field, Old: from, New: to})
+	}
+}
+
+// version records the change of a version, if any, as an upgrade or a
+// downgrade when both versions are known.
+func (d *differ) version(element, key, from, to string) {
+	if from == to {
+		return
+	}
+	kind := ChangeModified
+	if from != "" && to != "" && !strings.Contains(from, "${") && !strings.Contains(to, "${") {
+		switch CompareVersions(from, to) {
+		case -1:
+			kind = ChangeUpgraded
+		case 1:
+			kind = ChangeDowngraded
+		}
+	}
+	d.add(Change{Kind: kind, Element: element, Key: key, Field: "version", Old: from, New: to})
+}
+
+// diffList compares lists of elements identified by key: removed and
+// changed elements are reported in the order of a, then added ones in the
+// order of b. value returns what is recorded for added and removed
+// elements.
+func diffList[T any](d *differ, element string, a, b *[]T, key func(T) string, value func(T) string, compare func(key string, a, b T)) {
+	index := func(list *[]T) (map[string]T, []string) {
+		m := map[string]T{}
+		var keys []string
+		if list != nil {
+			for _, v := range *list {
+				k := key(v)
+				if _, ok := m[k]; !ok {
+					keys = append(keys, k)
+				}
+				m[k] = v
+			}
+		}
+		return m, keys
+	}
+	mapA, keysA := index(a)
+	mapB, keysB := index(b)
+	for _, k := range keysA {
+		if vb, ok := mapB[k]; ok {
+			compare(k, mapA[k], vb)
+		} else {
+			d.add(Change{Kind: ChangeRemoved, Element: element, Key: k, Old: value(mapA[k])})
+		}
+	}
+	for _, k := range keysB {
+		if _, ok := mapA[k]; !ok {
+			d.add(Change{Kind: ChangeAdded, Element: element, Key: k, New: value(mapB[k])})
+		}
+	}
+}
+
+func (d *differ) parent(a, b *Parent) {
+	switch {
+	case a == nil && b == nil:
+		return
+	case a != nil && b != nil && a.GroupID == b.GroupID && a.ArtifactID == b.ArtifactID:
+		key := a.GroupID + ":" + a.ArtifactID
+		d.version("parent", key, a.Version, b.Version)
+		d.value("parent", key, "relativePath", a.RelativePath, b.RelativePath)
+		return
+	}
+	if a != nil {
+		d.add(Change{Kind: ChangeRemoved, Element: "parent", Key: a.GroupID + ":" + a.ArtifactID, Old: a.Version})
+	}
+	if b != nil {
+		d.add(Change{Kind: ChangeAdded, Element: "parent", Key: b.GroupID + ":" + b.ArtifactID, New: b.Version})
+	}
+}
+
+func (d *differ) properties(a, b *Properties) {
+	var entriesA, entriesB map[string]string
+	if a != nil {
+		entriesA = a.Entries
+	}
+	if b != nil {
+		entriesB = b.Entries
+	}
+	var names []string
+	for name := range entriesA {
+		names = append(names, name)
+	}
+	for name := range entriesB {
+		if _, ok := entriesA[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		from, inA := entriesA[name]
+		to, inB := entriesB[name]
+		switch {
+		case !inB:
+			d.add(Change{Kind: ChangeRemoved, Element: "property", Key: name, Old: from})
+		case !inA:
+			d.add(Change{Kind: ChangeAdded, Element: "property", Key: name, New: to})
+		case strings.TrimSpace(from) != strings.TrimSpace(to):
+			d.add(Change{Kind: ChangeModified, Element: "property", Key: name, Field: "value", Old: from, New: to})
+		}
+	}
+}
+
+func (d *differ) modules(a, b *[]string) {
+	diffList(d, "module", a, b, func(m string) string { return m }, func(string) string { return "" }, func(string, string, string) {})
+}
+
+func managedDependencies(dm *DependencyManagement) *[]Dependency {
+	if dm == nil {
+		return nil
+	}
+	return dm.Dependencies
+}
+
+func (d *differ) dependencies(element string, a, b *[]Dependency) {
+	key := func(dep Dependency) string {
+		c := dep.Coordinate()
+		c.Version = ""
+		return c.String()
+	}
+	version := func(dep Dependency) string { return dep.Version }
+	diffList(d, element, a, b, key, version, func(key string, a, b Dependency) {
+		d.version(element, key, a.Version, b.Version)
+		scopeA, scopeB := a.Scope, b.Scope
+		optionalA, optionalB := strings.TrimSpace(a.Optional), strings.TrimSpace(b.Optional)
+		if element == "dependency" {
+			// Unlike managed dependencies, which only set what they
+			// declare, dependencies default to compile and not optional.
+			scopeA, scopeB = orDefault(scopeA, "compile"), orDefault(scopeB, "compile")
+			optionalA, optionalB = orDefault(optionalA, "false"), orDefault(optionalB, "false")
+		}
+		d.value(element, key, "scope", scopeA, scopeB)
+		d.value(element, key, "optional", optionalA, optionalB)
+		d.value(element, key, "systemPath", a.SystemPath, b.SystemPath)
+		d.value(element, key, "exclusions", exclusionsString(a.Exclusions), exclusionsString(b.Exclusions))
+	})
+}
+
+func orDefault(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
+
+func exclusionsString(exclusions *[]Exclusion) string {
+	var list []string
+	for _, e := range derefExclusions(exclusions) {
+		list = append(list, e.GroupID+":"+e.ArtifactID)
+	}
+	sort.Strings(list)
+	return strings.Join(list, ", ")
+}
+
+func (d *differ) build(a, b *BuildBase) {
+	var pluginsA, pluginsB, managedA, managedB *[]Plugin
+	if a != nil {
+		pluginsA = a.Plugins
+		if a.PluginManagement != nil {
+			managedA = a.PluginManagement.Plugins
+		}
+	}
+	if b != nil {
+		pluginsB = b.Plugins
+		if b.PluginManagement != nil {
+			managedB = b.PluginManagement.Plugins
+		}
+	}
+	d.plugins("managed plugin", managedA, managedB)
+	d.plugins("plugin", pluginsA, pluginsB)
+}
+
+func (d *differ) plugins(element string, a, b *[]Plugin) {
+	key := func(p Plugin) string {
+		c := p.Coordinate()
+		return c.GroupID + ":" + c.ArtifactID
+	}
+	version := func(p Plugin) string { return p.Version }
+	diffList(d, element, a, b, key, version, func(key string, a, b Plugin) {
+		d.version(element, key, a.Version, b.Version)
+		d.value(element, key, "extensions", a.Extensions, b.Extensions)
+		d.value(element, key, "inherited", a.Inherited, b.Inherited)
+		d.value(element, key, "configuration", configurationString(a.Configuration), configurationString(b.Configuration))
+		d.value(element, key, "executions", executionsString(a.Executions), executionsString(b.Executions))
+		var depsA, depsB []string
+		for _, dep := range derefDependencies(a.Dependencies) {
+			depsA = append(depsA, dep.Coordinate().String())
+		}
+		for _, dep := range derefDependencies(b.Dependencies) {
+			depsB = append(depsB, dep.Coordinate().String())
+		}
+		d.value(element, key, "dependencies", strings.Join(depsA, ", "), strings.Join(depsB, ", "))
+	})
+}
+
+func derefDependencies(deps *[]Dependency) []Dependency {
+	if deps == nil {
+		return nil
+	}
+	return *deps
+}
+
+// configurationString returns the configuration with its whitespace
+// collapsed, so that indentation changes do not count.
+func configurationString(c *Configuration) string {
+	if c == nil {
+		return ""
+	}
+	s := strings.Join(strings.Fields(c.RawConfiguration), " ")
+	s = strings.ReplaceAll(strings.ReplaceAll(s, "> <", "><"), "> ", ">")
+	s = strings.ReplaceAll(s, " <", "<")
+	if c.Children != "" {
+		s = "combine.children=" + c.Children + " " + s
+	}
+	if c.Self != "" {
+		s = "combine.self=" + c.Self + " " + s
+	}
+	return s
+}
+
+// executionsString summarizes executions as id@phase:goal,goal lists.
+func executionsString(executions *[]PluginExecution) string {
+	if executions == nil {
+		return ""
+	}
+	var list []string
+	for _, e := range *executions {
+		s := e.ID
+		if s == "" {
+			s = "default"
+		}
+		if e.Phase != "" {
+			s += "@" + e.Phase
+		}
+		if e.Goals != nil {
+			s += ":" + strings.Join(*e.Goals, ",")
+		}
+		if c := configurationString(e.Configuration); c != "" {
+			s += " " + c
+		}
+		list = append(list, s)
+	}
+	return strings.Join(list, "; ")
+}
+
+func (d *differ) profiles(a, b *[]Profile) {
+	diffList(d, "profile", a, b, func(p Profile) string { return p.ID }, func(Profile) string { return "" }, func(id string, a, b Profile) {
+		d.value("profile", id, "activation", activationString(a.Activation), activationString(b.Activation))
+		d.profile = id
+		d.properties(a.Properties, b.Properties)
+		d.modules(a.Modules, b.Modules)
+		d.dependencies("managed dependency", managedDependencies(a.DependencyManagement), managedDependencies(b.DependencyManagement))
+		d.dependencies("dependency", a.Dependencies, b.Dependencies)
+		d.build(a.Build, b.Build)
+		d.profile = ""
+	})
+}
+
+func activationString(a *Activation) string {
+	if a == nil {
+		return ""
+	}
+	b, _ := json.Marshal(a)
+	return string(b)
+}
@@ -0,0 +1,73 @@
+package gopom
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiff(t *testing.T) {
+	a, err := Parse("testdata/diff/old.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := Parse("testdata/diff/new.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, c := range Diff(a, b) {
+		got = append(got, c.String())
+	}
+	assert.Equal(t, []string{
+		"project: version upgraded from 1.0-SNAPSHOT to 1.0",
+		"parent com.example:parent: version upgraded from 3 to 4",
+		"property jackson.version: value changed from 2.16.0 to 2.17.0",
+		"property java.version: value changed from 17 to 21",
+		"property surefire.forkCount: added 2",
+		"module legacy: removed",
+		"module api: added",
+		"dependency com.google.guava:guava: version upgraded from 32.0.0-jre to 33.0.0-jre",
+		"dependency commons-io:commons-io: version downgraded from 2.15.0 to 2.11.0",
+		"dependency commons-io:commons-io: scope changed from compile to runtime",
+		"dependency junit:junit: removed 4.13.2",
+		"dependency org.junit.jupiter:junit-jupiter: added 5.10.1",
+		"plugin org.apache.maven.plugins:maven-compiler-plugin: version upgraded from 3.11.0 to 3.12.1",
+		"plugin org.apache.maven.plugins:maven-compiler-plugin: configuration changed from <release>17</release> to <release>21</release>",
+		`profile release: activation changed from (none) to {"property":{"name":"performRelease"}}`,
+		"profile release: property gpg.skip: value changed from false to true",
+		"profile release: dependency com.example:signing: added 1.2",
+		"profile legacy: removed",
+	}, got)
+
+	assert.Empty(t, Diff(a, a))
+}
+
+func TestDiffReaders(t *testing.T) {
+	// Revisions of a POM, as given by git show, can be compared from
+	// readers.
+	b, err := os.ReadFile("testdata/diff/old.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, err := ParseReader(strings.NewReader(string(b)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	edited := strings.Replace(string(b), "<version>4.13.2</version>", "<version>4.12</version>", 1)
+	c, err := ParseReader(strings.NewReader(edited))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []Change{{
+		Kind:    ChangeDowngraded,
+		Element: "dependency",
+		Key:     "junit:junit",
+		Field:   "version",
+		Old:     "4.13.2",
+		New:     "4.12",
+	}}, Diff(a, c))
+}
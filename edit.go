@@ -0,0 +1,339 @@
+package gopom
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Edit sets the text of the elements selected by Query, see ParseQuery, to
+// Value.
+type Edit struct {
+	Query string
+	Value string
+}
+
+// ApplyEdits applies edits in turn to the POM in b. Only the edited
+// elements change: the rest of the document, formatting and comments
+// included, is kept as is.
+//
+// When a query selects nothing, the missing elements are created in the
+// elements matching the longest part of the query that selects something,
+// as long as the rest only names elements, like "scm.url" or
+// "properties.java.version". They are written after their preceding sibling
+// in model order, like with ApplyProject. New list items are not created:
+// selectors cannot be satisfied by creating elements.
+func ApplyEdits(b []byte, edits ...Edit) ([]byte, error) {
+	for _, e := range edits {
+		var err error
+		if b, err = applyEdit(b, e); err != nil {
+			return nil, fmt.Errorf("failed to set %s: %w", e.Query, err)
+		}
+	}
+	return b, nil
+}
+
+// EditFile applies edits to the POM file at path, see ApplyEdits. The file
+// is left untouched when an edit fails.
+func EditFile(path string, edits ...Edit) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	edited, err := ApplyEdits(b, edits...)
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, edited, info.Mode().Perm())
+}
+
+// ParseEdits reads edits, one per line, as a query followed by whitespace
+// and the value. Values are taken verbatim up to the end of the line,
+// unless they are double quoted Go strings. Empty lines and lines starting
+// with "#" are ignored.
+func ParseEdits(r io.Reader) ([]Edit, error) {
+	var edits []Edit
+	s := bufio.NewScanner(r)
+	for n := 1; s.Scan(); n++ {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		query, value, ok := splitEdit(line)
+		if !ok {
+			return nil, fmt.Errorf("line %d: missing value", n)
+		}
+		if strings.HasPrefix(value, `"`) {
+			unquoted, err := strconv.Unquote(value)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid value %s: %w", n, value, err)
+			}
+			value = unquoted
+		}
+		if _, err := ParseQuery(query); err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+		edits = append(edits, Edit{Query: query, Value: value})
+	}
+	return edits, s.Err()
+}
+
+// splitEdit splits line at the first whitespace that is not within
+// brackets or quotes.
+func splitEdit(line string) (string, string, bool) {
+	depth, quoted := 0, false
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; {
+		case quoted && c == '\\':
+			i++
+		case c == '"':
+			quoted = !quoted
+		case quoted:
+		case c == '[':
+			depth++
+		case c == ']':
+			depth--
+		case depth == 0 && (c == ' ' || c == '\t'):
+			return line[:i], strings.TrimSpace(line[i:]), true
+		}
+	}
+	return line, "", false
+}
+
+// replacement replaces b[start:end] with text.
+type replacement struct {
+	start, end int
+	text       string
+}
+
+func applyEdit(b []byte, e Edit) ([]byte, error) {
+	q, err := ParseQuery(e.Query)
+	if err != nil {
+		return nil, err
+	}
+	p, err := parse(b)
+	if err != nil {
+		return nil, err
+	}
+	value := escapeText(e.Value)
+
+	var replacements []replacement
+	results, err := q.Eval(p)
+	if err != nil {
+		return nil, err
+	}
+	if len(results) > 0 {
+		for _, r := range results {
+			if !isText(r) {
+				return nil, fmt.Errorf("%s is not a text element", r.Path)
+			}
+			s := p.source.Elements[r.Path]
+			if s.ContentStart == s.End {
+				// Self-closing element.
+				name := elementName(r.Path)
+				replacements = append(replacements, replacement{s.Start, s.End, "<" + name + ">" + value + "</" + name + ">"})
+				continue
+			}
+			replacements = append(replacements, replacement{s.ContentStart, s.ContentEnd, value})
+		}
+	} else {
+		if replacements, err = creations(b, p, q, value); err != nil {
+			return nil, err
+		}
+	}
+
+	return replace(b, replacements), nil
+}
+
+// replace applies the replacements, which must not overlap, to b.
+// Insertions come before the replacements starting at the same offset.
+func replace(b []byte, replacements []replacement) []byte {
+	sort.Slice(replacements, func(i, j int) bool {
+		ri, rj := replacements[i], replacements[j]
+		return ri.start < rj.start || (ri.start == rj.start && ri.end < rj.end)
+	})
+	// Replace from the end so the offsets stay valid.
+	var out []byte
+	end := len(b)
+	for i := len(replacements) - 1; i >= 0; i-- {
+		r := replacements[i]
+		out = append(append([]byte(r.text), b[r.end:end]...), out...)
+		end = r.start
+	}
+	return append(append([]byte(nil), b[:end]...), out...)
+}
+
+// isText reports whether r is an element that only holds text.
+func isText(r Result) bool {
+	switch v := r.Value.(type) {
+	case string, bool:
+		return true
+	case *ConfigElement:
+		return len(v.Children) == 0
+	}
+	return false
+}
+
+// creations returns the insertions creating the elements q selects, in the
+// elements selected by its longest prefix that selects something.
+func creations(b []byte, p *Project, q *Query, value string) ([]replacement, error) {
+	for k := len(q.steps) - 1; k >= 0; k-- {
+		parents, err := evalSteps([]node{{path: "project", v: reflect.ValueOf(p)}}, q.steps[:k])
+		if err != nil {
+			return nil, err
+		}
+		if len(parents) == 0 {
+			continue
+		}
+		var replacements []replacement
+		for _, parent := range parents {
+			names, err := missingElements(parent, q.steps[k:])
+			if err != nil {
+				return nil, err
+			}
+			replacements = append(replacements, insertion(b, p.source, parent.path, parent.v, names, value))
+		}
+		return replacements, nil
+	}
+	return nil, fmt.Errorf("no element matches")
+}
+
+// missingElements returns the names of the nested elements to create in
+// parent for steps.
+func missingElements(parent node, steps []step) ([]string, error) {
+	t := parent.v.Type()
+	var names []string
+	for i, s := range steps {
+		if len(s.selectors) > 0 || s.name == "*" {
+			return nil, fmt.Errorf("cannot create %s: only named elements can be created", s.name)
+		}
+		if t == configElementType {
+			names = append(names, s.name)
+			continue
+		}
+		for t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		switch t {
+		case reflect.TypeOf(Properties{}):
+			var key []string
+			for _, s := range steps[i:] {
+				key = append(key, s.name)
+			}
+			return append(names, strings.Join(key, ".")), nil
+		case reflect.TypeOf(Configuration{}):
+			names = append(names, s.name)
+			t = configElementType
+			continue
+		}
+
+		if t.Kind() != reflect.Struct {
+			return nil, fmt.Errorf("cannot create %s: %s has no child elements", s.name, strings.Join(names, "."))
+		}
+		var f *xmlField
+		for _, candidate := range xmlFields(reflect.New(t).Elem()) {
+			if candidate.name == s.name {
+				f = &candidate
+				break
+			}
+		}
+		if f == nil {
+			return nil, fmt.Errorf("cannot create %s: no such element", s.name)
+		}
+		if f.item != "" {
+			return nil, fmt.Errorf("cannot create %s: list items cannot be created", s.name)
+		}
+		names = append(names, s.name)
+		t = f.v.Type()
+	}
+	for t.Kind() == reflect.Pointer && t != configElementType {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.String && t.Kind() != reflect.Bool && t != configElementType {
+		return nil, fmt.Errorf("cannot set %s: not a text element", strings.Join(names, "."))
+	}
+	return names, nil
+}
+
+// insertion returns the replacement inserting the nested elements names,
+// holding value, in the element at path holding v, indented like the rest
+// of the document. The new element goes where ApplyProject puts new
+// elements, see patcher.insertAt.
+func insertion(b []byte, index *sourceIndex, path string, v reflect.Value, names []string, value string) replacement {
+	pt := &patcher{b: b, index: index, unit: indentUnit(b, index), nl: "\n"}
+	if bytes.Contains(b, []byte("\r\n")) {
+		pt.nl = "\r\n"
+	}
+
+	var text strings.Builder
+	indent := indentation(b, index.Elements[path].Start)
+	for _, name := range names {
+		indent += pt.unit
+		text.WriteString(pt.nl + indent + "<" + name + ">")
+	}
+	text.WriteString(value)
+	for i := len(names) - 1; i >= 0; i-- {
+		if i < len(names)-1 {
+			text.WriteString(pt.nl + indent)
+		}
+		text.WriteString("</" + names[i] + ">")
+		indent = strings.TrimSuffix(indent, pt.unit)
+	}
+
+	pt.insert(path, pt.insertAt(path, v, names[0]), text.String())
+	return pt.replacements[0]
+}
+
+// indentation returns the whitespace before offset on its line.
+func indentation(b []byte, offset int) string {
+	start := offset
+	for start > 0 && (b[start-1] == ' ' || b[start-1] == '\t') {
+		start--
+	}
+	return string(b[start:offset])
+}
+
+// indentUnit guesses the indentation step of the document from its first
+// indented element, defaulting to four spaces like Marshal.
+func indentUnit(b []byte, index *sourceIndex) string {
+	first := -1
+	for path, s := range index.Elements {
+		if strings.Count(path, "/") == 1 && (first < 0 || s.Start < first) {
+			first = s.Start
+		}
+	}
+	if first >= 0 {
+		if unit := indentation(b, first); unit != "" && first-len(unit) > 0 && b[first-len(unit)-1] == '\n' {
+			return unit
+		}
+	}
+	return "    "
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+// elementName returns the name of the last element of an element path.
+func elementName(path string) string {
+	name := path[strings.LastIndexByte(path, '/')+1:]
+	name, _, _ = strings.Cut(name, "[")
+	return name
+}
+
+func escapeText(s string) string {
+	var buf bytes.Buffer
+	_ = xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
@@ -0,0 +1,157 @@
+package gopom
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyEdits(t *testing.T) {
+	b, err := os.ReadFile(queryFilename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		edit Edit
+		// old and new are the lines of the document that change.
+		old, new string
+	}{
+		{
+			name: "text",
+			edit: Edit{Query: "dependencies[artifactId=guava].version", Value: "33.2.0-jre"},
+			old:  "      <version>33.0.0-jre</version>\n",
+			new:  "      <version>33.2.0-jre</version>\n",
+		},
+		{
+			name: "property",
+			edit: Edit{Query: "properties.java.version", Value: "21"},
+			old:  "    <java.version>17</java.version>\n",
+			new:  "    <java.version>21</java.version>\n",
+		},
+		{
+			name: "escaped",
+			edit: Edit{Query: "artifactId", Value: "a<b"},
+			old:  "  <artifactId>query</artifactId>\n",
+			new:  "  <artifactId>a&lt;b</artifactId>\n",
+		},
+		{
+			name: "configuration",
+			edit: Edit{Query: "build.plugins.configuration.compilerArgs.arg[1]", Value: "-Xlint:none"},
+			old:  "            <arg>-Werror</arg>\n",
+			new:  "            <arg>-Xlint:none</arg>\n",
+		},
+		{
+			name: "all matches",
+			edit: Edit{Query: "dependencies[scope=test].scope", Value: "provided"},
+			old:  "\n      <scope>test</scope>\n",
+			new:  "\n      <scope>provided</scope>\n",
+		},
+		{
+			name: "new property",
+			edit: Edit{Query: "properties.maven.compiler.release", Value: "17"},
+			old:  "    <java>ambiguous</java>\n",
+			new:  "    <java>ambiguous</java>\n    <maven.compiler.release>17</maven.compiler.release>\n",
+		},
+		{
+			name: "new elements",
+			edit: Edit{Query: "scm.url", Value: "https://example.com/query"},
+			old:  "    <java>ambiguous</java>\n  </properties>\n",
+			new:  "    <java>ambiguous</java>\n  </properties>\n  <scm>\n    <url>https://example.com/query</url>\n  </scm>\n",
+		},
+		{
+			name: "new element between siblings",
+			edit: Edit{Query: "dependencies[artifactId=junit-jupiter].type", Value: "test-jar"},
+			old:  "      <version>5.10.0</version>\n      <scope>test</scope>\n",
+			new:  "      <version>5.10.0</version>\n      <type>test-jar</type>\n      <scope>test</scope>\n",
+		},
+		{
+			name: "new element in selected item",
+			edit: Edit{Query: "dependencies[artifactId=guava].scope", Value: "runtime"},
+			old:  "      <version>33.0.0-jre</version>\n",
+			new:  "      <version>33.0.0-jre</version>\n      <scope>runtime</scope>\n",
+		},
+		{
+			name: "new configuration",
+			edit: Edit{Query: "build.plugins[artifactId=maven-surefire-plugin].configuration.skipTests", Value: "true"},
+			old:  "        <version>3.2.2</version>\n",
+			new:  "        <version>3.2.2</version>\n        <configuration>\n          <skipTests>true</skipTests>\n        </configuration>\n",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := ApplyEdits(b, test.edit)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, strings.Replace(string(b), test.old, test.new, -1), string(got))
+		})
+	}
+}
+
+func TestApplyEditsErrors(t *testing.T) {
+	b, err := os.ReadFile(queryFilename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range []Edit{
+		{Query: "dependencies[0]", Value: "x"},
+		{Query: "dependencies[artifactId=missing].version", Value: "1"},
+		{Query: "licenses.name", Value: "MIT"},
+		{Query: "nope", Value: "x"},
+		{Query: "dependencies[", Value: "x"},
+	} {
+		_, err := ApplyEdits(b, e)
+		assert.Error(t, err, e.Query)
+	}
+}
+
+func TestParseEdits(t *testing.T) {
+	edits, err := ParseEdits(strings.NewReader(`
+# Upgrade guava.
+dependencies[artifactId=guava].version   33.2.0-jre
+dependencies[artifactId="a b"].version 1.0
+name "  padded  "
+description some text with spaces
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []Edit{
+		{Query: "dependencies[artifactId=guava].version", Value: "33.2.0-jre"},
+		{Query: `dependencies[artifactId="a b"].version`, Value: "1.0"},
+		{Query: "name", Value: "  padded  "},
+		{Query: "description", Value: "some text with spaces"},
+	}, edits)
+
+	_, err = ParseEdits(strings.NewReader("version\n"))
+	assert.EqualError(t, err, "line 1: missing value")
+}
+
+func TestEditFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pom.xml")
+	copyFile(t, queryFilename, path)
+	err := EditFile(path,
+		Edit{Query: "version", Value: "1.1.0"},
+		Edit{Query: "properties.java.version", Value: "21"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := Parse(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "1.1.0", p.Version)
+	assert.Equal(t, "21", p.Properties.Entries["java.version"])
+
+	// Failing edits leave the file untouched.
+	before, _ := os.ReadFile(path)
+	assert.Error(t, EditFile(path, Edit{Query: "version", Value: "2"}, Edit{Query: "nope", Value: "x"}))
+	after, _ := os.ReadFile(path)
+	assert.Equal(t, string(before), string(after))
+}
@@ -0,0 +1,598 @@
+package gopom
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+)
+
+// EffectiveOptions configures how Effective builds the effective model.
+type EffectiveOptions struct {
+	// Dir is the directory of the project, used to locate its parents and
+	// to evaluate profile activation. It defaults to the current directory.
+	Dir string
+	// Profiles are the explicitly requested profiles, see
+	// Project.ActiveProfiles.
+	Profiles []string
+	// Parse is used to load the parent POMs. It defaults to the Parse
+	// method of a cache shared by the package, and can be set to the one
+	// of another Cache.
+	Parse func(path string) (*Project, error)
+	// Repository is a local Maven repository where parents not found on
+	// disk are looked up. When empty, such parents are not loaded.
+	Repository string
+	// Settings, when set, activate the POM profiles listed in their
+	// activeProfiles, and their own active profiles are injected into the
+	// effective model, see Settings.ActivatedProfiles.
+	Settings *Settings
+}
+
+// Effective returns the effective model of p, similar to what
+// `mvn help:effective-pom` prints:
+//
+//   - the active profiles of p and of its parents are injected into them,
+//   - p inherits from its parents, found on disk through their relativePath,
+//   - the active profiles of opts.Settings are injected into the result,
+//   - ${...} expressions referencing properties, project coordinates and
+//     environment variables are interpolated,
+//   - dependency and plugin management is applied.
+//
+// Parents that can be found neither on disk nor in opts.Repository end the
+// inheritance chain. Imported BOMs are not resolved.
+// p itself is not modified.
+func Effective(p *Project, opts EffectiveOptions) (*Project, error) {
+	if opts.Dir == "" {
+		opts.Dir = "."
+	}
+	if opts.Parse == nil {
+		opts.Parse = defaultCache.Parse
+	}
+
+	type model struct {
+		p   *Project
+		dir string
+	}
+	lineage := []model{{p: p.Clone(), dir: opts.Dir}}
+	seen := map[string]bool{}
+	for {
+		child := lineage[len(lineage)-1]
+		if child.p.Parent == nil {
+			break
+		}
+		path := parentPOMPath(child.dir, child.p.Parent.RelativePath)
+		parent, err := opts.Parse(path)
+		if opts.Repository != "" && (errors.Is(err, fs.ErrNotExist) || err == nil && !isParent(parent, child.p.Parent)) {
+			path = repositoryPOMPath(opts.Repository, child.p.Parent.Coordinate())
+			parent, err = opts.Parse(path)
+		}
+		if errors.Is(err, fs.ErrNotExist) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to load the parent %s: %w", path, err)
+		}
+		if !isParent(parent, child.p.Parent) || seen[path] {
+			break
+		}
+		seen[path] = true
+		lineage = append(lineage, model{p: parent.Clone(), dir: filepath.Dir(path)})
+	}
+
+	var ids []string
+	if opts.Settings != nil {
+		ids = append(ids, derefSlice(opts.Settings.ActiveProfiles)...)
+	}
+	ids = append(ids, opts.Profiles...)
+	for _, m := range lineage {
+		for _, profile := range m.p.ActiveProfiles(m.dir, ids...) {
+			injectProfile(m.p, profile)
+		}
+	}
+	eff := lineage[len(lineage)-1].p
+	for i := len(lineage) - 2; i >= 0; i-- {
+		inherit(lineage[i].p, eff)
+		eff = lineage[i].p
+	}
+	for _, profile := range opts.Settings.Clone().ActivatedProfiles(opts.Dir, opts.Profiles...) {
+		injectProfile(eff, profile.profile())
+	}
+	interpolate(eff, opts.Dir)
+	applyManagement(eff)
+	return eff, nil
+}
+
+// isParent tells whether p is the project referenced by parent.
+func isParent(p *Project, parent *Parent) bool {
+	return effectiveGroupID(p) == parent.GroupID && p.ArtifactID == parent.ArtifactID
+}
+
+// parentPOMPath returns the path of the parent POM of a project in dir with
+// the given relativePath, or an empty string if the parent is not to be
+// looked up on disk.
+func parentPOMPath(dir, relativePath string) string {
+	if relativePath == "" {
+		relativePath = ".."
+	}
+	path := filepath.Join(dir, filepath.FromSlash(relativePath))
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		path = ProjectFile(path)
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	return path
+}
+
+func effectiveGroupID(p *Project) string {
+	if p.GroupID == "" && p.Parent != nil {
+		return p.Parent.GroupID
+	}
+	return p.GroupID
+}
+
+func effectiveVersion(p *Project) string {
+	if p.Version == "" && p.Parent != nil {
+		return p.Parent.Version
+	}
+	return p.Version
+}
+
+// dependencyKey identifies a dependency the way Maven merges them.
+func dependencyKey(d Dependency) string {
+	typ := d.Type
+	if typ == "" {
+		typ = "jar"
+	}
+	return d.GroupID + ":" + d.ArtifactID + ":" + typ + ":" + d.Classifier
+}
+
+func pluginKey(p Plugin) string {
+	groupID := p.GroupID
+	if groupID == "" {
+		groupID = "org.apache.maven.plugins"
+	}
+	return groupID + ":" + p.ArtifactID
+}
+
+// mergeList merges the elements of parent and child identified by key:
+// parent elements come first, and child elements replace the parent ones
+// with the same key, after merge combined them.
+func mergeList[T any](parent, child *[]T, key func(T) string, merge func(child *T, parent T)) *[]T {
+	if parent == nil || len(*parent) == 0 {
+		return child
+	}
+	merged := append([]T(nil), *parent...)
+	index := map[string]int{}
+	for i, e := range merged {
+		index[key(e)] = i
+	}
+	if child != nil {
+		for _, e := range *child {
+			i, ok := index[key(e)]
+			if !ok {
+				index[key(e)] = len(merged)
+				merged = append(merged, e)
+				continue
+			}
+			if merge != nil {
+				merge(&e, merged[i])
+			}
+			merged[i] = e
+		}
+	}
+	return &merged
+}
+
+func mergeProperties(parent, child *Properties) *Properties {
+	if parent == nil {
+		return child
+	}
+	if child == nil {
+		child = &Properties{Entries: map[string]string{}}
+	}
+	merged := &Properties{Entries: map[string]string{}, comments: child.comments}
+	for _, key := range parent.Order {
+		if _, ok := merged.Entries[key]; !ok {
+			merged.Order = append(merged.Order, key)
+		}
+		merged.Entries[key] = parent.Entries[key]
+	}
+	for _, key := range child.Order {
+		if _, ok := merged.Entries[key]; !ok {
+			merged.Order = append(merged.Order, key)
+		}
+		merged.Entries[key] = child.Entries[key]
+	}
+	return merged
+}
+
+func mergeDependencies(parent, child *[]Dependency) *[]Dependency {
+	return mergeList(parent, child, dependencyKey, nil)
+}
+
+func mergeDependencyManagement(parent, child *DependencyManagement) *DependencyManagement {
+	if parent == nil {
+		return child
+	}
+	if child == nil {
+		child = &DependencyManagement{}
+	}
+	child.Dependencies = mergeDependencies(parent.Dependencies, child.Dependencies)
+	return child
+}
+
+func mergePlugin(child *Plugin, parent Plugin) {
+	if child.Version == "" {
+		child.Version = parent.Version
+	}
+	if child.Extensions == "" {
+		child.Extensions = parent.Extensions
+	}
+	if child.Configuration == nil {
+		child.Configuration = parent.Configuration
+	}
+	child.Dependencies = mergeDependencies(parent.Dependencies, child.Dependencies)
+	child.Executions = mergeList(parent.Executions, child.Executions, func(e PluginExecution) string {
+		return e.ID
+	}, func(child *PluginExecution, parent PluginExecution) {
+		if child.Phase == "" {
+			child.Phase = parent.Phase
+		}
+		if child.Goals == nil {
+			child.Goals = parent.Goals
+		}
+		if child.Configuration == nil {
+			child.Configuration = parent.Configuration
+		}
+	})
+}
+
+// inheritedPlugins returns the plugins that are inherited by children.
+func inheritedPlugins(plugins *[]Plugin) *[]Plugin {
+	if plugins == nil {
+		return nil
+	}
+	var inherited []Plugin
+	for _, p := range *plugins {
+		if p.Inherited != "false" {
+			inherited = append(inherited, p)
+		}
+	}
+	return &inherited
+}
+
+func mergeBuildBase(parent, child *BuildBase) {
+	if child.DefaultGoal == "" {
+		child.DefaultGoal = parent.DefaultGoal
+	}
+	if child.Resources == nil {
+		child.Resources = parent.Resources
+	}
+	if child.TestResources == nil {
+		child.TestResources = parent.TestResources
+	}
+	if child.Directory == "" {
+		child.Directory = parent.Directory
+	}
+	if child.FinalName == "" {
+		child.FinalName = parent.FinalName
+	}
+	if child.Filters == nil {
+		child.Filters = parent.Filters
+	}
+	if parent.PluginManagement != nil {
+		if child.PluginManagement == nil {
+			child.PluginManagement = &PluginManagement{}
+		}
+		child.PluginManagement.Plugins = mergeList(inheritedPlugins(parent.PluginManagement.Plugins), child.PluginManagement.Plugins, pluginKey, mergePlugin)
+	}
+	child.Plugins = mergeList(inheritedPlugins(parent.Plugins), child.Plugins, pluginKey, mergePlugin)
+}
+
+func mergeBuild(parent, child *Build) *Build {
+	if parent == nil {
+		return child
+	}
+	if child == nil {
+		child = &Build{}
+	}
+	if child.SourceDirectory == "" {
+		child.SourceDirectory = parent.SourceDirectory
+	}
+	if child.ScriptSourceDirectory == "" {
+		child.ScriptSourceDirectory = parent.ScriptSourceDirectory
+	}
+	if child.TestSourceDirectory == "" {
+		child.TestSourceDirectory = parent.TestSourceDirectory
+	}
+	if child.OutputDirectory == "" {
+		child.OutputDirectory = parent.OutputDirectory
+	}
+	if child.TestOutputDirectory == "" {
+		child.TestOutputDirectory = parent.TestOutputDirectory
+	}
+	child.Extensions = mergeList(parent.Extensions, child.Extensions, func(e Extension) string {
+		return e.GroupID + ":" + e.ArtifactID
+	}, nil)
+	mergeBuildBase(&parent.BuildBase, &child.BuildBase)
+	return child
+}
+
+func mergeRepositories(parent, child *[]Repository) *[]Repository {
+	return mergeList(parent, child, func(r Repository) string { return r.ID }, nil)
+}
+
+func mergePluginRepositories(parent, child *[]PluginRepository) *[]PluginRepository {
+	return mergeList(parent, child, func(r PluginRepository) string { return r.ID }, nil)
+}
+
+// childURL returns the URL a child inherits from its parent's, to which
+// Maven appends the child's artifactId.
+func childURL(parent, artifactID string) string {
+	if parent == "" {
+		return ""
+	}
+	return strings.TrimSuffix(parent, "/") + "/" + artifactID
+}
+
+// inherit makes child inherit from parent, following Maven's inheritance
+// rules: artifactId, name, packaging, prerequisites, modules and profiles
+// are not inherited.
+func inherit(child, parent *Project) {
+	if child.GroupID == "" {
+		child.GroupID = parent.GroupID
+	}
+	if child.Version == "" {
+		child.Version = parent.Version
+	}
+	if child.ModelVersion == "" {
+		child.ModelVersion = parent.ModelVersion
+	}
+	if child.Description == "" {
+		child.Description = parent.Description
+	}
+	if child.URL == "" {
+		child.URL = childURL(parent.URL, child.ArtifactID)
+	}
+	if child.InceptionYear == "" {
+		child.InceptionYear = parent.InceptionYear
+	}
+	if child.Organization == nil {
+		child.Organization = parent.Organization
+	}
+	if child.Licenses == nil {
+		child.Licenses = parent.Licenses
+	}
+	if child.Developers == nil {
+		child.Developers = parent.Developers
+	}
+	if child.Contributors == nil {
+		child.Contributors = parent.Contributors
+	}
+	if child.MailingLists == nil {
+		child.MailingLists = parent.MailingLists
+	}
+	if child.SCM == nil && parent.SCM != nil {
+		child.SCM = &Scm{
+			Connection:          childURL(parent.SCM.Connection, child.ArtifactID),
+			DeveloperConnection: childURL(parent.SCM.DeveloperConnection, child.ArtifactID),
+			Tag:                 parent.SCM.Tag,
+			URL:                 childURL(parent.SCM.URL, child.ArtifactID),
+		}
+	}
+	if child.IssueManagement == nil {
+		child.IssueManagement = parent.IssueManagement
+	}
+	if child.CIManagement == nil {
+		child.CIManagement = parent.CIManagement
+	}
+	if child.DistributionManagement == nil && parent.DistributionManagement != nil {
+		dm := *parent.DistributionManagement
+		dm.Relocation = nil
+		child.DistributionManagement = &dm
+	}
+	if child.Reporting == nil {
+		child.Reporting = parent.Reporting
+	}
+	child.Properties = mergeProperties(parent.Properties, child.Properties)
+	child.DependencyManagement = mergeDependencyManagement(parent.DependencyManagement, child.DependencyManagement)
+	child.Dependencies = mergeDependencies(parent.Dependencies, child.Dependencies)
+	child.Repositories = mergeRepositories(parent.Repositories, child.Repositories)
+	child.PluginRepositories = mergePluginRepositories(parent.PluginRepositories, child.PluginRepositories)
+	child.Build = mergeBuild(parent.Build, child.Build)
+}
+
+// injectProfile merges an active profile into the model it belongs to. The
+// profile takes precedence over the model.
+func injectProfile(p *Project, profile *Profile) {
+	p.Properties = mergeProperties(p.Properties, profile.Properties)
+	p.DependencyManagement = mergeDependencyManagement(p.DependencyManagement, profile.DependencyManagement)
+	p.Dependencies = mergeDependencies(p.Dependencies, profile.Dependencies)
+	p.Repositories = mergeRepositories(p.Repositories, profile.Repositories)
+	p.PluginRepositories = mergePluginRepositories(p.PluginRepositories, profile.PluginRepositories)
+	if profile.Modules != nil {
+		modules := append([]string(nil), derefStrings(p.Modules)...)
+		modules = append(modules, *profile.Modules...)
+		p.Modules = &modules
+	}
+	if profile.DistributionManagement != nil {
+		p.DistributionManagement = profile.DistributionManagement
+	}
+	if profile.Reporting != nil {
+		p.Reporting = profile.Reporting
+	}
+	if profile.Build != nil {
+		base := *profile.Build
+		if p.Build == nil {
+			p.Build = &Build{}
+		}
+		// The profile build is the child: it wins over the project's.
+		mergeBuildBase(&p.Build.BuildBase, &base)
+		p.Build.BuildBase = base
+	}
+}
+
+func derefStrings(s *[]string) []string {
+	if s == nil {
+		return nil
+	}
+	return *s
+}
+
+// applyManagement fills in the dependencies and plugins of p from its
+// dependency and plugin management.
+func applyManagement(p *Project) {
+	if p.DependencyManagement != nil && p.DependencyManagement.Dependencies != nil && p.Dependencies != nil {
+		managed := map[string]Dependency{}
+		for _, d := range *p.DependencyManagement.Dependencies {
+			managed[dependencyKey(d)] = d
+		}
+		for i := range *p.Dependencies {
+			d := &(*p.Dependencies)[i]
+			m, ok := managed[dependencyKey(*d)]
+			if !ok {
+				continue
+			}
+			if d.Version == "" {
+				d.Version = m.Version
+			}
+			if d.Scope == "" {
+				d.Scope = m.Scope
+			}
+			if d.Exclusions == nil {
+				d.Exclusions = m.Exclusions
+			}
+			if d.Optional == "" {
+				d.Optional = m.Optional
+			}
+			if d.SystemPath == "" {
+				d.SystemPath = m.SystemPath
+			}
+		}
+	}
+	if b := p.Build; b != nil && b.PluginManagement != nil && b.PluginManagement.Plugins != nil && b.Plugins != nil {
+		managed := map[string]Plugin{}
+		for _, plugin := range *b.PluginManagement.Plugins {
+			managed[pluginKey(plugin)] = plugin
+		}
+		for i := range *b.Plugins {
+			plugin := &(*b.Plugins)[i]
+			if m, ok := managed[pluginKey(*plugin)]; ok {
+				mergePlugin(plugin, m)
+			}
+		}
+	}
+}
+
+// interpolate replaces the ${...} expressions in all the values of p.
+func interpolate(p *Project, dir string) {
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+	values := map[string]string{
+		"project.groupId":     p.GroupID,
+		"project.artifactId":  p.ArtifactID,
+		"project.version":     p.Version,
+		"project.packaging":   p.Packaging,
+		"project.name":        p.Name,
+		"project.description": p.Description,
+		"project.url":         p.URL,
+		"project.basedir":     dir,
+		"basedir":             dir,
+	}
+	if p.Parent != nil {
+		values["project.parent.groupId"] = p.Parent.GroupID
+		values["project.parent.artifactId"] = p.Parent.ArtifactID
+		values["project.parent.version"] = p.Parent.Version
+	}
+	var properties map[string]string
+	if p.Properties != nil {
+		properties = p.Properties.Entries
+	}
+	lookup := func(name string) (string, bool) {
+		if v, ok := properties[name]; ok {
+			return v, true
+		}
+		if strings.HasPrefix(name, "pom.") {
+			name = "project." + strings.TrimPrefix(name, "pom.")
+		}
+		if v, ok := values[name]; ok && v != "" {
+			return v, true
+		}
+		if env, ok := strings.CutPrefix(name, "env."); ok {
+			return os.LookupEnv(env)
+		}
+		return "", false
+	}
+	interpolateValue(reflect.ValueOf(p).Elem(), func(s string) string {
+		return expand(s, lookup, 0)
+	})
+}
+
+// maxExpansionDepth bounds the nesting of expressions, which guards against
+// properties referencing each other.
+const maxExpansionDepth = 16
+
+// expand replaces the ${...} expressions in s that lookup knows about,
+// recursively.
+func expand(s string, lookup func(string) (string, bool), depth int) string {
+	if depth > maxExpansionDepth || !strings.Contains(s, "${") {
+		return s
+	}
+	var b strings.Builder
+	for {
+		start := strings.Index(s, "${")
+		if start < 0 {
+			break
+		}
+		end := strings.Index(s[start:], "}")
+		if end < 0 {
+			break
+		}
+		end += start
+		b.WriteString(s[:start])
+		if v, ok := lookup(s[start+2 : end]); ok {
+			b.WriteString(expand(v, lookup, depth+1))
+		} else {
+			b.WriteString(s[start : end+1])
+		}
+		s = s[end+1:]
+	}
+	b.WriteString(s)
+	return b.String()
+}
+
+// interpolateValue applies fn to every string reachable from v.
+func interpolateValue(v reflect.Value, fn func(string) string) {
+	switch v.Kind() {
+	case reflect.String:
+		if v.CanSet() {
+			v.SetString(fn(v.String()))
+		}
+	case reflect.Pointer:
+		if !v.IsNil() {
+			interpolateValue(v.Elem(), fn)
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if v.Field(i).CanSet() {
+				interpolateValue(v.Field(i), fn)
+			}
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			interpolateValue(v.Index(i), fn)
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			if iter.Value().Kind() == reflect.String {
+				v.SetMapIndex(iter.Key(), reflect.ValueOf(fn(iter.Value().String())))
+			}
+		}
+	}
+}
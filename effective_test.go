@@ -0,0 +1,91 @@
+package gopom
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func dependencyCoordinates(deps *[]Dependency) []string {
+	var coords []string
+	if deps == nil {
+		return nil
+	}
+	for _, d := range *deps {
+		c := d.GroupID + ":" + d.ArtifactID + ":" + d.Version
+		if d.Scope != "" {
+			c += ":" + d.Scope
+		}
+		coords = append(coords, c)
+	}
+	return coords
+}
+
+func TestEffective(t *testing.T) {
+	const dir = "./testdata/effective/child"
+	p, err := Parse(dir + "/pom.xml")
+	if err != nil {
+		t.Fatalf("failed parsing the file: %v", err)
+	}
+	eff, err := Effective(p, EffectiveOptions{Dir: dir})
+	if err != nil {
+		t.Fatalf("failed building the effective model: %v", err)
+	}
+
+	assert.Equal(t, "com.example", eff.GroupID)
+	assert.Equal(t, "2.1.0", eff.Version)
+	assert.Empty(t, eff.Name)
+	assert.Nil(t, eff.Modules)
+	assert.Equal(t, "https://example.com/parent/child", eff.URL)
+	assert.Equal(t, "Built with Java 21, version 2.1.0", eff.Description)
+	assert.Equal(t, "The Apache Software License, Version 2.0", (*eff.Licenses)[0].Name)
+
+	assert.Equal(t, []string{"java.version", "guava.version", "junit.version", "profile.marker"}, eff.Properties.Order)
+	assert.Equal(t, "21", eff.Properties.Entries["java.version"])
+	assert.Equal(t, "parent-default", eff.Properties.Entries["profile.marker"])
+
+	assert.Equal(t, []string{
+		"org.slf4j:slf4j-api:2.0.13",
+		"com.google.guava:guava:33.2.0-jre",
+		"org.junit.jupiter:junit-jupiter:5.10.2:test",
+	}, dependencyCoordinates(eff.Dependencies))
+
+	plugins := *eff.Build.Plugins
+	assert.Equal(t, 2, len(plugins))
+	assert.Equal(t, "maven-enforcer-plugin", plugins[0].ArtifactID)
+	assert.Equal(t, "maven-compiler-plugin", plugins[1].ArtifactID)
+	assert.Equal(t, "3.13.0", plugins[1].Version)
+	assert.Contains(t, plugins[1].Configuration.RawConfiguration, "<release>21</release>")
+
+	// The original model is left untouched.
+	assert.Empty(t, p.GroupID)
+	assert.Equal(t, "", (*p.Dependencies)[0].Version)
+}
+
+func TestEffectiveProfiles(t *testing.T) {
+	const dir = "./testdata/effective/child"
+	p, err := Parse(dir + "/pom.xml")
+	if err != nil {
+		t.Fatalf("failed parsing the file: %v", err)
+	}
+	eff, err := Effective(p, EffectiveOptions{Dir: dir, Profiles: []string{"release"}})
+	if err != nil {
+		t.Fatalf("failed building the effective model: %v", err)
+	}
+	assert.Contains(t, dependencyCoordinates(eff.Dependencies), "com.example:signing:2.1.0")
+	// Requesting a profile only disables the default profiles of the POM
+	// declaring it.
+	assert.Equal(t, "parent-default", eff.Properties.Entries["profile.marker"])
+}
+
+func TestEffectiveWithoutParent(t *testing.T) {
+	p, err := Parse(filename)
+	if err != nil {
+		t.Fatalf("failed parsing the file: %v", err)
+	}
+	eff, err := Effective(p, EffectiveOptions{Dir: "./testdata"})
+	if err != nil {
+		t.Fatalf("failed building the effective model: %v", err)
+	}
+	assert.Equal(t, p.ArtifactID, eff.ArtifactID)
+}
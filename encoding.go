@@ -0,0 +1,419 @@
+package gopom
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// The JSON and YAML representations of a project follow its XML elements:
+// fields are named after the elements, lists are arrays of their items
+// without the wrapping element, and booleans and strings are kept as such.
+//
+// Properties are objects whose fields keep the order of the properties.
+//
+// Configurations are nested objects: elements only holding text are
+// strings, repeated elements are arrays, attributes are fields prefixed by
+// "@", and the text of elements that also have attributes or children is
+// in a "#text" field. Configurations that only hold text are strings. For
+// instance
+//
+//	<configuration combine.children="append">
+//	  <release>17</release>
+//	  <compilerArgs>
+//	    <arg>-Xlint:all</arg>
+//	    <arg>-Werror</arg>
+//	  </compilerArgs>
+//	</configuration>
+//
+// is represented as
+//
+//	{
+//	  "@combine.children": "append",
+//	  "release": "17",
+//	  "compilerArgs": {"arg": ["-Xlint:all", "-Werror"]}
+//	}
+
+// tree is an ordered JSON or YAML value: text, a list, or an object whose
+// fields keep their order.
+type tree struct {
+	kind   treeKind
+	text   string
+	items  []tree
+	fields []treeField
+}
+
+type treeKind int
+
+const (
+	treeText treeKind = iota
+	treeList
+	treeObject
+)
+
+type treeField struct {
+	name  string
+	value tree
+}
+
+func textTree(s string) tree {
+	return tree{kind: treeText, text: s}
+}
+
+func (t tree) appendJSON(b []byte) []byte {
+	switch t.kind {
+	case treeList:
+		b = append(b, '[')
+		for i, item := range t.items {
+			if i > 0 {
+				b = append(b, ',')
+			}
+			b = item.appendJSON(b)
+		}
+		return append(b, ']')
+	case treeObject:
+		b = append(b, '{')
+		for i, f := range t.fields {
+			if i > 0 {
+				b = append(b, ',')
+			}
+			b = appendJSONString(b, f.name)
+			b = append(b, ':')
+			b = f.value.appendJSON(b)
+		}
+		return append(b, '}')
+	}
+	return appendJSONString(b, t.text)
+}
+
+func appendJSONString(b []byte, s string) []byte {
+	quoted, _ := json.Marshal(s)
+	return append(b, quoted...)
+}
+
+// decodeJSONTree reads a tree from b. Numbers and booleans are read as
+// text, and null as an empty text.
+func decodeJSONTree(b []byte) (tree, error) {
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	return readJSONTree(d)
+}
+
+func readJSONTree(d *json.Decoder) (tree, error) {
+	tok, err := d.Token()
+	if err != nil {
+		return tree{}, err
+	}
+	switch v := tok.(type) {
+	case json.Delim:
+		if v == '[' {
+			t := tree{kind: treeList}
+			for d.More() {
+				item, err := readJSONTree(d)
+				if err != nil {
+					return tree{}, err
+				}
+				t.items = append(t.items, item)
+			}
+			_, err := d.Token()
+			return t, err
+		}
+		t := tree{kind: treeObject}
+		for d.More() {
+			key, err := d.Token()
+			if err != nil {
+				return tree{}, err
+			}
+			value, err := readJSONTree(d)
+			if err != nil {
+				return tree{}, err
+			}
+			t.fields = append(t.fields, treeField{name: key.(string), value: value})
+		}
+		_, err := d.Token()
+		return t, err
+	case string:
+		return textTree(v), nil
+	case json.Number:
+		return textTree(v.String()), nil
+	case bool:
+		return textTree(fmt.Sprint(v)), nil
+	}
+	return textTree(""), nil
+}
+
+func (t tree) yamlNode() *yaml.Node {
+	switch t.kind {
+	case treeList:
+		n := &yaml.Node{Kind: yaml.SequenceNode}
+		for _, item := range t.items {
+			n.Content = append(n.Content, item.yamlNode())
+		}
+		return n
+	case treeObject:
+		n := &yaml.Node{Kind: yaml.MappingNode}
+		for _, f := range t.fields {
+			n.Content = append(n.Content, textTree(f.name).yamlNode(), f.value.yamlNode())
+		}
+		return n
+	}
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: t.text}
+}
+
+func yamlTree(n *yaml.Node) (tree, error) {
+	switch n.Kind {
+	case yaml.DocumentNode:
+		if len(n.Content) == 0 {
+			return textTree(""), nil
+		}
+		return yamlTree(n.Content[0])
+	case yaml.AliasNode:
+		return yamlTree(n.Alias)
+	case yaml.SequenceNode:
+		t := tree{kind: treeList}
+		for _, c := range n.Content {
+			item, err := yamlTree(c)
+			if err != nil {
+				return tree{}, err
+			}
+			t.items = append(t.items, item)
+		}
+		return t, nil
+	case yaml.MappingNode:
+		t := tree{kind: treeObject}
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			value, err := yamlTree(n.Content[i+1])
+			if err != nil {
+				return tree{}, err
+			}
+			t.fields = append(t.fields, treeField{name: n.Content[i].Value, value: value})
+		}
+		return t, nil
+	}
+	if n.Tag == "!!null" {
+		return textTree(""), nil
+	}
+	return textTree(n.Value), nil
+}
+
+func (p Properties) tree() tree {
+	t := tree{kind: treeObject}
+	for _, key := range p.Order {
+		t.fields = append(t.fields, treeField{name: key, value: textTree(p.Entries[key])})
+	}
+	return t
+}
+
+func (p *Properties) setTree(t tree) error {
+	if t.kind != treeObject {
+		return fmt.Errorf("properties must be an object")
+	}
+	p.Entries = map[string]string{}
+	p.Order = nil
+	for _, f := range t.fields {
+		if f.value.kind != treeText {
+			return fmt.Errorf("property %s must be a string", f.name)
+		}
+		if _, ok := p.Entries[f.name]; !ok {
+			p.Order = append(p.Order, f.name)
+		}
+		p.Entries[f.name] = f.value.text
+	}
+	return nil
+}
+
+// MarshalJSON encodes the properties as an object, in order.
+func (p Properties) MarshalJSON() ([]byte, error) {
+	return p.tree().appendJSON(nil), nil
+}
+
+// UnmarshalJSON decodes the properties from an object, keeping their
+// order.
+func (p *Properties) UnmarshalJSON(b []byte) error {
+	t, err := decodeJSONTree(b)
+	if err != nil {
+		return err
+	}
+	return p.setTree(t)
+}
+
+// MarshalYAML encodes the properties as a mapping, in order.
+func (p Properties) MarshalYAML() (any, error) {
+	return p.tree().yamlNode(), nil
+}
+
+// UnmarshalYAML decodes the properties from a mapping, keeping their order.
+func (p *Properties) UnmarshalYAML(n *yaml.Node) error {
+	t, err := yamlTree(n)
+	if err != nil {
+		return err
+	}
+	return p.setTree(t)
+}
+
+func (c Configuration) tree() (tree, error) {
+	root, err := c.root()
+	if err != nil {
+		return tree{}, err
+	}
+	if c.Children == "" && c.Self == "" && len(root.Children) == 0 {
+		return textTree(root.Text), nil
+	}
+	var attrs []xml.Attr
+	if c.Children != "" {
+		attrs = append(attrs, xml.Attr{Name: xml.Name{Local: "combine.children"}, Value: c.Children})
+	}
+	if c.Self != "" {
+		attrs = append(attrs, xml.Attr{Name: xml.Name{Local: "combine.self"}, Value: c.Self})
+	}
+	return elementsTree(attrs, root.Text, root.Children), nil
+}
+
+// elementsTree returns the object holding attrs, text and elements.
+func elementsTree(attrs []xml.Attr, text string, elements []*ConfigElement) tree {
+	t := tree{kind: treeObject}
+	for _, a := range attrs {
+		t.fields = append(t.fields, treeField{name: "@" + a.Name.Local, value: textTree(a.Value)})
+	}
+	if text != "" {
+		t.fields = append(t.fields, treeField{name: "#text", value: textTree(text)})
+	}
+	// Group the elements by name, in the order they first appear.
+	index := map[string]int{}
+	for _, e := range elements {
+		value := textTree(e.Text)
+		if len(e.Attrs) > 0 || len(e.Children) > 0 {
+			value = elementsTree(e.Attrs, e.Text, e.Children)
+		}
+		i, ok := index[e.Name]
+		if !ok {
+			index[e.Name] = len(t.fields)
+			t.fields = append(t.fields, treeField{name: e.Name, value: value})
+			continue
+		}
+		if f := &t.fields[i]; f.value.kind == treeList {
+			f.value.items = append(f.value.items, value)
+		} else {
+			f.value = tree{kind: treeList, items: []tree{f.value, value}}
+		}
+	}
+	return t
+}
+
+func (c *Configuration) setTree(t tree) error {
+	c.Children, c.Self = "", ""
+	switch t.kind {
+	case treeText:
+		c.RawConfiguration = escapeText(t.text)
+		return nil
+	case treeList:
+		return fmt.Errorf("configuration must be an object or a string")
+	}
+	rest := tree{kind: treeObject}
+	for _, f := range t.fields {
+		switch f.name {
+		case "@combine.children":
+			c.Children = f.value.text
+		case "@combine.self":
+			c.Self = f.value.text
+		default:
+			rest.fields = append(rest.fields, f)
+		}
+	}
+	var b strings.Builder
+	if err := writeTreeXML(&b, rest); err != nil {
+		return err
+	}
+	c.RawConfiguration = b.String()
+	return nil
+}
+
+// writeTreeXML writes the content of the element represented by the
+// object t: its text and child elements. Attributes are written by the
+// caller.
+func writeTreeXML(b *strings.Builder, t tree) error {
+	for _, f := range t.fields {
+		if strings.HasPrefix(f.name, "@") {
+			continue
+		}
+		if f.name == "#text" {
+			b.WriteString(escapeText(f.value.text))
+			continue
+		}
+		items := []tree{f.value}
+		if f.value.kind == treeList {
+			items = f.value.items
+		}
+		for _, item := range items {
+			if err := writeElementXML(b, f.name, item); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func writeElementXML(b *strings.Builder, name string, t tree) error {
+	if name == "" || strings.ContainsAny(name, " <>&\"'/=") {
+		return fmt.Errorf("invalid configuration element name %q", name)
+	}
+	b.WriteString("<" + name)
+	switch t.kind {
+	case treeText:
+		b.WriteString(">" + escapeText(t.text))
+	case treeList:
+		return fmt.Errorf("configuration element %s cannot hold nested arrays", name)
+	case treeObject:
+		for _, f := range t.fields {
+			if attr, ok := strings.CutPrefix(f.name, "@"); ok {
+				b.WriteString(" " + attr + `="` + escapeText(f.value.text) + `"`)
+			}
+		}
+		b.WriteString(">")
+		if err := writeTreeXML(b, t); err != nil {
+			return err
+		}
+	}
+	b.WriteString("</" + name + ">")
+	return nil
+}
+
+// MarshalJSON encodes the configuration as nested objects.
+func (c Configuration) MarshalJSON() ([]byte, error) {
+	t, err := c.tree()
+	if err != nil {
+		return nil, err
+	}
+	return t.appendJSON(nil), nil
+}
+
+// UnmarshalJSON decodes the configuration from nested objects.
+func (c *Configuration) UnmarshalJSON(b []byte) error {
+	t, err := decodeJSONTree(b)
+	if err != nil {
+		return err
+	}
+	return c.setTree(t)
+}
+
+// MarshalYAML encodes the configuration as nested mappings.
+func (c Configuration) MarshalYAML() (any, error) {
+	t, err := c.tree()
+	if err != nil {
+		return nil, err
+	}
+	return t.yamlNode(), nil
+}
+
+// UnmarshalYAML decodes the configuration from nested mappings.
+func (c *Configuration) UnmarshalYAML(n *yaml.Node) error {
+	t, err := yamlTree(n)
+	if err != nil {
+		return err
+	}
+	return c.setTree(t)
+}
@@ -0,0 +1,128 @@
+package gopom
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+// roundTrip converts p with marshal and unmarshal, writes the result as
+// XML and parses it back.
+func roundTrip(t *testing.T, p *Project, marshal func(any) ([]byte, error), unmarshal func([]byte, any) error) *Project {
+	t.Helper()
+	b, err := marshal(p)
+	if err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+	var converted Project
+	if err := unmarshal(b, &converted); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	x, err := converted.Marshal()
+	if err != nil {
+		t.Fatalf("failed to marshal to XML: %v", err)
+	}
+	parsed, err := ParseReader(bytes.NewReader(x))
+	if err != nil {
+		t.Fatalf("failed to parse the converted XML: %v\n%s", err, x)
+	}
+	return parsed
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	for _, path := range []string{"testdata/example.xml", queryFilename, commentsFilename} {
+		t.Run(path, func(t *testing.T) {
+			p, err := Parse(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want, err := json.Marshal(p)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := json.Marshal(roundTrip(t, p, json.Marshal, json.Unmarshal))
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.JSONEq(t, string(want), string(got))
+
+			got, err = json.Marshal(roundTrip(t, p, yaml.Marshal, yaml.Unmarshal))
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.JSONEq(t, string(want), string(got))
+		})
+	}
+}
+
+func TestPropertiesJSON(t *testing.T) {
+	var props Properties
+	err := json.Unmarshal([]byte(`{"z": "last", "a": "first", "n": 17, "b": true}`), &props)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []string{"z", "a", "n", "b"}, props.Order)
+	assert.Equal(t, "17", props.Entries["n"])
+	assert.Equal(t, "true", props.Entries["b"])
+
+	b, err := json.Marshal(props)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, `{"z":"last","a":"first","n":"17","b":"true"}`, string(b))
+
+	var yprops Properties
+	if err := yaml.Unmarshal([]byte("z: last\na: first\n"), &yprops); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []string{"z", "a"}, yprops.Order)
+
+	assert.Error(t, json.Unmarshal([]byte(`{"nested": {"a": "b"}}`), &props))
+	assert.Error(t, json.Unmarshal([]byte(`["a"]`), &props))
+}
+
+func TestConfigurationJSON(t *testing.T) {
+	c := Configuration{
+		Children: "append",
+		RawConfiguration: `
+          <release>17</release>
+          <compilerArgs>
+            <arg>-Xlint:all</arg>
+            <arg>-Werror</arg>
+          </compilerArgs>
+          <manifest mode="strict">text<main>App</main></manifest>
+          <empty/>`,
+	}
+	want := `{
+		"@combine.children": "append",
+		"release": "17",
+		"compilerArgs": {"arg": ["-Xlint:all", "-Werror"]},
+		"manifest": {"@mode": "strict", "#text": "text", "main": "App"},
+		"empty": ""
+	}`
+	b, err := json.Marshal(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.JSONEq(t, want, string(b))
+
+	var decoded Configuration
+	if err := json.Unmarshal([]byte(want), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "append", decoded.Children)
+	assert.Equal(t, `<release>17</release><compilerArgs><arg>-Xlint:all</arg><arg>-Werror</arg></compilerArgs><manifest mode="strict">text<main>App</main></manifest><empty></empty>`, decoded.RawConfiguration)
+
+	b, err = json.Marshal(Configuration{RawConfiguration: "configuration"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, `"configuration"`, string(b))
+
+	assert.Error(t, json.Unmarshal([]byte(`{"a": [["nested"]]}`), &decoded))
+	assert.Error(t, json.Unmarshal([]byte(`{"bad name": "x"}`), &decoded))
+}
@@ -0,0 +1,459 @@
+package gopom
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// FormatOptions configures Format.
+type FormatOptions struct {
+	// Indent is the indentation of nested elements. It defaults to four
+	// spaces.
+	Indent string
+	// SortDependencies sorts the dependencies of the project and of its
+	// profiles by groupId, then artifactId.
+	SortDependencies bool
+	// SortDependencyManagement sorts the managed dependencies the same way.
+	// Beware that the order of imported BOMs decides which one wins.
+	SortDependencyManagement bool
+	// SortProperties sorts properties by name.
+	SortProperties bool
+	// SortModules sorts modules by path.
+	SortModules bool
+	// Configuration also reindents plugin configurations, which are
+	// otherwise written exactly as they were parsed.
+	Configuration bool
+}
+
+// projectOrder is the order of the elements of a project recommended by
+// Maven, as used by the sortpom plugin.
+var projectOrder = []string{
+	"modelVersion",
+	"parent",
+	"groupId",
+	"artifactId",
+	"version",
+	"packaging",
+	"name",
+	"description",
+	"url",
+	"inceptionYear",
+	"organization",
+	"licenses",
+	"developers",
+	"contributors",
+	"mailingLists",
+	"prerequisites",
+	"modules",
+	"scm",
+	"issueManagement",
+	"ciManagement",
+	"distributionManagement",
+	"properties",
+	"dependencyManagement",
+	"dependencies",
+	"repositories",
+	"pluginRepositories",
+	"build",
+	"reporting",
+	"profiles",
+}
+
+// Format returns the project in canonical form: the elements of the project
+// in the order recommended by Maven, the other elements in the order of the
+// model, one element per line indented with opts.Indent, empty elements
+// written as <name/> and comments kept with the elements they belong to.
+// Lists are sorted as requested by opts. The result ends with a newline.
+//
+// Projects that were parsed are formatted from their source, with the
+// changes made since applied like ApplyProject does, so every comment is
+// kept, including the ones before the project and within elements the model
+// does not attach comments to.
+func (p *Project) Format(opts FormatOptions) ([]byte, error) {
+	if opts.Indent == "" {
+		opts.Indent = "    "
+	}
+	var b []byte
+	var err error
+	if p.source != nil && p.source.Text != nil {
+		b, err = ApplyProject(p.source.Text, p)
+	} else {
+		b, err = p.Clone().Marshal()
+	}
+	if err != nil {
+		return nil, err
+	}
+	root, err := parseFormatTree(b, opts.Configuration)
+	if err != nil {
+		return nil, fmt.Errorf("failed to format: %w", err)
+	}
+
+	var out bytes.Buffer
+	out.WriteString(xml.Header)
+	for _, n := range root.children {
+		switch {
+		case n.start != nil && n.start.Name.Local == "project":
+			orderFormatTree(n, reflect.TypeOf(Project{}))
+			sortFormatTree(n, opts)
+		case !n.comment:
+			continue
+		}
+		writeFormatNode(&out, n, opts.Indent, 0)
+		out.WriteString("\n")
+	}
+	return out.Bytes(), nil
+}
+
+// orderFormatTree sorts the elements of n, holding a value of type t, in
+// the order of the model, see orderChildren.
+func orderFormatTree(n *formatNode, t reflect.Type) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || t == configurationType.Elem() || t == propertiesType.Elem() || n.opaque {
+		return
+	}
+	fields := xmlFields(reflect.New(t).Elem())
+	order := projectOrder
+	if t != reflect.TypeOf(Project{}) {
+		order = nil
+		for _, f := range fields {
+			order = append(order, f.name)
+		}
+	}
+	n.children = orderChildren(n.children, order)
+
+	for _, c := range n.children {
+		for _, f := range fields {
+			if c.start == nil || c.start.Name.Local != f.name {
+				continue
+			}
+			if f.item == "" {
+				orderFormatTree(c, f.v.Type())
+				break
+			}
+			for _, item := range c.children {
+				if item.start != nil && item.start.Name.Local == f.item {
+					orderFormatTree(item, f.v.Type().Elem().Elem())
+				}
+			}
+			break
+		}
+	}
+}
+
+// sortFormatTree sorts the lists of the project n as requested by opts.
+func sortFormatTree(n *formatNode, opts FormatOptions) {
+	sortModel := func(n *formatNode) {
+		if opts.SortDependencies {
+			sortChildren(n.child("dependencies"), dependencyNodeLess)
+		}
+		if opts.SortDependencyManagement {
+			sortChildren(n.child("dependencyManagement").child("dependencies"), dependencyNodeLess)
+		}
+		if opts.SortProperties {
+			sortChildren(n.child("properties"), func(a, b *formatNode) bool { return a.start.Name.Local < b.start.Name.Local })
+		}
+		if opts.SortModules {
+			sortChildren(n.child("modules"), func(a, b *formatNode) bool { return a.content() < b.content() })
+		}
+	}
+	sortModel(n)
+	if profiles := n.child("profiles"); profiles != nil {
+		for _, profile := range profiles.children {
+			if profile.start != nil {
+				sortModel(profile)
+			}
+		}
+	}
+}
+
+// dependencyNodeLess orders dependencies by groupId, then artifactId.
+func dependencyNodeLess(a, b *formatNode) bool {
+	dependency := func(n *formatNode) Dependency {
+		return Dependency{
+			GroupID:    n.child("groupId").content(),
+			ArtifactID: n.child("artifactId").content(),
+			Type:       n.child("type").content(),
+			Classifier: n.child("classifier").content(),
+		}
+	}
+	return dependencyLess(dependency(a), dependency(b))
+}
+
+func dependencyLess(a, b Dependency) bool {
+	if a.GroupID != b.GroupID {
+		return a.GroupID < b.GroupID
+	}
+	if a.ArtifactID != b.ArtifactID {
+		return a.ArtifactID < b.ArtifactID
+	}
+	return dependencyKey(a) < dependencyKey(b)
+}
+
+// formatNode is an element, text or comment of the document being
+// formatted.
+type formatNode struct {
+	// start is set for elements.
+	start    *xml.StartElement
+	children []*formatNode
+	// raw is the content of configurations, written as is.
+	raw []byte
+	// opaque is set for elements whose content is raw.
+	opaque bool
+
+	text string
+	// comment is set for comments.
+	comment bool
+	// trailing is set for comments on the same line as the end of the
+	// preceding element.
+	trailing bool
+}
+
+func (n *formatNode) isSpace() bool {
+	return n.start == nil && !n.comment && strings.TrimSpace(n.text) == ""
+}
+
+// child returns the first child element of n called name, if any.
+func (n *formatNode) child(name string) *formatNode {
+	if n == nil {
+		return nil
+	}
+	for _, c := range n.children {
+		if c.start != nil && c.start.Name.Local == name {
+			return c
+		}
+	}
+	return nil
+}
+
+// content returns the text of n, if any.
+func (n *formatNode) content() string {
+	if n == nil {
+		return ""
+	}
+	var b strings.Builder
+	for _, c := range n.children {
+		if c.start == nil && !c.comment {
+			b.WriteString(c.text)
+		}
+	}
+	return b.String()
+}
+
+// parseFormatTree parses b into a tree of nodes. Configurations are kept
+// raw unless configuration is set.
+func parseFormatTree(b []byte, configuration bool) (*formatNode, error) {
+	d := xml.NewDecoder(bytes.NewReader(b))
+	root := &formatNode{}
+	stack := []*formatNode{root}
+	// newline tells whether a line ended since the last end tag, to tell
+	// trailing comments from leading ones.
+	newline := true
+	for {
+		tok, err := d.RawToken()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		parent := stack[len(stack)-1]
+		switch t := tok.(type) {
+		case xml.StartElement:
+			start := t.Copy()
+			n := &formatNode{start: &start}
+			parent.children = append(parent.children, n)
+			newline = false
+			if t.Name.Local != "configuration" || configuration {
+				stack = append(stack, n)
+				continue
+			}
+			n.opaque = true
+			from := d.InputOffset()
+			for depth := 1; depth > 0; {
+				to := d.InputOffset()
+				tok, err := d.RawToken()
+				if err != nil {
+					return nil, err
+				}
+				switch tok.(type) {
+				case xml.StartElement:
+					depth++
+				case xml.EndElement:
+					if depth--; depth == 0 {
+						n.raw = b[from:to]
+					}
+				}
+			}
+		case xml.EndElement:
+			if len(stack) == 1 {
+				return nil, fmt.Errorf("unexpected </%s>", t.Name.Local)
+			}
+			stack = stack[:len(stack)-1]
+			newline = false
+		case xml.CharData:
+			s := string(t)
+			if strings.TrimSpace(s) == "" && strings.Contains(s, "\n") {
+				newline = true
+			}
+			parent.children = append(parent.children, &formatNode{text: s})
+		case xml.Comment:
+			trailing := false
+			for i := len(parent.children) - 1; i >= 0; i-- {
+				if c := parent.children[i]; !c.isSpace() {
+					trailing = !newline && (c.start != nil || c.trailing)
+					break
+				}
+			}
+			parent.children = append(parent.children, &formatNode{text: string(t), comment: true, trailing: trailing})
+		}
+	}
+	return root, nil
+}
+
+// formatGroup is an element along with the comments before it and the
+// trailing ones after it.
+type formatGroup struct {
+	nodes   []*formatNode
+	element *formatNode
+}
+
+// groupChildren groups the elements of children with their comments, and
+// returns the comments following the last element apart. Whitespace is
+// dropped.
+func groupChildren(children []*formatNode) ([]formatGroup, []*formatNode) {
+	var groups []formatGroup
+	var pending []*formatNode
+	for _, n := range children {
+		switch {
+		case n.isSpace():
+		case n.trailing && len(groups) > 0:
+			g := &groups[len(groups)-1]
+			g.nodes = append(g.nodes, n)
+		case n.start != nil:
+			groups = append(groups, formatGroup{nodes: append(pending, n), element: n})
+			pending = nil
+		default:
+			pending = append(pending, n)
+		}
+	}
+	return groups, pending
+}
+
+// orderChildren sorts the elements of children in the given order, keeping
+// the comments with the elements they belong to. Unknown elements come
+// last, in their original order.
+func orderChildren(children []*formatNode, order []string) []*formatNode {
+	rank := func(n *formatNode) int {
+		for i, name := range order {
+			if name == n.start.Name.Local {
+				return i
+			}
+		}
+		return len(order)
+	}
+	groups, rest := groupChildren(children)
+	sort.SliceStable(groups, func(i, j int) bool { return rank(groups[i].element) < rank(groups[j].element) })
+	var out []*formatNode
+	for _, g := range groups {
+		out = append(out, g.nodes...)
+	}
+	return append(out, rest...)
+}
+
+// sortChildren sorts the elements of n with less, keeping the comments with
+// the elements they belong to.
+func sortChildren(n *formatNode, less func(a, b *formatNode) bool) {
+	if n == nil {
+		return
+	}
+	groups, rest := groupChildren(n.children)
+	sort.SliceStable(groups, func(i, j int) bool { return less(groups[i].element, groups[j].element) })
+	var out []*formatNode
+	for _, g := range groups {
+		out = append(out, g.nodes...)
+	}
+	n.children = append(out, rest...)
+}
+
+func writeFormatNode(w *bytes.Buffer, n *formatNode, indent string, depth int) {
+	if n.comment {
+		w.WriteString("<!--" + n.text + "-->")
+		return
+	}
+	if n.start == nil {
+		escapeFormatText(w, n.text)
+		return
+	}
+
+	name := formatName(n.start.Name)
+	w.WriteString("<" + name)
+	for _, a := range n.start.Attr {
+		w.WriteString(" " + formatName(a.Name) + `="`)
+		escapeFormatText(w, a.Value)
+		w.WriteString(`"`)
+	}
+
+	// Elements holding nothing but line breaks and indentation are empty.
+	var elements, text, lines bool
+	for _, c := range n.children {
+		switch {
+		case c.start != nil || c.comment:
+			elements = true
+		case !c.isSpace():
+			text = true
+		case strings.Contains(c.text, "\n"):
+			lines = true
+		}
+	}
+	switch {
+	case n.opaque && strings.TrimSpace(string(n.raw)) == "", !n.opaque && (len(n.children) == 0 || !elements && !text && lines):
+		w.WriteString("/>")
+	case n.opaque:
+		w.WriteString(">")
+		w.Write(n.raw)
+		w.WriteString("</" + name + ">")
+	case !elements || text:
+		// Text, or mixed content, is written as is.
+		w.WriteString(">")
+		for _, c := range n.children {
+			writeFormatNode(w, c, indent, depth+1)
+		}
+		w.WriteString("</" + name + ">")
+	default:
+		w.WriteString(">")
+		for _, c := range n.children {
+			switch {
+			case c.isSpace():
+				continue
+			case c.trailing:
+				w.WriteString(" ")
+			default:
+				w.WriteString("\n" + strings.Repeat(indent, depth+1))
+			}
+			writeFormatNode(w, c, indent, depth+1)
+		}
+		w.WriteString("\n" + strings.Repeat(indent, depth) + "</" + name + ">")
+	}
+}
+
+func formatName(name xml.Name) string {
+	if name.Space != "" {
+		return name.Space + ":" + name.Local
+	}
+	return name.Local
+}
+
+// escapeFormatText escapes s like the XML encoder does, keeping newlines.
+func escapeFormatText(w *bytes.Buffer, s string) {
+	var b bytes.Buffer
+	_ = xml.EscapeText(&b, []byte(s))
+	w.Write(bytes.ReplaceAll(b.Bytes(), []byte("&#xA;"), []byte("\n")))
+}
@@ -0,0 +1,113 @@
+package gopom
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormat(t *testing.T) {
+	p, err := Parse("testdata/format/pom.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		name string
+		opts FormatOptions
+	}{
+		{name: "canonical", opts: FormatOptions{}},
+		{name: "sorted", opts: FormatOptions{Indent: "  ", SortDependencies: true, SortProperties: true, SortModules: true, Configuration: true}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			want, err := os.ReadFile("testdata/format/" + test.name + ".xml")
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := p.Format(test.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, string(want), string(got))
+
+			// Formatting is idempotent.
+			formatted, err := ParseReader(strings.NewReader(string(got)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			again, err := formatted.Format(test.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, string(got), string(again))
+		})
+	}
+}
+
+func TestFormatKeepsConfiguration(t *testing.T) {
+	p, err := Parse("testdata/format/pom.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw := (*p.Build.Plugins)[0].Configuration.RawConfiguration
+	b, err := p.Format(FormatOptions{Indent: "\t"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Contains(t, string(b), "\t\t\t\t<configuration>"+raw+"</configuration>\n")
+	// The project is left alone.
+	assert.Equal(t, "web", (*p.Modules)[0])
+}
+
+func TestFormatDependencyManagement(t *testing.T) {
+	p := &Project{DependencyManagement: &DependencyManagement{Dependencies: &[]Dependency{
+		{GroupID: "org.b", ArtifactID: "b"},
+		{GroupID: "org.a", ArtifactID: "a", Classifier: "tests"},
+		{GroupID: "org.a", ArtifactID: "a"},
+	}}}
+	b, err := p.Format(FormatOptions{SortDependencyManagement: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := strings.Index(string(b), "<groupId>org.a</groupId>")
+	tests := strings.Index(string(b), "<classifier>tests</classifier>")
+	org := strings.Index(string(b), "<groupId>org.b</groupId>")
+	assert.True(t, a < tests && tests < org, string(b))
+}
+
+func TestFormatChanges(t *testing.T) {
+	p, err := Parse("testdata/format/pom.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Version = "1.1"
+	*p.Dependencies = append(*p.Dependencies, Dependency{Version: "1.0", ArtifactID: "b", GroupID: "org.b"})
+	b, err := p.Format(FormatOptions{Indent: "  "})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Contains(t, string(b), "-->\n<project")
+	assert.Contains(t, string(b), "  <version>1.1</version>\n")
+	assert.Contains(t, string(b), "    <dependency>\n      <groupId>org.b</groupId>\n      <artifactId>b</artifactId>\n      <version>1.0</version>\n    </dependency>\n  </dependencies>\n")
+}
+
+func TestFormatElementOrder(t *testing.T) {
+	p, err := ParseReader(strings.NewReader(`<project>
+  <dependencies>
+    <dependency>
+      <version>1.0</version> <!-- pinned -->
+      <artifactId>a</artifactId>
+      <groupId>org.a</groupId>
+    </dependency>
+  </dependencies>
+</project>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := p.Format(FormatOptions{Indent: "  "})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Contains(t, string(b), "      <groupId>org.a</groupId>\n      <artifactId>a</artifactId>\n      <version>1.0</version> <!-- pinned -->\n")
+}
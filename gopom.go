@@ -7,20 +7,38 @@ import (
 	"os"
 )
 
+// Parse parses the POM file at path. Files named .yaml or .yml are read in
+// the polyglot YAML format, see ParsePolyglotYAML.
 func Parse(path string) (*Project, error) {
-	file, err := os.Open(path)
+	b, err := os.ReadFile(path)
 	if err != nil {
 		return nil, err
 	}
-	defer file.Close()
+	return parseFile(path, b)
+}
+
+// ParseReader parses the POM read from r, the same way Parse does for
+// files.
+func ParseReader(r io.Reader) (*Project, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return parse(b)
+}
 
-	b, _ := io.ReadAll(file)
+// parse unmarshals the POM in b, attaches the comments found next to the
+// elements that support them and records where the elements are.
+func parse(b []byte) (*Project, error) {
 	var project Project
 
-	err = xml.Unmarshal(b, &project)
+	err := xml.Unmarshal(b, &project)
 	if err != nil {
 		return nil, err
 	}
+	if err := scan(b, &project); err != nil {
+		return nil, err
+	}
 	return &project, nil
 }
 
@@ -34,7 +52,7 @@ func (p *Project) Marshal() ([]byte, error) {
 	p.XsiNS = p.Xsi
 	p.SchemaLocation = ""
 	p.Xsi = ""
-	marshalled, err := xml.MarshalIndent(p, "", "    ")
+	marshalled, err := marshalIndent("", "    ", func(e *xml.Encoder) error { return e.Encode(p) })
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal: %w", err)
 	}
@@ -43,50 +61,53 @@ func (p *Project) Marshal() ([]byte, error) {
 }
 
 type Project struct {
-	XMLName xml.Name `xml:"project,omitempty"`
-	Xmlns   string   `xml:"xmlns,attr"`
-	Xsi     string   `xml:"xsi,attr,omitempty"`
+	XMLName xml.Name `xml:"project,omitempty" json:"-" yaml:"-"`
+	Xmlns   string   `xml:"xmlns,attr" json:"xmlns,omitempty" yaml:"xmlns,omitempty"`
+	Xsi     string   `xml:"xsi,attr,omitempty" json:"xsi,omitempty" yaml:"xsi,omitempty"`
 	// This is the variant of the above. In the POM, it's `xmlns:xsi`,
 	// it gets parsed into `xsi`, so we have this so that when we
 	// marshal the attribute has the right name. I'm sure it's something that's
 	// wrong, but I don't know how to fix this.
 	// Previous version had entirely separate struct for Marshalling,
-	XsiNS          string `xml:"xmlns:xsi,attr,omitempty"`
-	SchemaLocation string `xml:"schemaLocation,attr,omitempty"`
+	XsiNS          string `xml:"xmlns:xsi,attr,omitempty" json:"-" yaml:"-"`
+	SchemaLocation string `xml:"schemaLocation,attr,omitempty" json:"schemaLocation,omitempty" yaml:"schemaLocation,omitempty"`
 	// This is the variant of the above. In the POM, it's `xsi:schemaLocation`,
 	// it gets parsed into `schemaLocation`, so we have this so that when we
 	// marshal the attribute has the right name. I'm sure it's something that's
 	// wrong, but I don't know how to fix this.
-	SchemaLocationXSI      string                  `xml:"xsi:schemaLocation,attr,omitempty"`
-	ModelVersion           string                  `xml:"modelVersion,omitempty"`
-	GroupID                string                  `xml:"groupId,omitempty"`
-	ArtifactID             string                  `xml:"artifactId,omitempty"`
-	Version                string                  `xml:"version,omitempty"`
-	Packaging              string                  `xml:"packaging,omitempty"`
-	Name                   string                  `xml:"name,omitempty"`
-	Description            string                  `xml:"description,omitempty"`
-	URL                    string                  `xml:"url,omitempty"`
-	InceptionYear          string                  `xml:"inceptionYear,omitempty"`
-	Organization           *Organization           `xml:"organization,omitempty"`
-	Licenses               *[]License              `xml:"licenses>license,omitempty"`
-	Developers             *[]Developer            `xml:"developers>developer,omitempty"`
-	Contributors           *[]Contributor          `xml:"contributors>contributor,omitempty"`
-	MailingLists           *[]MailingList          `xml:"mailingLists>mailingList,omitempty"`
-	Prerequisites          *Prerequisites          `xml:"prerequisites,omitempty"`
-	Properties             *Properties             `xml:"properties,omitempty"`
-	Parent                 *Parent                 `xml:"parent,omitempty"`
-	Modules                *[]string               `xml:"modules>module,omitempty"`
-	SCM                    *Scm                    `xml:"scm,omitempty"`
-	IssueManagement        *IssueManagement        `xml:"issueManagement,omitempty"`
-	CIManagement           *CIManagement           `xml:"ciManagement,omitempty"`
-	DistributionManagement *DistributionManagement `xml:"distributionManagement,omitempty"`
-	DependencyManagement   *DependencyManagement   `xml:"dependencyManagement,omitempty"`
-	Dependencies           *[]Dependency           `xml:"dependencies>dependency,omitempty"`
-	Repositories           *[]Repository           `xml:"repositories>repository,omitempty"`
-	PluginRepositories     *[]PluginRepository     `xml:"pluginRepositories>pluginRepository,omitempty"`
-	Build                  *Build                  `xml:"build,omitempty"`
-	Reporting              *Reporting              `xml:"reporting,omitempty"`
-	Profiles               *[]Profile              `xml:"profiles>profile,omitempty"`
+	SchemaLocationXSI      string                  `xml:"xsi:schemaLocation,attr,omitempty" json:"-" yaml:"-"`
+	ModelVersion           string                  `xml:"modelVersion,omitempty" json:"modelVersion,omitempty" yaml:"modelVersion,omitempty"`
+	GroupID                string                  `xml:"groupId,omitempty" json:"groupId,omitempty" yaml:"groupId,omitempty"`
+	ArtifactID             string                  `xml:"artifactId,omitempty" json:"artifactId,omitempty" yaml:"artifactId,omitempty"`
+	Version                string                  `xml:"version,omitempty" json:"version,omitempty" yaml:"version,omitempty"`
+	Packaging              string                  `xml:"packaging,omitempty" json:"packaging,omitempty" yaml:"packaging,omitempty"`
+	Name                   string                  `xml:"name,omitempty" json:"name,omitempty" yaml:"name,omitempty"`
+	Description            string                  `xml:"description,omitempty" json:"description,omitempty" yaml:"description,omitempty"`
+	URL                    string                  `xml:"url,omitempty" json:"url,omitempty" yaml:"url,omitempty"`
+	InceptionYear          string                  `xml:"inceptionYear,omitempty" json:"inceptionYear,omitempty" yaml:"inceptionYear,omitempty"`
+	Organization           *Organization           `xml:"organization,omitempty" json:"organization,omitempty" yaml:"organization,omitempty"`
+	Licenses               *[]License              `xml:"licenses>license,omitempty" json:"licenses,omitempty" yaml:"licenses,omitempty"`
+	Developers             *[]Developer            `xml:"developers>developer,omitempty" json:"developers,omitempty" yaml:"developers,omitempty"`
+	Contributors           *[]Contributor          `xml:"contributors>contributor,omitempty" json:"contributors,omitempty" yaml:"contributors,omitempty"`
+	MailingLists           *[]MailingList          `xml:"mailingLists>mailingList,omitempty" json:"mailingLists,omitempty" yaml:"mailingLists,omitempty"`
+	Prerequisites          *Prerequisites          `xml:"prerequisites,omitempty" json:"prerequisites,omitempty" yaml:"prerequisites,omitempty"`
+	Properties             *Properties             `xml:"properties,omitempty" json:"properties,omitempty" yaml:"properties,omitempty"`
+	Parent                 *Parent                 `xml:"parent,omitempty" json:"parent,omitempty" yaml:"parent,omitempty"`
+	Modules                *[]string               `xml:"modules>module,omitempty" json:"modules,omitempty" yaml:"modules,omitempty"`
+	SCM                    *Scm                    `xml:"scm,omitempty" json:"scm,omitempty" yaml:"scm,omitempty"`
+	IssueManagement        *IssueManagement        `xml:"issueManagement,omitempty" json:"issueManagement,omitempty" yaml:"issueManagement,omitempty"`
+	CIManagement           *CIManagement           `xml:"ciManagement,omitempty" json:"ciManagement,omitempty" yaml:"ciManagement,omitempty"`
+	DistributionManagement *DistributionManagement `xml:"distributionManagement,omitempty" json:"distributionManagement,omitempty" yaml:"distributionManagement,omitempty"`
+	DependencyManagement   *DependencyManagement   `xml:"dependencyManagement,omitempty" json:"dependencyManagement,omitempty" yaml:"dependencyManagement,omitempty"`
+	Dependencies           *[]Dependency           `xml:"dependencies>dependency,omitempty" json:"dependencies,omitempty" yaml:"dependencies,omitempty"`
+	Repositories           *[]Repository           `xml:"repositories>repository,omitempty" json:"repositories,omitempty" yaml:"repositories,omitempty"`
+	PluginRepositories     *[]PluginRepository     `xml:"pluginRepositories>pluginRepository,omitempty" json:"pluginRepositories,omitempty" yaml:"pluginRepositories,omitempty"`
+	Build                  *Build                  `xml:"build,omitempty" json:"build,omitempty" yaml:"build,omitempty"`
+	Reporting              *Reporting              `xml:"reporting,omitempty" json:"reporting,omitempty" yaml:"reporting,omitempty"`
+	Profiles               *[]Profile              `xml:"profiles>profile,omitempty" json:"profiles,omitempty" yaml:"profiles,omitempty"`
+
+	// source locates the elements in the parsed POM, if any.
+	source *sourceIndex
 }
 
 type Properties struct {
@@ -95,6 +116,8 @@ type Properties struct {
 	// order. I'm sure there's a better way to do this, but this will suffice
 	// for now.
 	Order []string
+
+	comments map[string]*Comments
 }
 
 func (p *Properties) UnmarshalXML(d *xml.Decoder, start xml.StartElement) (err error) {
@@ -118,213 +141,221 @@ func (p *Properties) UnmarshalXML(d *xml.Decoder, start xml.StartElement) (err e
 }
 
 func (p Properties) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
-	tokens := []xml.Token{start}
-
-	for _, name := range p.Order {
-		t := xml.StartElement{Name: xml.Name{"", name}}
-		tokens = append(tokens, t, xml.CharData(p.Entries[name]), xml.EndElement{t.Name})
+	if err := e.EncodeToken(start); err != nil {
+		return err
 	}
 
-	tokens = append(tokens, xml.EndElement{start.Name})
-
-	for _, t := range tokens {
-		err := e.EncodeToken(t)
-		if err != nil {
+	for _, name := range p.Order {
+		c := p.comments[name]
+		if err := encodeLeading(e, c); err != nil {
 			return err
 		}
+		t := xml.StartElement{Name: xml.Name{Local: name}}
+		tokens := append([]xml.Token{t, xml.CharData(p.Entries[name]), xml.EndElement{Name: t.Name}}, c.trailingTokens()...)
+		for _, t := range tokens {
+			if err := e.EncodeToken(t); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := e.EncodeToken(xml.EndElement{Name: start.Name}); err != nil {
+		return err
 	}
 
 	return e.Flush()
 }
 
 type Parent struct {
-	GroupID      string `xml:"groupId,omitempty"`
-	ArtifactID   string `xml:"artifactId,omitempty"`
-	Version      string `xml:"version,omitempty"`
-	RelativePath string `xml:"relativePath,omitempty"`
+	GroupID      string `xml:"groupId,omitempty" json:"groupId,omitempty" yaml:"groupId,omitempty"`
+	ArtifactID   string `xml:"artifactId,omitempty" json:"artifactId,omitempty" yaml:"artifactId,omitempty"`
+	Version      string `xml:"version,omitempty" json:"version,omitempty" yaml:"version,omitempty"`
+	RelativePath string `xml:"relativePath,omitempty" json:"relativePath,omitempty" yaml:"relativePath,omitempty"`
 }
 
 type Organization struct {
-	Name string `xml:"name,omitempty"`
-	URL  string `xml:"url,omitempty"`
+	Name string `xml:"name,omitempty" json:"name,omitempty" yaml:"name,omitempty"`
+	URL  string `xml:"url,omitempty" json:"url,omitempty" yaml:"url,omitempty"`
 }
 
 type License struct {
-	Name         string `xml:"name,omitempty"`
-	URL          string `xml:"url,omitempty"`
-	Distribution string `xml:"distribution,omitempty"`
-	Comments     string `xml:"comments,omitempty"`
+	Name         string `xml:"name,omitempty" json:"name,omitempty" yaml:"name,omitempty"`
+	URL          string `xml:"url,omitempty" json:"url,omitempty" yaml:"url,omitempty"`
+	Distribution string `xml:"distribution,omitempty" json:"distribution,omitempty" yaml:"distribution,omitempty"`
+	Comments     string `xml:"comments,omitempty" json:"comments,omitempty" yaml:"comments,omitempty"`
 }
 
 type Developer struct {
-	ID              string      `xml:"id,omitempty"`
-	Name            string      `xml:"name,omitempty"`
-	Email           string      `xml:"email,omitempty"`
-	URL             string      `xml:"url,omitempty"`
-	Organization    string      `xml:"organization,omitempty"`
-	OrganizationURL string      `xml:"organizationUrl,omitempty"`
-	Roles           *[]string   `xml:"roles>role,omitempty"`
-	Timezone        string      `xml:"timezone,omitempty"`
-	Properties      *Properties `xml:"properties,omitempty"`
+	ID              string      `xml:"id,omitempty" json:"id,omitempty" yaml:"id,omitempty"`
+	Name            string      `xml:"name,omitempty" json:"name,omitempty" yaml:"name,omitempty"`
+	Email           string      `xml:"email,omitempty" json:"email,omitempty" yaml:"email,omitempty"`
+	URL             string      `xml:"url,omitempty" json:"url,omitempty" yaml:"url,omitempty"`
+	Organization    string      `xml:"organization,omitempty" json:"organization,omitempty" yaml:"organization,omitempty"`
+	OrganizationURL string      `xml:"organizationUrl,omitempty" json:"organizationUrl,omitempty" yaml:"organizationUrl,omitempty"`
+	Roles           *[]string   `xml:"roles>role,omitempty" json:"roles,omitempty" yaml:"roles,omitempty"`
+	Timezone        string      `xml:"timezone,omitempty" json:"timezone,omitempty" yaml:"timezone,omitempty"`
+	Properties      *Properties `xml:"properties,omitempty" json:"properties,omitempty" yaml:"properties,omitempty"`
 }
 
 type Contributor struct {
-	Name            string      `xml:"name,omitempty"`
-	Email           string      `xml:"email,omitempty"`
-	URL             string      `xml:"url,omitempty"`
-	Organization    string      `xml:"organization,omitempty"`
-	OrganizationURL string      `xml:"organizationUrl,omitempty"`
-	Roles           *[]string   `xml:"roles>role,omitempty"`
-	Timezone        string      `xml:"timezone,omitempty"`
-	Properties      *Properties `xml:"properties,omitempty"`
+	Name            string      `xml:"name,omitempty" json:"name,omitempty" yaml:"name,omitempty"`
+	Email           string      `xml:"email,omitempty" json:"email,omitempty" yaml:"email,omitempty"`
+	URL             string      `xml:"url,omitempty" json:"url,omitempty" yaml:"url,omitempty"`
+	Organization    string      `xml:"organization,omitempty" json:"organization,omitempty" yaml:"organization,omitempty"`
+	OrganizationURL string      `xml:"organizationUrl,omitempty" json:"organizationUrl,omitempty" yaml:"organizationUrl,omitempty"`
+	Roles           *[]string   `xml:"roles>role,omitempty" json:"roles,omitempty" yaml:"roles,omitempty"`
+	Timezone        string      `xml:"timezone,omitempty" json:"timezone,omitempty" yaml:"timezone,omitempty"`
+	Properties      *Properties `xml:"properties,omitempty" json:"properties,omitempty" yaml:"properties,omitempty"`
 }
 
 type MailingList struct {
-	Name          string    `xml:"name,omitempty"`
-	Subscribe     string    `xml:"subscribe,omitempty"`
-	Unsubscribe   string    `xml:"unsubscribe,omitempty"`
-	Post          string    `xml:"post,omitempty"`
-	Archive       string    `xml:"archive,omitempty"`
-	OtherArchives *[]string `xml:"otherArchives>otherArchive,omitempty"`
+	Name          string    `xml:"name,omitempty" json:"name,omitempty" yaml:"name,omitempty"`
+	Subscribe     string    `xml:"subscribe,omitempty" json:"subscribe,omitempty" yaml:"subscribe,omitempty"`
+	Unsubscribe   string    `xml:"unsubscribe,omitempty" json:"unsubscribe,omitempty" yaml:"unsubscribe,omitempty"`
+	Post          string    `xml:"post,omitempty" json:"post,omitempty" yaml:"post,omitempty"`
+	Archive       string    `xml:"archive,omitempty" json:"archive,omitempty" yaml:"archive,omitempty"`
+	OtherArchives *[]string `xml:"otherArchives>otherArchive,omitempty" json:"otherArchives,omitempty" yaml:"otherArchives,omitempty"`
 }
 
 type Prerequisites struct {
-	Maven string `xml:"maven,omitempty"`
+	Maven string `xml:"maven,omitempty" json:"maven,omitempty" yaml:"maven,omitempty"`
 }
 
 type Scm struct {
-	Connection          string `xml:"connection,omitempty"`
-	DeveloperConnection string `xml:"developerConnection,omitempty"`
-	Tag                 string `xml:"tag,omitempty"`
-	URL                 string `xml:"url,omitempty"`
+	Connection          string `xml:"connection,omitempty" json:"connection,omitempty" yaml:"connection,omitempty"`
+	DeveloperConnection string `xml:"developerConnection,omitempty" json:"developerConnection,omitempty" yaml:"developerConnection,omitempty"`
+	Tag                 string `xml:"tag,omitempty" json:"tag,omitempty" yaml:"tag,omitempty"`
+	URL                 string `xml:"url,omitempty" json:"url,omitempty" yaml:"url,omitempty"`
 }
 
 type IssueManagement struct {
-	System string `xml:"system,omitempty"`
-	URL    string `xml:"url,omitempty"`
+	System string `xml:"system,omitempty" json:"system,omitempty" yaml:"system,omitempty"`
+	URL    string `xml:"url,omitempty" json:"url,omitempty" yaml:"url,omitempty"`
 }
 
 type CIManagement struct {
-	System    string      `xml:"system,omitempty"`
-	URL       string      `xml:"url,omitempty"`
-	Notifiers *[]Notifier `xml:"notifiers>notifier,omitempty"`
+	System    string      `xml:"system,omitempty" json:"system,omitempty" yaml:"system,omitempty"`
+	URL       string      `xml:"url,omitempty" json:"url,omitempty" yaml:"url,omitempty"`
+	Notifiers *[]Notifier `xml:"notifiers>notifier,omitempty" json:"notifiers,omitempty" yaml:"notifiers,omitempty"`
 }
 
 type Notifier struct {
-	Type          string         `xml:"type,omitempty"`
-	SendOnError   bool           `xml:"sendOnError,omitempty"`
-	SendOnFailure bool           `xml:"sendOnFailure,omitempty"`
-	SendOnSuccess bool           `xml:"sendOnSuccess,omitempty"`
-	SendOnWarning bool           `xml:"sendOnWarning,omitempty"`
-	Address       string         `xml:"address,omitempty"`
-	Configuration *Configuration `xml:"configuration,omitempty"`
+	Type          string         `xml:"type,omitempty" json:"type,omitempty" yaml:"type,omitempty"`
+	SendOnError   bool           `xml:"sendOnError,omitempty" json:"sendOnError,omitempty" yaml:"sendOnError,omitempty"`
+	SendOnFailure bool           `xml:"sendOnFailure,omitempty" json:"sendOnFailure,omitempty" yaml:"sendOnFailure,omitempty"`
+	SendOnSuccess bool           `xml:"sendOnSuccess,omitempty" json:"sendOnSuccess,omitempty" yaml:"sendOnSuccess,omitempty"`
+	SendOnWarning bool           `xml:"sendOnWarning,omitempty" json:"sendOnWarning,omitempty" yaml:"sendOnWarning,omitempty"`
+	Address       string         `xml:"address,omitempty" json:"address,omitempty" yaml:"address,omitempty"`
+	Configuration *Configuration `xml:"configuration,omitempty" json:"configuration,omitempty" yaml:"configuration,omitempty"`
 }
 
 type DistributionManagement struct {
-	Repository         *Repository `xml:"repository,omitempty"`
-	SnapshotRepository *Repository `xml:"snapshotRepository,omitempty"`
-	Site               *Site       `xml:"site,omitempty"`
-	DownloadURL        string      `xml:"downloadUrl,omitempty"`
-	Relocation         *Relocation `xml:"relocation,omitempty"`
-	Status             string      `xml:"status,omitempty"`
+	Repository         *Repository `xml:"repository,omitempty" json:"repository,omitempty" yaml:"repository,omitempty"`
+	SnapshotRepository *Repository `xml:"snapshotRepository,omitempty" json:"snapshotRepository,omitempty" yaml:"snapshotRepository,omitempty"`
+	Site               *Site       `xml:"site,omitempty" json:"site,omitempty" yaml:"site,omitempty"`
+	DownloadURL        string      `xml:"downloadUrl,omitempty" json:"downloadUrl,omitempty" yaml:"downloadUrl,omitempty"`
+	Relocation         *Relocation `xml:"relocation,omitempty" json:"relocation,omitempty" yaml:"relocation,omitempty"`
+	Status             string      `xml:"status,omitempty" json:"status,omitempty" yaml:"status,omitempty"`
 }
 
 type Site struct {
-	ID   string `xml:"id,omitempty"`
-	Name string `xml:"name,omitempty"`
-	URL  string `xml:"url,omitempty"`
+	ID   string `xml:"id,omitempty" json:"id,omitempty" yaml:"id,omitempty"`
+	Name string `xml:"name,omitempty" json:"name,omitempty" yaml:"name,omitempty"`
+	URL  string `xml:"url,omitempty" json:"url,omitempty" yaml:"url,omitempty"`
 }
 
 type Relocation struct {
-	GroupID    string `xml:"groupId,omitempty"`
-	ArtifactID string `xml:"artifactId,omitempty"`
-	Version    string `xml:"version,omitempty"`
-	Message    string `xml:"message,omitempty"`
+	GroupID    string `xml:"groupId,omitempty" json:"groupId,omitempty" yaml:"groupId,omitempty"`
+	ArtifactID string `xml:"artifactId,omitempty" json:"artifactId,omitempty" yaml:"artifactId,omitempty"`
+	Version    string `xml:"version,omitempty" json:"version,omitempty" yaml:"version,omitempty"`
+	Message    string `xml:"message,omitempty" json:"message,omitempty" yaml:"message,omitempty"`
 }
 
 type DependencyManagement struct {
-	Dependencies *[]Dependency `xml:"dependencies>dependency,omitempty"`
+	Dependencies *[]Dependency `xml:"dependencies>dependency,omitempty" json:"dependencies,omitempty" yaml:"dependencies,omitempty"`
 }
 
 type Dependency struct {
-	GroupID    string       `xml:"groupId,omitempty"`
-	ArtifactID string       `xml:"artifactId,omitempty"`
-	Version    string       `xml:"version,omitempty"`
-	Type       string       `xml:"type,omitempty"`
-	Classifier string       `xml:"classifier,omitempty"`
-	Scope      string       `xml:"scope,omitempty"`
-	SystemPath string       `xml:"systemPath,omitempty"`
-	Exclusions *[]Exclusion `xml:"exclusions>exclusion,omitempty"`
-	Optional   string       `xml:"optional,omitempty"`
+	GroupID    string       `xml:"groupId,omitempty" json:"groupId,omitempty" yaml:"groupId,omitempty"`
+	ArtifactID string       `xml:"artifactId,omitempty" json:"artifactId,omitempty" yaml:"artifactId,omitempty"`
+	Version    string       `xml:"version,omitempty" json:"version,omitempty" yaml:"version,omitempty"`
+	Type       string       `xml:"type,omitempty" json:"type,omitempty" yaml:"type,omitempty"`
+	Classifier string       `xml:"classifier,omitempty" json:"classifier,omitempty" yaml:"classifier,omitempty"`
+	Scope      string       `xml:"scope,omitempty" json:"scope,omitempty" yaml:"scope,omitempty"`
+	SystemPath string       `xml:"systemPath,omitempty" json:"systemPath,omitempty" yaml:"systemPath,omitempty"`
+	Exclusions *[]Exclusion `xml:"exclusions>exclusion,omitempty" json:"exclusions,omitempty" yaml:"exclusions,omitempty"`
+	Optional   string       `xml:"optional,omitempty" json:"optional,omitempty" yaml:"optional,omitempty"`
+
+	comments *Comments
 }
 
 type Exclusion struct {
-	GroupID    string `xml:"groupId,omitempty"`
-	ArtifactID string `xml:"artifactId,omitempty"`
+	GroupID    string `xml:"groupId,omitempty" json:"groupId,omitempty" yaml:"groupId,omitempty"`
+	ArtifactID string `xml:"artifactId,omitempty" json:"artifactId,omitempty" yaml:"artifactId,omitempty"`
 }
 
 type Repository struct {
-	UniqueVersion bool              `xml:"uniqueVersion,omitempty"`
-	Releases      *RepositoryPolicy `xml:"releases,omitempty"`
-	Snapshots     *RepositoryPolicy `xml:"snapshots,omitempty"`
-	ID            string            `xml:"id,omitempty"`
-	Name          string            `xml:"name,omitempty"`
-	URL           string            `xml:"url,omitempty"`
-	Layout        string            `xml:"layout,omitempty"`
+	UniqueVersion bool              `xml:"uniqueVersion,omitempty" json:"uniqueVersion,omitempty" yaml:"uniqueVersion,omitempty"`
+	Releases      *RepositoryPolicy `xml:"releases,omitempty" json:"releases,omitempty" yaml:"releases,omitempty"`
+	Snapshots     *RepositoryPolicy `xml:"snapshots,omitempty" json:"snapshots,omitempty" yaml:"snapshots,omitempty"`
+	ID            string            `xml:"id,omitempty" json:"id,omitempty" yaml:"id,omitempty"`
+	Name          string            `xml:"name,omitempty" json:"name,omitempty" yaml:"name,omitempty"`
+	URL           string            `xml:"url,omitempty" json:"url,omitempty" yaml:"url,omitempty"`
+	Layout        string            `xml:"layout,omitempty" json:"layout,omitempty" yaml:"layout,omitempty"`
 }
 
 type RepositoryPolicy struct {
-	Enabled        string `xml:"enabled,omitempty"`
-	UpdatePolicy   string `xml:"updatePolicy,omitempty"`
-	ChecksumPolicy string `xml:"checksumPolicy,omitempty"`
+	Enabled        string `xml:"enabled,omitempty" json:"enabled,omitempty" yaml:"enabled,omitempty"`
+	UpdatePolicy   string `xml:"updatePolicy,omitempty" json:"updatePolicy,omitempty" yaml:"updatePolicy,omitempty"`
+	ChecksumPolicy string `xml:"checksumPolicy,omitempty" json:"checksumPolicy,omitempty" yaml:"checksumPolicy,omitempty"`
 }
 
 type PluginRepository struct {
-	Releases  *RepositoryPolicy `xml:"releases,omitempty"`
-	Snapshots *RepositoryPolicy `xml:"snapshots,omitempty"`
-	ID        string            `xml:"id,omitempty"`
-	Name      string            `xml:"name,omitempty"`
-	URL       string            `xml:"url,omitempty"`
-	Layout    string            `xml:"layout,omitempty"`
+	Releases  *RepositoryPolicy `xml:"releases,omitempty" json:"releases,omitempty" yaml:"releases,omitempty"`
+	Snapshots *RepositoryPolicy `xml:"snapshots,omitempty" json:"snapshots,omitempty" yaml:"snapshots,omitempty"`
+	ID        string            `xml:"id,omitempty" json:"id,omitempty" yaml:"id,omitempty"`
+	Name      string            `xml:"name,omitempty" json:"name,omitempty" yaml:"name,omitempty"`
+	URL       string            `xml:"url,omitempty" json:"url,omitempty" yaml:"url,omitempty"`
+	Layout    string            `xml:"layout,omitempty" json:"layout,omitempty" yaml:"layout,omitempty"`
 }
 
 type BuildBase struct {
-	DefaultGoal      string            `xml:"defaultGoal,omitempty"`
-	Resources        *[]Resource       `xml:"resources>resource,omitempty"`
-	TestResources    *[]Resource       `xml:"testResources>testResource,omitempty"`
-	Directory        string            `xml:"directory,omitempty"`
-	FinalName        string            `xml:"finalName,omitempty"`
-	Filters          *[]string         `xml:"filters>filter,omitempty"`
-	PluginManagement *PluginManagement `xml:"pluginManagement,omitempty"`
-	Plugins          *[]Plugin         `xml:"plugins>plugin,omitempty"`
+	DefaultGoal      string            `xml:"defaultGoal,omitempty" json:"defaultGoal,omitempty" yaml:"defaultGoal,omitempty"`
+	Resources        *[]Resource       `xml:"resources>resource,omitempty" json:"resources,omitempty" yaml:"resources,omitempty"`
+	TestResources    *[]Resource       `xml:"testResources>testResource,omitempty" json:"testResources,omitempty" yaml:"testResources,omitempty"`
+	Directory        string            `xml:"directory,omitempty" json:"directory,omitempty" yaml:"directory,omitempty"`
+	FinalName        string            `xml:"finalName,omitempty" json:"finalName,omitempty" yaml:"finalName,omitempty"`
+	Filters          *[]string         `xml:"filters>filter,omitempty" json:"filters,omitempty" yaml:"filters,omitempty"`
+	PluginManagement *PluginManagement `xml:"pluginManagement,omitempty" json:"pluginManagement,omitempty" yaml:"pluginManagement,omitempty"`
+	Plugins          *[]Plugin         `xml:"plugins>plugin,omitempty" json:"plugins,omitempty" yaml:"plugins,omitempty"`
 }
 
 type Build struct {
-	SourceDirectory       string       `xml:"sourceDirectory,omitempty"`
-	ScriptSourceDirectory string       `xml:"scriptSourceDirectory,omitempty"`
-	TestSourceDirectory   string       `xml:"testSourceDirectory,omitempty"`
-	OutputDirectory       string       `xml:"outputDirectory,omitempty"`
-	TestOutputDirectory   string       `xml:"testOutputDirectory,omitempty"`
-	Extensions            *[]Extension `xml:"extensions>extension,omitempty"`
-	BuildBase
+	SourceDirectory       string       `xml:"sourceDirectory,omitempty" json:"sourceDirectory,omitempty" yaml:"sourceDirectory,omitempty"`
+	ScriptSourceDirectory string       `xml:"scriptSourceDirectory,omitempty" json:"scriptSourceDirectory,omitempty" yaml:"scriptSourceDirectory,omitempty"`
+	TestSourceDirectory   string       `xml:"testSourceDirectory,omitempty" json:"testSourceDirectory,omitempty" yaml:"testSourceDirectory,omitempty"`
+	OutputDirectory       string       `xml:"outputDirectory,omitempty" json:"outputDirectory,omitempty" yaml:"outputDirectory,omitempty"`
+	TestOutputDirectory   string       `xml:"testOutputDirectory,omitempty" json:"testOutputDirectory,omitempty" yaml:"testOutputDirectory,omitempty"`
+	Extensions            *[]Extension `xml:"extensions>extension,omitempty" json:"extensions,omitempty" yaml:"extensions,omitempty"`
+	BuildBase             `yaml:",inline"`
 }
 
 type Extension struct {
-	GroupID    string `xml:"groupId,omitempty"`
-	ArtifactID string `xml:"artifactId,omitempty"`
-	Version    string `xml:"version,omitempty"`
+	GroupID    string `xml:"groupId,omitempty" json:"groupId,omitempty" yaml:"groupId,omitempty"`
+	ArtifactID string `xml:"artifactId,omitempty" json:"artifactId,omitempty" yaml:"artifactId,omitempty"`
+	Version    string `xml:"version,omitempty" json:"version,omitempty" yaml:"version,omitempty"`
 }
 
 type Resource struct {
-	TargetPath string    `xml:"targetPath,omitempty"`
-	Filtering  string    `xml:"filtering,omitempty"`
-	Directory  string    `xml:"directory,omitempty"`
-	Includes   *[]string `xml:"includes>include,omitempty"`
-	Excludes   *[]string `xml:"excludes>exclude,omitempty"`
+	TargetPath string    `xml:"targetPath,omitempty" json:"targetPath,omitempty" yaml:"targetPath,omitempty"`
+	Filtering  string    `xml:"filtering,omitempty" json:"filtering,omitempty" yaml:"filtering,omitempty"`
+	Directory  string    `xml:"directory,omitempty" json:"directory,omitempty" yaml:"directory,omitempty"`
+	Includes   *[]string `xml:"includes>include,omitempty" json:"includes,omitempty" yaml:"includes,omitempty"`
+	Excludes   *[]string `xml:"excludes>exclude,omitempty" json:"excludes,omitempty" yaml:"excludes,omitempty"`
 }
 
 type PluginManagement struct {
-	Plugins *[]Plugin `xml:"plugins>plugin,omitempty"`
+	Plugins *[]Plugin `xml:"plugins>plugin,omitempty" json:"plugins,omitempty" yaml:"plugins,omitempty"`
 }
 
 // Configuration is a raw XML configuration that we currently do not muck with.
@@ -339,79 +370,83 @@ type Configuration struct {
 }
 
 type Plugin struct {
-	GroupID       string             `xml:"groupId,omitempty"`
-	ArtifactID    string             `xml:"artifactId,omitempty"`
-	Version       string             `xml:"version,omitempty"`
-	Extensions    string             `xml:"extensions,omitempty"`
-	Executions    *[]PluginExecution `xml:"executions>execution,omitempty"`
-	Dependencies  *[]Dependency      `xml:"dependencies>dependency,omitempty"`
-	Inherited     string             `xml:"inherited,omitempty"`
-	Configuration *Configuration     `xml:"configuration,omitempty"`
+	GroupID       string             `xml:"groupId,omitempty" json:"groupId,omitempty" yaml:"groupId,omitempty"`
+	ArtifactID    string             `xml:"artifactId,omitempty" json:"artifactId,omitempty" yaml:"artifactId,omitempty"`
+	Version       string             `xml:"version,omitempty" json:"version,omitempty" yaml:"version,omitempty"`
+	Extensions    string             `xml:"extensions,omitempty" json:"extensions,omitempty" yaml:"extensions,omitempty"`
+	Executions    *[]PluginExecution `xml:"executions>execution,omitempty" json:"executions,omitempty" yaml:"executions,omitempty"`
+	Dependencies  *[]Dependency      `xml:"dependencies>dependency,omitempty" json:"dependencies,omitempty" yaml:"dependencies,omitempty"`
+	Inherited     string             `xml:"inherited,omitempty" json:"inherited,omitempty" yaml:"inherited,omitempty"`
+	Configuration *Configuration     `xml:"configuration,omitempty" json:"configuration,omitempty" yaml:"configuration,omitempty"`
+
+	comments *Comments
 }
 
 type PluginExecution struct {
-	ID            string         `xml:"id,omitempty"`
-	Phase         string         `xml:"phase,omitempty"`
-	Goals         *[]string      `xml:"goals>goal,omitempty"`
-	Inherited     string         `xml:"inherited,omitempty"`
-	Configuration *Configuration `xml:"configuration,omitempty"`
+	ID            string         `xml:"id,omitempty" json:"id,omitempty" yaml:"id,omitempty"`
+	Phase         string         `xml:"phase,omitempty" json:"phase,omitempty" yaml:"phase,omitempty"`
+	Goals         *[]string      `xml:"goals>goal,omitempty" json:"goals,omitempty" yaml:"goals,omitempty"`
+	Inherited     string         `xml:"inherited,omitempty" json:"inherited,omitempty" yaml:"inherited,omitempty"`
+	Configuration *Configuration `xml:"configuration,omitempty" json:"configuration,omitempty" yaml:"configuration,omitempty"`
 }
 
 type Reporting struct {
-	ExcludeDefaults string             `xml:"excludeDefaults,omitempty"`
-	OutputDirectory string             `xml:"outputDirectory,omitempty"`
-	Plugins         *[]ReportingPlugin `xml:"plugins>plugin,omitempty"`
+	ExcludeDefaults string             `xml:"excludeDefaults,omitempty" json:"excludeDefaults,omitempty" yaml:"excludeDefaults,omitempty"`
+	OutputDirectory string             `xml:"outputDirectory,omitempty" json:"outputDirectory,omitempty" yaml:"outputDirectory,omitempty"`
+	Plugins         *[]ReportingPlugin `xml:"plugins>plugin,omitempty" json:"plugins,omitempty" yaml:"plugins,omitempty"`
 }
 
 type ReportingPlugin struct {
-	GroupID    string       `xml:"groupId,omitempty"`
-	ArtifactID string       `xml:"artifactId,omitempty"`
-	Version    string       `xml:"version,omitempty"`
-	Inherited  string       `xml:"inherited,omitempty"`
-	ReportSets *[]ReportSet `xml:"reportSets>reportSet,omitempty"`
+	GroupID    string       `xml:"groupId,omitempty" json:"groupId,omitempty" yaml:"groupId,omitempty"`
+	ArtifactID string       `xml:"artifactId,omitempty" json:"artifactId,omitempty" yaml:"artifactId,omitempty"`
+	Version    string       `xml:"version,omitempty" json:"version,omitempty" yaml:"version,omitempty"`
+	Inherited  string       `xml:"inherited,omitempty" json:"inherited,omitempty" yaml:"inherited,omitempty"`
+	ReportSets *[]ReportSet `xml:"reportSets>reportSet,omitempty" json:"reportSets,omitempty" yaml:"reportSets,omitempty"`
 }
 
 type ReportSet struct {
-	ID        string    `xml:"id,omitempty"`
-	Reports   *[]string `xml:"reports>report,omitempty"`
-	Inherited string    `xml:"inherited,omitempty"`
+	ID        string    `xml:"id,omitempty" json:"id,omitempty" yaml:"id,omitempty"`
+	Reports   *[]string `xml:"reports>report,omitempty" json:"reports,omitempty" yaml:"reports,omitempty"`
+	Inherited string    `xml:"inherited,omitempty" json:"inherited,omitempty" yaml:"inherited,omitempty"`
 }
 
 type Profile struct {
-	ID                     string                  `xml:"id,omitempty"`
-	Activation             *Activation             `xml:"activation,omitempty"`
-	Build                  *BuildBase              `xml:"build,omitempty"`
-	Modules                *[]string               `xml:"modules>module,omitempty"`
-	DistributionManagement *DistributionManagement `xml:"distributionManagement,omitempty"`
-	Properties             *Properties             `xml:"properties,omitempty"`
-	DependencyManagement   *DependencyManagement   `xml:"dependencyManagement,omitempty"`
-	Dependencies           *[]Dependency           `xml:"dependencies>dependency,omitempty"`
-	Repositories           *[]Repository           `xml:"repositories>repository,omitempty"`
-	PluginRepositories     *[]PluginRepository     `xml:"pluginRepositories>pluginRepository,omitempty"`
-	Reporting              *Reporting              `xml:"reporting,omitempty"`
+	ID                     string                  `xml:"id,omitempty" json:"id,omitempty" yaml:"id,omitempty"`
+	Activation             *Activation             `xml:"activation,omitempty" json:"activation,omitempty" yaml:"activation,omitempty"`
+	Build                  *BuildBase              `xml:"build,omitempty" json:"build,omitempty" yaml:"build,omitempty"`
+	Modules                *[]string               `xml:"modules>module,omitempty" json:"modules,omitempty" yaml:"modules,omitempty"`
+	DistributionManagement *DistributionManagement `xml:"distributionManagement,omitempty" json:"distributionManagement,omitempty" yaml:"distributionManagement,omitempty"`
+	Properties             *Properties             `xml:"properties,omitempty" json:"properties,omitempty" yaml:"properties,omitempty"`
+	DependencyManagement   *DependencyManagement   `xml:"dependencyManagement,omitempty" json:"dependencyManagement,omitempty" yaml:"dependencyManagement,omitempty"`
+	Dependencies           *[]Dependency           `xml:"dependencies>dependency,omitempty" json:"dependencies,omitempty" yaml:"dependencies,omitempty"`
+	Repositories           *[]Repository           `xml:"repositories>repository,omitempty" json:"repositories,omitempty" yaml:"repositories,omitempty"`
+	PluginRepositories     *[]PluginRepository     `xml:"pluginRepositories>pluginRepository,omitempty" json:"pluginRepositories,omitempty" yaml:"pluginRepositories,omitempty"`
+	Reporting              *Reporting              `xml:"reporting,omitempty" json:"reporting,omitempty" yaml:"reporting,omitempty"`
+
+	comments *Comments
 }
 
 type Activation struct {
-	ActiveByDefault bool                `xml:"activeByDefault,omitempty"`
-	JDK             string              `xml:"jdk,omitempty"`
-	OS              *ActivationOS       `xml:"os,omitempty"`
-	Property        *ActivationProperty `xml:"property,omitempty"`
-	File            *ActivationFile     `xml:"file,omitempty"`
+	ActiveByDefault bool                `xml:"activeByDefault,omitempty" json:"activeByDefault,omitempty" yaml:"activeByDefault,omitempty"`
+	JDK             string              `xml:"jdk,omitempty" json:"jdk,omitempty" yaml:"jdk,omitempty"`
+	OS              *ActivationOS       `xml:"os,omitempty" json:"os,omitempty" yaml:"os,omitempty"`
+	Property        *ActivationProperty `xml:"property,omitempty" json:"property,omitempty" yaml:"property,omitempty"`
+	File            *ActivationFile     `xml:"file,omitempty" json:"file,omitempty" yaml:"file,omitempty"`
 }
 
 type ActivationOS struct {
-	Name    string `xml:"name,omitempty"`
-	Family  string `xml:"family,omitempty"`
-	Arch    string `xml:"arch,omitempty"`
-	Version string `xml:"version,omitempty"`
+	Name    string `xml:"name,omitempty" json:"name,omitempty" yaml:"name,omitempty"`
+	Family  string `xml:"family,omitempty" json:"family,omitempty" yaml:"family,omitempty"`
+	Arch    string `xml:"arch,omitempty" json:"arch,omitempty" yaml:"arch,omitempty"`
+	Version string `xml:"version,omitempty" json:"version,omitempty" yaml:"version,omitempty"`
 }
 
 type ActivationProperty struct {
-	Name  string `xml:"name,omitempty"`
-	Value string `xml:"value,omitempty"`
+	Name  string `xml:"name,omitempty" json:"name,omitempty" yaml:"name,omitempty"`
+	Value string `xml:"value,omitempty" json:"value,omitempty" yaml:"value,omitempty"`
 }
 
 type ActivationFile struct {
-	Missing string `xml:"missing,omitempty"`
-	Exists  string `xml:"exists,omitempty"`
+	Missing string `xml:"missing,omitempty" json:"missing,omitempty" yaml:"missing,omitempty"`
+	Exists  string `xml:"exists,omitempty" json:"exists,omitempty" yaml:"exists,omitempty"`
 }
@@ -0,0 +1,235 @@
+package gopom
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// licensesJSON maps SPDX license expressions to the names and URLs
+// commonly found in POMs for them.
+//
+//go:embed licenses.json
+var licensesJSON []byte
+
+// Licensing is the normalized form of a list of licenses.
+type Licensing struct {
+	// Expression is the SPDX license expression of the list. Maven lets
+	// users pick any of the licenses of a project, so they are combined
+	// with OR. Unrecognized licenses appear as LicenseRef identifiers. It
+	// is empty when there are no licenses.
+	Expression string
+	// Licenses are the licenses of the list, in order, skipping those
+	// without a name or URL.
+	Licenses []NormalizedLicense
+}
+
+// NormalizedLicense is a license with its SPDX license expression.
+type NormalizedLicense struct {
+	License
+	// SPDX is the SPDX license expression of the license, or an empty
+	// string when it was not recognized.
+	SPDX string
+}
+
+// Unrecognized returns the licenses that could not be mapped to SPDX
+// identifiers.
+func (l Licensing) Unrecognized() []License {
+	var unrecognized []License
+	for _, nl := range l.Licenses {
+		if nl.SPDX == "" {
+			unrecognized = append(unrecognized, nl.License)
+		}
+	}
+	return unrecognized
+}
+
+// Licensing returns the normalized licenses of the project. Licenses are
+// inherited from the parent, so call it on the effective model to take
+// them into account.
+func (p *Project) Licensing() Licensing {
+	if p.Licenses == nil {
+		return Licensing{}
+	}
+	return NormalizeLicenses(*p.Licenses)
+}
+
+// NormalizeLicenses normalizes every license of the list, see
+// NormalizeLicense, and combines them into a single expression.
+func NormalizeLicenses(licenses []License) Licensing {
+	var l Licensing
+	var terms []string
+	seen := map[string]bool{}
+	for _, license := range licenses {
+		if license.Name == "" && license.URL == "" {
+			continue
+		}
+		id := NormalizeLicense(license)
+		l.Licenses = append(l.Licenses, NormalizedLicense{License: license, SPDX: id})
+		if id == "" {
+			id = licenseRef(license)
+		}
+		if !seen[id] {
+			seen[id] = true
+			terms = append(terms, id)
+		}
+	}
+	if len(terms) > 1 {
+		for i, term := range terms {
+			if strings.Contains(term, " OR ") || strings.Contains(term, " AND ") {
+				terms[i] = "(" + term + ")"
+			}
+		}
+	}
+	l.Expression = strings.Join(terms, " OR ")
+	return l
+}
+
+// NormalizeLicense returns the SPDX license expression of l, recognized
+// from its name or URL using an embedded table, or an empty string. The
+// expression of dual licenses, like CDDL + GPLv2 with classpath exception,
+// has several identifiers. Names that already are SPDX expressions of known
+// licenses are accepted as is.
+func NormalizeLicense(l License) string {
+	t := licenseIndex()
+	if id, ok := t.names[licenseKey(l.Name)]; ok {
+		return id
+	}
+	if id := t.expression(l.Name); id != "" {
+		return id
+	}
+	if id, ok := t.urls[licenseURLKey(l.URL)]; ok {
+		return id
+	}
+	return ""
+}
+
+// licenseRef returns the LicenseRef identifier standing for an
+// unrecognized license.
+func licenseRef(l License) string {
+	name := l.Name
+	if name == "" {
+		name = l.URL
+	}
+	return "LicenseRef-" + spdxIDChars(name)
+}
+
+type licenseTable struct {
+	// names and urls map the keys of license names and URLs to SPDX
+	// expressions, and ids maps lower case SPDX identifiers to their
+	// canonical spelling.
+	names, urls, ids map[string]string
+}
+
+var licenseIndex = sync.OnceValue(func() *licenseTable {
+	t, err := parseLicenseTable(licensesJSON)
+	if err != nil {
+		panic(err)
+	}
+	return t
+})
+
+func parseLicenseTable(b []byte) (*licenseTable, error) {
+	var entries []struct {
+		ID    string   `json:"id"`
+		Names []string `json:"names"`
+		URLs  []string `json:"urls"`
+	}
+	if err := json.Unmarshal(b, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse license table: %w", err)
+	}
+	t := &licenseTable{names: map[string]string{}, urls: map[string]string{}, ids: map[string]string{}}
+	add := func(m map[string]string, key, id string) error {
+		if other, ok := m[key]; ok && other != id {
+			return fmt.Errorf("license table maps %q to both %s and %s", key, other, id)
+		}
+		m[key] = id
+		return nil
+	}
+	for _, e := range entries {
+		for _, token := range strings.Fields(e.ID) {
+			if !isLicenseOperator(token) {
+				t.ids[strings.ToLower(token)] = token
+			}
+		}
+		if err := add(t.names, licenseKey(e.ID), e.ID); err != nil {
+			return nil, err
+		}
+		for _, name := range e.Names {
+			if err := add(t.names, licenseKey(name), e.ID); err != nil {
+				return nil, err
+			}
+		}
+		for _, url := range e.URLs {
+			if err := add(t.urls, licenseURLKey(url), e.ID); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return t, nil
+}
+
+// expression returns s with the canonical spelling of its identifiers and
+// operators if it is an SPDX license expression of known licenses, or an
+// empty string.
+func (t *licenseTable) expression(s string) string {
+	tokens := strings.Fields(strings.NewReplacer("(", " ( ", ")", " ) ").Replace(s))
+	if len(tokens) == 0 {
+		return ""
+	}
+	depth := 0
+	for i, token := range tokens {
+		switch {
+		case token == "(":
+			depth++
+		case token == ")":
+			if depth--; depth < 0 {
+				return ""
+			}
+		case isLicenseOperator(token):
+			tokens[i] = strings.ToUpper(token)
+		default:
+			id, ok := t.ids[strings.ToLower(token)]
+			if !ok {
+				return ""
+			}
+			tokens[i] = id
+		}
+	}
+	if depth != 0 {
+		return ""
+	}
+	return strings.NewReplacer("( ", "(", " )", ")").Replace(strings.Join(tokens, " "))
+}
+
+func isLicenseOperator(token string) bool {
+	switch strings.ToUpper(token) {
+	case "AND", "OR", "WITH":
+		return true
+	}
+	return false
+}
+
+// licenseKey normalizes license names: lower case letters and digits only.
+func licenseKey(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case 'a' <= r && r <= 'z' || '0' <= r && r <= '9':
+			return r
+		case 'A' <= r && r <= 'Z':
+			return r + 'a' - 'A'
+		}
+		return -1
+	}, name)
+}
+
+// licenseURLKey normalizes license URLs: lower case, without scheme, www.
+// prefix, fragment or trailing slash.
+func licenseURLKey(url string) string {
+	url = strings.ToLower(strings.TrimSpace(url))
+	url, _, _ = strings.Cut(url, "#")
+	url = strings.TrimPrefix(strings.TrimPrefix(url, "http://"), "https://")
+	return strings.TrimSuffix(strings.TrimPrefix(url, "www."), "/")
+}
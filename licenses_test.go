@@ -0,0 +1,70 @@
+package gopom
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLicenseTable(t *testing.T) {
+	_, err := parseLicenseTable(licensesJSON)
+	assert.NoError(t, err)
+}
+
+func TestNormalizeLicense(t *testing.T) {
+	for _, test := range []struct {
+		license License
+		want    string
+	}{
+		{License{Name: "The Apache Software License, Version 2.0"}, "Apache-2.0"},
+		{License{Name: "Apache License, Version 2.0"}, "Apache-2.0"},
+		{License{Name: "apache-2.0"}, "Apache-2.0"},
+		{License{URL: "http://www.apache.org/licenses/LICENSE-2.0.txt"}, "Apache-2.0"},
+		{License{Name: "Apache", URL: "https://apache.org/licenses/LICENSE-2.0/"}, "Apache-2.0"},
+		{License{Name: "MIT License"}, "MIT"},
+		{License{Name: "Eclipse Public License v2.0"}, "EPL-2.0"},
+		{License{Name: "New BSD License"}, "BSD-3-Clause"},
+		{License{Name: "CDDL + GPLv2 with classpath exception"}, "CDDL-1.1 OR GPL-2.0-only WITH Classpath-exception-2.0"},
+		{License{Name: "mit or (apache-2.0 and bsd-3-clause)"}, "MIT OR (Apache-2.0 AND BSD-3-Clause)"},
+		{License{Name: "MIT OR (Apache-2.0"}, ""},
+		{License{Name: "MIT OR Proprietary"}, ""},
+		{License{Name: "Proprietary"}, ""},
+		{License{}, ""},
+	} {
+		assert.Equal(t, test.want, NormalizeLicense(test.license), test.license)
+	}
+}
+
+func TestNormalizeLicenses(t *testing.T) {
+	l := NormalizeLicenses([]License{
+		{Name: "Eclipse Public License - v 2.0"},
+		{Name: "GNU General Public License, version 2 with the GNU Classpath Exception"},
+		{Name: "Apache-2.0 OR MIT"},
+		{Name: "EPL 2.0"},
+		{Comments: "no name or URL"},
+		{Name: "Example Commercial License", URL: "https://example.com/license"},
+	})
+	assert.Equal(t, "EPL-2.0 OR GPL-2.0-only WITH Classpath-exception-2.0 OR (Apache-2.0 OR MIT) OR LicenseRef-Example-Commercial-License", l.Expression)
+	assert.Len(t, l.Licenses, 5)
+	assert.Equal(t, "EPL-2.0", l.Licenses[3].SPDX)
+	assert.Equal(t, []License{{Name: "Example Commercial License", URL: "https://example.com/license"}}, l.Unrecognized())
+
+	assert.Equal(t, Licensing{}, NormalizeLicenses(nil))
+}
+
+func TestProjectLicensing(t *testing.T) {
+	const dir = "./testdata/effective/child"
+	p, err := Parse(dir + "/pom.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, Licensing{}, p.Licensing())
+
+	eff, err := Effective(p, EffectiveOptions{Dir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := eff.Licensing()
+	assert.Equal(t, "Apache-2.0", l.Expression)
+	assert.Empty(t, l.Unrecognized())
+}
@@ -0,0 +1,465 @@
+package gopom
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LintRule is a best practice check over projects.
+type LintRule struct {
+	Rule
+	// Check calls report for each problem found in p, with the path of the
+	// offending element in the form used by Problem.
+	Check func(p *Project, report func(path, message string))
+}
+
+// LintConfig configures the rules of Lint, and is usually read from YAML:
+//
+//	rules:
+//	  unused-property: off
+//	  inline-version: warning
+type LintConfig struct {
+	// Rules maps rule IDs to their severity: error, warning or info, or off
+	// to disable the rule.
+	Rules map[string]string `json:"rules,omitempty" yaml:"rules,omitempty"`
+}
+
+// ParseLintConfig reads a lint configuration in YAML.
+func ParseLintConfig(r io.Reader) (*LintConfig, error) {
+	d := yaml.NewDecoder(r)
+	d.KnownFields(true)
+	var config LintConfig
+	if err := d.Decode(&config); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("failed to parse the lint configuration: %w", err)
+	}
+	return &config, nil
+}
+
+// lintSuppression is the comment that suppresses lint problems, for all
+// rules or for the rules listed after it, on the element it precedes or
+// trails and on its descendants. A comment before <project> applies to the
+// whole POM.
+const lintSuppression = "gopom-lint-ignore"
+
+// Lint checks p against rules, which default to LintRules, and returns the
+// problems found in rule order. config, which may be nil, changes the
+// severity of rules or disables them. Problems on elements carrying a
+// gopom-lint-ignore comment, optionally followed by rule IDs, are left out.
+//
+// Lint looks at the POM as written: rules like plugin-version only know
+// about the plugins managed in the same POM.
+func (p *Project) Lint(rules []LintRule, config *LintConfig) ([]Problem, error) {
+	if rules == nil {
+		rules = LintRules()
+	}
+	severities := map[string]Severity{}
+	disabled := map[string]bool{}
+	if config != nil {
+		known := map[string]bool{}
+		for _, r := range rules {
+			known[r.ID] = true
+		}
+		for id, s := range config.Rules {
+			if !known[id] {
+				return nil, fmt.Errorf("unknown lint rule %q", id)
+			}
+			switch s {
+			case "off":
+				disabled[id] = true
+			case "error":
+				severities[id] = SeverityError
+			case "warning":
+				severities[id] = SeverityWarning
+			case "info":
+				severities[id] = SeverityInfo
+			default:
+				return nil, fmt.Errorf("invalid severity %q for lint rule %s", s, id)
+			}
+		}
+	}
+
+	var problems []Problem
+	for _, r := range rules {
+		if disabled[r.ID] {
+			continue
+		}
+		severity, ok := severities[r.ID]
+		if !ok {
+			severity = r.Severity
+		}
+		r.Check(p, func(path, message string) {
+			if !p.lintSuppressed(r.ID, path) {
+				problems = append(problems, Problem{Severity: severity, Path: path, Message: message, Rule: r.ID})
+			}
+		})
+	}
+	return problems, nil
+}
+
+// lintSuppressed tells whether a comment suppresses rule on the element at
+// path or on one of its ancestors.
+func (p *Project) lintSuppressed(rule, path string) bool {
+	if p.source == nil {
+		return false
+	}
+	for {
+		for _, c := range p.source.Comments[path] {
+			fields := strings.FieldsFunc(c, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' || r == '\n' })
+			if len(fields) == 0 || fields[0] != lintSuppression {
+				continue
+			}
+			if len(fields) == 1 {
+				return true
+			}
+			for _, id := range fields[1:] {
+				if id == rule {
+					return true
+				}
+			}
+		}
+		i := strings.LastIndex(path, "/")
+		if i < 0 {
+			return false
+		}
+		path = path[:i]
+	}
+}
+
+// LintRules returns the built-in lint rules.
+func LintRules() []LintRule {
+	return []LintRule{
+		{Rule{ID: "plugin-version", Severity: SeverityWarning, Description: "Plugins should have a version, or a managed one, for reproducible builds."}, lintPluginVersion},
+		{Rule{ID: "snapshot-dependency", Severity: SeverityWarning, Description: "Releases should not depend on SNAPSHOT versions."}, lintSnapshotDependency},
+		{Rule{ID: "duplicate-dependency", Severity: SeverityWarning, Description: "Dependencies should be declared once."}, lintDuplicateDependency},
+		{Rule{ID: "unused-property", Severity: SeverityInfo, Description: "Properties should be used in the POM."}, lintUnusedProperty},
+		{Rule{ID: "redundant-version", Severity: SeverityWarning, Description: "Dependencies should not repeat the version they are managed with."}, lintRedundantVersion},
+		{Rule{ID: "insecure-repository", Severity: SeverityError, Description: "Repositories should use https:// URLs, as Maven blocks http:// ones."}, lintInsecureRepository},
+		{Rule{ID: "system-scope", Severity: SeverityWarning, Description: "Dependencies should not use the deprecated system scope."}, lintSystemScope},
+		{Rule{ID: "deprecated-element", Severity: SeverityWarning, Description: "Deprecated elements and expressions should be replaced."}, lintDeprecatedElement},
+		{Rule{ID: "inline-version", Severity: SeverityInfo, Description: "Dependency versions of projects with a parent or a dependencyManagement section belong in dependencyManagement."}, lintInlineVersion},
+	}
+}
+
+// lintSection is the part of a project, or of one of its profiles, that
+// rules look at.
+type lintSection struct {
+	path               string
+	dependencies       *[]Dependency
+	managed            *[]Dependency
+	build              *BuildBase
+	repositories       *[]Repository
+	pluginRepositories *[]PluginRepository
+	distribution       *DistributionManagement
+	reporting          *Reporting
+	// managedVersions and managedPlugins map the keys of the dependencies
+	// and plugins managed by the project and the profile to their version.
+	managedVersions map[string]string
+	managedPlugins  map[string]string
+}
+
+func lintSections(p *Project) []lintSection {
+	project := lintSection{
+		path:               "project",
+		dependencies:       p.Dependencies,
+		managed:            managedDependencies(p.DependencyManagement),
+		repositories:       p.Repositories,
+		pluginRepositories: p.PluginRepositories,
+		distribution:       p.DistributionManagement,
+		reporting:          p.Reporting,
+	}
+	if p.Build != nil {
+		project.build = &p.Build.BuildBase
+	}
+	project.managedVersions, project.managedPlugins = project.managedKeys(nil, nil)
+	sections := []lintSection{project}
+	if p.Profiles == nil {
+		return sections
+	}
+	for i, profile := range *p.Profiles {
+		s := lintSection{
+			path:               elementPath("project/profiles[0]", "profile", i),
+			dependencies:       profile.Dependencies,
+			managed:            managedDependencies(profile.DependencyManagement),
+			build:              profile.Build,
+			repositories:       profile.Repositories,
+			pluginRepositories: profile.PluginRepositories,
+			distribution:       profile.DistributionManagement,
+			reporting:          profile.Reporting,
+		}
+		s.managedVersions, s.managedPlugins = s.managedKeys(project.managedVersions, project.managedPlugins)
+		sections = append(sections, s)
+	}
+	return sections
+}
+
+// managedKeys adds the dependencies and plugins managed by s to the given
+// ones.
+func (s lintSection) managedKeys(versions, plugins map[string]string) (map[string]string, map[string]string) {
+	v, pl := map[string]string{}, map[string]string{}
+	for k, version := range versions {
+		v[k] = version
+	}
+	for k, version := range plugins {
+		pl[k] = version
+	}
+	for _, d := range derefDependencies(s.managed) {
+		v[dependencyKey(d)] = d.Version
+	}
+	if s.build != nil && s.build.PluginManagement != nil && s.build.PluginManagement.Plugins != nil {
+		for _, p := range *s.build.PluginManagement.Plugins {
+			pl[pluginKey(p)] = p.Version
+		}
+	}
+	return v, pl
+}
+
+// pluginLists returns the plugins and the managed plugins of s, with the
+// path of the list.
+func (s lintSection) pluginLists() (paths []string, lists [][]Plugin) {
+	if s.build == nil {
+		return nil, nil
+	}
+	if s.build.Plugins != nil {
+		paths = append(paths, s.path+"/build[0]/plugins[0]")
+		lists = append(lists, *s.build.Plugins)
+	}
+	if s.build.PluginManagement != nil && s.build.PluginManagement.Plugins != nil {
+		paths = append(paths, s.path+"/build[0]/pluginManagement[0]/plugins[0]")
+		lists = append(lists, *s.build.PluginManagement.Plugins)
+	}
+	return paths, lists
+}
+
+func lintPluginVersion(p *Project, report func(path, message string)) {
+	for _, s := range lintSections(p) {
+		paths, lists := s.pluginLists()
+		for i, plugins := range lists {
+			for j, pl := range plugins {
+				if pl.Version == "" && s.managedPlugins[pluginKey(pl)] == "" {
+					report(elementPath(paths[i], "plugin", j), fmt.Sprintf("Plugin %s has no version.", pluginKey(pl)))
+				}
+			}
+		}
+		if s.reporting == nil || s.reporting.Plugins == nil {
+			continue
+		}
+		for j, pl := range *s.reporting.Plugins {
+			key := pluginKey(Plugin{GroupID: pl.GroupID, ArtifactID: pl.ArtifactID})
+			if pl.Version == "" && s.managedPlugins[key] == "" {
+				report(elementPath(s.path+"/reporting[0]/plugins[0]", "plugin", j), fmt.Sprintf("Plugin %s has no version.", key))
+			}
+		}
+	}
+}
+
+// lintExpand replaces the references to the properties of p in s.
+func lintExpand(p *Project, s string) string {
+	if p.Properties == nil || !strings.Contains(s, "${") {
+		return s
+	}
+	for k, v := range p.Properties.Entries {
+		s = strings.ReplaceAll(s, "${"+k+"}", v)
+	}
+	return s
+}
+
+func lintSnapshotDependency(p *Project, report func(path, message string)) {
+	version := lintExpand(p, effectiveVersion(p))
+	if version == "" || strings.Contains(version, "${") || strings.HasSuffix(version, "-SNAPSHOT") {
+		return
+	}
+	snapshot := func(path, what, v string) {
+		if strings.HasSuffix(lintExpand(p, v), "-SNAPSHOT") {
+			report(path+"/version[0]", fmt.Sprintf("Release %s depends on %s %s.", version, what, lintExpand(p, v)))
+		}
+	}
+	if p.Parent != nil {
+		snapshot("project/parent[0]", "parent "+p.Parent.GroupID+":"+p.Parent.ArtifactID, p.Parent.Version)
+	}
+	forEachDependencyList(p, func(path string, deps []Dependency) {
+		for i, d := range deps {
+			snapshot(elementPath(path, "dependency", i), d.GroupID+":"+d.ArtifactID, d.Version)
+		}
+	})
+	for _, s := range lintSections(p) {
+		paths, lists := s.pluginLists()
+		for i, plugins := range lists {
+			for j, pl := range plugins {
+				snapshot(elementPath(paths[i], "plugin", j), "plugin "+pluginKey(pl), pl.Version)
+			}
+		}
+	}
+}
+
+func lintDuplicateDependency(p *Project, report func(path, message string)) {
+	forEachDependencyList(p, func(path string, deps []Dependency) {
+		seen := map[string]bool{}
+		for i, d := range deps {
+			key := dependencyKey(d)
+			if seen[key] {
+				report(elementPath(path, "dependency", i), fmt.Sprintf("Dependency %s is declared more than once.", d.GroupID+":"+d.ArtifactID))
+			}
+			seen[key] = true
+		}
+	})
+}
+
+// forEachDependencyList calls fn with the dependencies, then the managed
+// dependencies, of the project and of each profile.
+func forEachDependencyList(p *Project, fn func(path string, deps []Dependency)) {
+	for _, s := range lintSections(p) {
+		fn(s.path+"/dependencies[0]", derefDependencies(s.dependencies))
+		fn(s.path+"/dependencyManagement[0]/dependencies[0]", derefDependencies(s.managed))
+	}
+}
+
+// implicitProperties are the prefixes of the properties plugins read
+// without the POM referencing them.
+var implicitProperties = []string{"maven.", "project.", "sonar.", "surefire.", "failsafe.", "jacoco.", "skip"}
+
+func lintUnusedProperty(p *Project, report func(path, message string)) {
+	// Parent POMs define properties for their children.
+	if p.Packaging == "pom" {
+		return
+	}
+	var texts []string
+	walkText(p, func(path, text string) {
+		texts = append(texts, text)
+	})
+	all := strings.Join(texts, "\n")
+	check := func(parent string, props *Properties) {
+		if props == nil {
+			return
+		}
+	next:
+		for _, key := range props.Order {
+			for _, prefix := range implicitProperties {
+				if strings.HasPrefix(key, prefix) {
+					continue next
+				}
+			}
+			if !strings.Contains(all, "${"+key+"}") {
+				report(elementPath(parent+"/properties[0]", key, 0), fmt.Sprintf("Property %s is not used.", key))
+			}
+		}
+	}
+	check("project", p.Properties)
+	if p.Profiles != nil {
+		for i, profile := range *p.Profiles {
+			check(elementPath("project/profiles[0]", "profile", i), profile.Properties)
+		}
+	}
+}
+
+// walkText calls fn with the path and text of the text elements of p,
+// including the ones in configurations and their attributes.
+func walkText(p *Project, fn func(path, text string)) {
+	var walk func(n node)
+	walk = func(n node) {
+		if t := n.text(); t != "" {
+			fn(n.path, t)
+		}
+		if e, ok := n.v.Interface().(*ConfigElement); ok {
+			for _, a := range e.Attrs {
+				fn(n.path, a.Value)
+			}
+		}
+		children, _ := n.children("*")
+		for _, c := range children {
+			walk(c)
+		}
+	}
+	walk(node{path: "project", v: reflect.ValueOf(p)})
+}
+
+func lintRedundantVersion(p *Project, report func(path, message string)) {
+	for _, s := range lintSections(p) {
+		for i, d := range derefDependencies(s.dependencies) {
+			if managed := s.managedVersions[dependencyKey(d)]; d.Version != "" && d.Version == managed {
+				report(elementPath(s.path+"/dependencies[0]", "dependency", i)+"/version[0]", fmt.Sprintf("Dependency %s repeats its managed version %s.", d.GroupID+":"+d.ArtifactID, d.Version))
+			}
+		}
+	}
+}
+
+func lintInsecureRepository(p *Project, report func(path, message string)) {
+	check := func(path, kind, id, url string) {
+		if strings.HasPrefix(strings.ToLower(strings.TrimSpace(url)), "http://") {
+			report(path+"/url[0]", fmt.Sprintf("%s %s uses an insecure URL: %s.", kind, id, url))
+		}
+	}
+	for _, s := range lintSections(p) {
+		if s.repositories != nil {
+			for i, r := range *s.repositories {
+				check(elementPath(s.path+"/repositories[0]", "repository", i), "Repository", r.ID, r.URL)
+			}
+		}
+		if s.pluginRepositories != nil {
+			for i, r := range *s.pluginRepositories {
+				check(elementPath(s.path+"/pluginRepositories[0]", "pluginRepository", i), "Plugin repository", r.ID, r.URL)
+			}
+		}
+		if s.distribution != nil {
+			if r := s.distribution.Repository; r != nil {
+				check(s.path+"/distributionManagement[0]/repository[0]", "Distribution repository", r.ID, r.URL)
+			}
+			if r := s.distribution.SnapshotRepository; r != nil {
+				check(s.path+"/distributionManagement[0]/snapshotRepository[0]", "Snapshot repository", r.ID, r.URL)
+			}
+		}
+	}
+}
+
+func lintSystemScope(p *Project, report func(path, message string)) {
+	forEachDependencyList(p, func(path string, deps []Dependency) {
+		for i, d := range deps {
+			if d.Scope == "system" {
+				report(elementPath(path, "dependency", i)+"/scope[0]", fmt.Sprintf("Dependency %s uses the system scope.", d.GroupID+":"+d.ArtifactID))
+			}
+		}
+	})
+}
+
+// deprecatedExpressions matches the expressions Maven 3 deprecated in
+// favor of the project.* ones.
+var deprecatedExpressions = regexp.MustCompile(`\$\{(pom\.[^}]+|version|groupId|artifactId)\}`)
+
+func lintDeprecatedElement(p *Project, report func(path, message string)) {
+	if p.Prerequisites != nil && p.Packaging != "maven-plugin" {
+		report("project/prerequisites[0]", "The prerequisites element is only meant for Maven plugins, use the maven-enforcer-plugin to require a Maven version.")
+	}
+	for _, s := range lintSections(p) {
+		if s.repositories != nil {
+			for i, r := range *s.repositories {
+				if r.Layout == "legacy" {
+					report(elementPath(s.path+"/repositories[0]", "repository", i)+"/layout[0]", fmt.Sprintf("Repository %s uses the legacy layout, which Maven 3 does not support.", r.ID))
+				}
+			}
+		}
+	}
+	walkText(p, func(path, text string) {
+		for _, m := range deprecatedExpressions.FindAllStringSubmatch(text, -1) {
+			name := strings.TrimPrefix(m[1], "pom.")
+			report(path, fmt.Sprintf("Expression %s is deprecated, use ${project.%s}.", m[0], name))
+		}
+	})
+}
+
+func lintInlineVersion(p *Project, report func(path, message string)) {
+	if p.Parent == nil && p.DependencyManagement == nil {
+		return
+	}
+	for _, s := range lintSections(p) {
+		for i, d := range derefDependencies(s.dependencies) {
+			if d.Version == "" || d.Version == s.managedVersions[dependencyKey(d)] || d.Scope == "system" {
+				continue
+			}
+			report(elementPath(s.path+"/dependencies[0]", "dependency", i)+"/version[0]", fmt.Sprintf("Dependency %s declares its version inline instead of in dependencyManagement.", d.GroupID+":"+d.ArtifactID))
+		}
+	}
+}
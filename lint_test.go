@@ -0,0 +1,103 @@
+package gopom
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLint(t *testing.T) {
+	p, err := Parse("testdata/lint/pom.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	problems, err := p.Lint(nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, pb := range problems {
+		got = append(got, pb.Path+": "+pb.String())
+	}
+	// The legacy dependency and the inline versions are suppressed by
+	// comments.
+	assert.Equal(t, []string{
+		"project/build[0]/plugins[0]/plugin[0]: warning: Plugin org.apache.maven.plugins:maven-compiler-plugin has no version. [plugin-version]",
+		"project/parent[0]/version[0]: warning: Release 1.0 depends on parent com.example:parent 2.0-SNAPSHOT. [snapshot-dependency]",
+		"project/dependencies[0]/dependency[1]/version[0]: warning: Release 1.0 depends on com.example:core 1.1-SNAPSHOT. [snapshot-dependency]",
+		"project/dependencies[0]/dependency[2]/version[0]: warning: Release 1.0 depends on com.example:core 1.1-SNAPSHOT. [snapshot-dependency]",
+		"project/dependencies[0]/dependency[2]: warning: Dependency com.example:core is declared more than once. [duplicate-dependency]",
+		"project/properties[0]/unused.version[0]: info: Property unused.version is not used. [unused-property]",
+		"project/dependencies[0]/dependency[0]/version[0]: warning: Dependency com.google.guava:guava repeats its managed version ${guava.version}. [redundant-version]",
+		"project/repositories[0]/repository[0]/url[0]: error: Repository internal uses an insecure URL: http://repo.example.com/maven2. [insecure-repository]",
+		"project/profiles[0]/profile[0]/distributionManagement[0]/repository[0]/url[0]: error: Distribution repository releases uses an insecure URL: http://deploy.example.com/releases. [insecure-repository]",
+		"project/dependencies[0]/dependency[3]/scope[0]: warning: Dependency com.sun:tools uses the system scope. [system-scope]",
+		"project/prerequisites[0]: warning: The prerequisites element is only meant for Maven plugins, use the maven-enforcer-plugin to require a Maven version. [deprecated-element]",
+		"project/repositories[0]/repository[1]/layout[0]: warning: Repository old uses the legacy layout, which Maven 3 does not support. [deprecated-element]",
+		"project/build[0]/finalName[0]: warning: Expression ${pom.artifactId} is deprecated, use ${project.artifactId}. [deprecated-element]",
+		"project/build[0]/plugins[0]/plugin[2]/configuration[0]/arguments[0]/argument[0]: warning: Expression ${version} is deprecated, use ${project.version}. [deprecated-element]",
+	}, got)
+}
+
+func TestLintConfig(t *testing.T) {
+	p, err := Parse("testdata/lint/pom.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	config, err := ParseLintConfig(strings.NewReader("rules:\n  snapshot-dependency: error\n  deprecated-element: off\n  unused-property: off\n  redundant-version: off\n  insecure-repository: off\n  system-scope: off\n  duplicate-dependency: off\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	problems, err := p.Lint(nil, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, pb := range problems {
+		got = append(got, pb.Rule+" "+pb.Severity.String())
+	}
+	assert.Equal(t, []string{
+		"plugin-version warning",
+		"snapshot-dependency error",
+		"snapshot-dependency error",
+		"snapshot-dependency error",
+	}, got)
+
+	_, err = p.Lint(nil, &LintConfig{Rules: map[string]string{"no-such-rule": "off"}})
+	assert.Error(t, err)
+	_, err = p.Lint(nil, &LintConfig{Rules: map[string]string{"system-scope": "fatal"}})
+	assert.Error(t, err)
+	_, err = ParseLintConfig(strings.NewReader("rule:\n  system-scope: off\n"))
+	assert.Error(t, err)
+}
+
+func TestLintRules(t *testing.T) {
+	// Projects that were not parsed have no suppressions.
+	p := &Project{
+		Parent:  &Parent{GroupID: "com.example", ArtifactID: "parent", Version: "1"},
+		Version: "1.0-SNAPSHOT",
+		Dependencies: &[]Dependency{
+			{GroupID: "junit", ArtifactID: "junit", Version: "4.13.2"},
+			{GroupID: "com.example", ArtifactID: "core"},
+		},
+	}
+	noJUnit := LintRule{
+		Rule: Rule{ID: "no-junit", Severity: SeverityError},
+		Check: func(p *Project, report func(path, message string)) {
+			for i, d := range *p.Dependencies {
+				if d.ArtifactID == "junit" {
+					report(elementPath("project/dependencies[0]", "dependency", i), "JUnit 4 is not allowed.")
+				}
+			}
+		},
+	}
+	problems, err := p.Lint(append(LintRules(), noJUnit), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []Problem{
+		{Severity: SeverityInfo, Path: "project/dependencies[0]/dependency[0]/version[0]", Message: "Dependency junit:junit declares its version inline instead of in dependencyManagement.", Rule: "inline-version"},
+		{Severity: SeverityError, Path: "project/dependencies[0]/dependency[0]", Message: "JUnit 4 is not allowed.", Rule: "no-junit"},
+	}, problems)
+}
@@ -0,0 +1,443 @@
+package gopom
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Conflict is an element changed differently on both sides of a merge.
+type Conflict struct {
+	// Path locates the element with the syntax of queries, see Query, like
+	// dependencies[groupId=com.google.guava][artifactId=guava].version or
+	// properties."java.version".
+	Path string
+	// Base, Ours and Theirs are the values of the element in the three
+	// projects: the text of text elements, and JSON for the others. They
+	// are empty when the element is missing.
+	Base, Ours, Theirs string
+}
+
+func (c Conflict) String() string {
+	value := func(s string) string {
+		if s == "" {
+			return "(none)"
+		}
+		return s
+	}
+	return c.Path + ": ours " + value(c.Ours) + ", theirs " + value(c.Theirs) + ", base " + value(c.Base)
+}
+
+// Merge merges the changes made to base in ours and in theirs. Changes
+// made on one side only are applied, and so are the ones made the same way
+// on both sides. When both sides changed an element differently, the merged
+// project keeps ours and the element is reported as a conflict.
+//
+// Elements are merged recursively. Lists are merged by key: dependencies by
+// coordinates without version, plugins by groupId:artifactId, executions,
+// profiles and repositories by id, extensions by groupId:artifactId, and
+// lists of strings, like modules, by value. Properties are merged by name.
+// Configurations and other lists, like licenses, are merged as a whole.
+// Formatting and the order of elements do not matter; the merged project
+// keeps the order of ours, followed by the elements added in theirs.
+func Merge(base, ours, theirs *Project) (*Project, []Conflict) {
+	merged := ours.Clone()
+	m := &merger{}
+	m.merge("", reflect.ValueOf(base).Elem(), reflect.ValueOf(ours).Elem(), reflect.ValueOf(theirs).Elem(), reflect.ValueOf(merged).Elem())
+	return merged, m.conflicts
+}
+
+type merger struct {
+	conflicts []Conflict
+}
+
+var (
+	configurationType = reflect.TypeOf(&Configuration{})
+	propertiesType    = reflect.TypeOf(&Properties{})
+)
+
+// merge merges base, ours and theirs into dst, which holds a copy of ours.
+func (m *merger) merge(path string, base, ours, theirs, dst reflect.Value) {
+	switch {
+	case mergeEqual(ours, theirs), mergeEqual(base, theirs):
+		return
+	case mergeEqual(base, ours):
+		setValue(dst, theirs)
+		return
+	}
+
+	switch t := dst.Type(); {
+	case t == propertiesType:
+		m.properties(path, base.Interface().(*Properties), ours.Interface().(*Properties), theirs.Interface().(*Properties), dst)
+		return
+	case t.Kind() == reflect.Pointer && t.Elem().Kind() == reflect.Slice && mergeListed(t.Elem().Elem()):
+		m.list(path, base, ours, theirs, dst)
+		return
+	case t.Kind() == reflect.Pointer && t.Elem().Kind() == reflect.Struct && t != configurationType:
+		if ours.IsNil() || theirs.IsNil() {
+			break
+		}
+		if base.IsNil() {
+			base = reflect.New(t.Elem())
+		}
+		m.merge(path, base.Elem(), ours.Elem(), theirs.Elem(), dst.Elem())
+		return
+	case t.Kind() == reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if !f.IsExported() || f.Name == "XMLName" {
+				continue
+			}
+			m.merge(joinMergePath(path, fieldName(f)), base.Field(i), ours.Field(i), theirs.Field(i), dst.Field(i))
+		}
+		return
+	}
+	m.conflict(path, base, ours, theirs)
+}
+
+func (m *merger) conflict(path string, base, ours, theirs reflect.Value) {
+	m.conflicts = append(m.conflicts, Conflict{Path: path, Base: conflictValue(base), Ours: conflictValue(ours), Theirs: conflictValue(theirs)})
+}
+
+// properties merges the properties by name.
+func (m *merger) properties(path string, base, ours, theirs *Properties, dst reflect.Value) {
+	get := func(p *Properties, key string) (string, bool) {
+		if p == nil {
+			return "", false
+		}
+		v, ok := p.Entries[key]
+		return v, ok
+	}
+	merged := dst.Interface().(*Properties)
+	if merged == nil {
+		merged = &Properties{}
+	}
+	if merged.Entries == nil {
+		merged.Entries = map[string]string{}
+	}
+
+	var keys []string
+	seen := map[string]bool{}
+	for _, p := range []*Properties{ours, theirs, base} {
+		if p == nil {
+			continue
+		}
+		for _, key := range p.Order {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	for _, key := range keys {
+		b, inBase := get(base, key)
+		o, inOurs := get(ours, key)
+		t, inTheirs := get(theirs, key)
+		switch {
+		case inOurs == inTheirs && o == t, inBase == inTheirs && b == t:
+		case inBase == inOurs && b == o:
+			if !inTheirs {
+				delete(merged.Entries, key)
+				merged.Order = removeString(merged.Order, key)
+				continue
+			}
+			if !inOurs {
+				merged.Order = append(merged.Order, key)
+				if c := theirs.comments[key]; c != nil {
+					*merged.Comments(key) = Comments{
+						Leading:  append([]string(nil), c.Leading...),
+						Trailing: append([]string(nil), c.Trailing...),
+					}
+				}
+			}
+			merged.Entries[key] = t
+		default:
+			m.conflicts = append(m.conflicts, Conflict{Path: joinMergePath(path, propertyName(key)), Base: b, Ours: o, Theirs: t})
+		}
+	}
+
+	if ours == nil && len(merged.Entries) == 0 {
+		return
+	}
+	dst.Set(reflect.ValueOf(merged))
+}
+
+// list merges lists of elements by key, see mergeKey.
+func (m *merger) list(path string, base, ours, theirs, dst reflect.Value) {
+	type entry struct {
+		v reflect.Value
+		i int
+	}
+	index := func(list reflect.Value) (map[string]entry, []string) {
+		entries := map[string]entry{}
+		var keys []string
+		if list.IsNil() {
+			return entries, nil
+		}
+		for i := 0; i < list.Elem().Len(); i++ {
+			v := list.Elem().Index(i)
+			key := mergeKey(v)
+			if _, ok := entries[key]; !ok {
+				entries[key] = entry{v, i}
+				keys = append(keys, key)
+			}
+		}
+		return entries, keys
+	}
+	baseEntries, _ := index(base)
+	oursEntries, _ := index(ours)
+	theirsEntries, theirsKeys := index(theirs)
+
+	sliceType := dst.Type().Elem()
+	merged := reflect.MakeSlice(sliceType, 0, 0)
+	if !ours.IsNil() {
+		for i := 0; i < ours.Elem().Len(); i++ {
+			o := ours.Elem().Index(i)
+			d := dst.Elem().Index(i)
+			key := mergeKey(o)
+			if oursEntries[key].i != i {
+				merged = reflect.Append(merged, d)
+				continue
+			}
+			elementPath := path + mergeSelector(o)
+			b, inBase := baseEntries[key]
+			t, inTheirs := theirsEntries[key]
+			switch {
+			case inTheirs:
+				bv := b.v
+				if !inBase {
+					bv = reflect.New(sliceType.Elem()).Elem()
+				}
+				m.merge(elementPath, bv, o, t.v, d)
+			case inBase && mergeEqual(b.v, o):
+				// Removed in theirs.
+				continue
+			case inBase:
+				m.conflict(elementPath, b.v, o, reflect.Value{})
+			}
+			merged = reflect.Append(merged, d)
+		}
+	}
+	for _, key := range theirsKeys {
+		if _, ok := oursEntries[key]; ok {
+			continue
+		}
+		t := theirsEntries[key]
+		b, inBase := baseEntries[key]
+		switch {
+		case !inBase:
+			v := reflect.New(sliceType.Elem()).Elem()
+			setValue(v, t.v)
+			merged = reflect.Append(merged, v)
+		case !mergeEqual(b.v, t.v):
+			m.conflict(path+mergeSelector(t.v), b.v, reflect.Value{}, t.v)
+		}
+	}
+
+	if ours.IsNil() && merged.Len() == 0 {
+		return
+	}
+	list := reflect.New(sliceType)
+	list.Elem().Set(merged)
+	dst.Set(list)
+}
+
+// mergeListed tells whether lists of t are merged by key.
+func mergeListed(t reflect.Type) bool {
+	switch reflect.Zero(t).Interface().(type) {
+	case Dependency, Plugin, PluginExecution, Profile, Repository, PluginRepository, Extension, string:
+		return true
+	}
+	return false
+}
+
+// mergeKey returns the key identifying the element v of a list.
+func mergeKey(v reflect.Value) string {
+	switch e := v.Interface().(type) {
+	case Dependency:
+		return dependencyKey(e)
+	case Plugin:
+		return pluginKey(e)
+	case PluginExecution:
+		return orDefault(e.ID, "default")
+	case Profile:
+		return e.ID
+	case Repository:
+		return e.ID
+	case PluginRepository:
+		return e.ID
+	case Extension:
+		return e.GroupID + ":" + e.ArtifactID
+	case string:
+		return e
+	}
+	return ""
+}
+
+// mergeSelector returns the query selectors matching the element v of a
+// list.
+func mergeSelector(v reflect.Value) string {
+	var b strings.Builder
+	add := func(name, value string) {
+		if value != "" {
+			b.WriteString("[" + name + "=" + value + "]")
+		}
+	}
+	switch e := v.Interface().(type) {
+	case Dependency:
+		add("groupId", e.GroupID)
+		add("artifactId", e.ArtifactID)
+		add("type", e.Type)
+		add("classifier", e.Classifier)
+	case Plugin:
+		add("groupId", e.GroupID)
+		add("artifactId", e.ArtifactID)
+	case Extension:
+		add("groupId", e.GroupID)
+		add("artifactId", e.ArtifactID)
+	case PluginExecution:
+		add("id", e.ID)
+	case Profile:
+		add("id", e.ID)
+	case Repository:
+		add("id", e.ID)
+	case PluginRepository:
+		add("id", e.ID)
+	}
+	return b.String()
+}
+
+// mergeEqual compares the exported parts of a and b. Configurations are
+// compared with their whitespace collapsed, and properties regardless of
+// their order.
+func mergeEqual(a, b reflect.Value) bool {
+	switch a.Type() {
+	case configurationType:
+		if a.IsNil() || b.IsNil() {
+			return a.IsNil() == b.IsNil()
+		}
+		return configurationString(a.Interface().(*Configuration)) == configurationString(b.Interface().(*Configuration))
+	case propertiesType:
+		if a.IsNil() || b.IsNil() {
+			return a.IsNil() == b.IsNil()
+		}
+		return mergeEqual(a.Elem().FieldByName("Entries"), b.Elem().FieldByName("Entries"))
+	}
+	switch a.Kind() {
+	case reflect.Pointer:
+		if a.IsNil() || b.IsNil() {
+			return a.IsNil() == b.IsNil()
+		}
+		return mergeEqual(a.Elem(), b.Elem())
+	case reflect.Struct:
+		for i := 0; i < a.NumField(); i++ {
+			if a.Type().Field(i).IsExported() && !mergeEqual(a.Field(i), b.Field(i)) {
+				return false
+			}
+		}
+		return true
+	case reflect.Slice:
+		if a.Len() != b.Len() {
+			return false
+		}
+		for i := 0; i < a.Len(); i++ {
+			if !mergeEqual(a.Index(i), b.Index(i)) {
+				return false
+			}
+		}
+		return true
+	case reflect.Map:
+		if a.Len() != b.Len() {
+			return false
+		}
+		iter := a.MapRange()
+		for iter.Next() {
+			v := b.MapIndex(iter.Key())
+			if !v.IsValid() || !mergeEqual(iter.Value(), v) {
+				return false
+			}
+		}
+		return true
+	}
+	return a.Interface() == b.Interface()
+}
+
+// setValue sets dst to a copy of src, along with the comments attached to
+// it.
+func setValue(dst, src reflect.Value) {
+	dst.Set(deepCopy(src))
+	if !src.CanAddr() || !dst.CanAddr() {
+		return
+	}
+	if from, ok := src.Addr().Interface().(commentable); ok {
+		if c := from.attached(); c != nil {
+			*dst.Addr().Interface().(commentable).Comments() = Comments{
+				Leading:  append([]string(nil), c.Leading...),
+				Trailing: append([]string(nil), c.Trailing...),
+			}
+		}
+	}
+}
+
+// conflictValue formats a conflicting value, see Conflict.
+func conflictValue(v reflect.Value) string {
+	if !v.IsValid() || (v.Kind() == reflect.Pointer && v.IsNil()) {
+		return ""
+	}
+	switch x := v.Interface().(type) {
+	case string:
+		return x
+	case bool:
+		return strconv.FormatBool(x)
+	case *Configuration:
+		return configurationString(x)
+	}
+	b, err := json.Marshal(v.Interface())
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
+
+// fieldName returns the element name of a field in queries, or an empty
+// string for embedded structs.
+func fieldName(f reflect.StructField) string {
+	if f.Anonymous {
+		return ""
+	}
+	name, _, _ := strings.Cut(f.Tag.Get("xml"), ",")
+	name, _, _ = strings.Cut(name, ">")
+	if name == "" {
+		return f.Name
+	}
+	return name
+}
+
+func joinMergePath(path, name string) string {
+	switch {
+	case name == "":
+		return path
+	case path == "":
+		return name
+	}
+	return path + "." + name
+}
+
+// propertyName quotes property names containing dots.
+func propertyName(key string) string {
+	if strings.Contains(key, ".") {
+		return strconv.Quote(key)
+	}
+	return key
+}
+
+func removeString(list []string, s string) []string {
+	var out []string
+	for _, v := range list {
+		if v != s {
+			out = append(out, v)
+		}
+	}
+	return out
+}
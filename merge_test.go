@@ -0,0 +1,92 @@
+package gopom
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func parseMerge(t *testing.T) (base, ours, theirs *Project) {
+	t.Helper()
+	var projects []*Project
+	for _, name := range []string{"base", "ours", "theirs"} {
+		p, err := Parse("testdata/merge/" + name + ".xml")
+		if err != nil {
+			t.Fatal(err)
+		}
+		projects = append(projects, p)
+	}
+	return projects[0], projects[1], projects[2]
+}
+
+func TestMerge(t *testing.T) {
+	base, ours, theirs := parseMerge(t)
+	merged, conflicts := Merge(base, ours, theirs)
+
+	assert.Equal(t, []Conflict{
+		{Path: "version", Base: "1.0-SNAPSHOT", Ours: "1.1-SNAPSHOT", Theirs: "1.0.1-SNAPSHOT"},
+		{Path: `properties."jackson.version"`, Base: "2.15.0", Ours: "2.16.0", Theirs: "2.15.3"},
+		{Path: "dependencies[groupId=com.google.guava][artifactId=guava].version", Base: "31.0-jre", Ours: "32.0.0-jre", Theirs: "31.1-jre"},
+		{
+			Path:   "dependencies[groupId=org.apache.commons][artifactId=commons-lang3]",
+			Base:   `{"groupId":"org.apache.commons","artifactId":"commons-lang3","version":"3.12.0"}`,
+			Theirs: `{"groupId":"org.apache.commons","artifactId":"commons-lang3","version":"3.13.0"}`,
+		},
+	}, conflicts)
+
+	// The changes of theirs that do not conflict are applied to ours.
+	var got []string
+	for _, c := range Diff(ours, merged) {
+		got = append(got, c.String())
+	}
+	assert.Equal(t, []string{
+		"property java.version: value changed from 11 to 17",
+		"module docs: added",
+		"dependency junit:junit: version upgraded from 4.13 to 4.13.2",
+		"dependency org.mockito:mockito-core: added 5.7.0",
+		"plugin org.apache.maven.plugins:maven-compiler-plugin: executions changed from compile@process-sources:compile to compile@process-sources:compile; test-compile@test-compile:testCompile",
+		"plugin org.apache.maven.plugins:maven-surefire-plugin: version upgraded from 2.22.2 to 3.2.2",
+	}, got)
+	assert.Equal(t, []string{"Mocks for the web tests."}, (*merged.Dependencies)[3].Comments().Leading)
+	assert.Equal(t, []string{"Only on our branch."}, merged.Properties.Comments("ours").Leading)
+
+	// Conflict paths are queries.
+	for _, c := range conflicts {
+		results, err := merged.Query(c.Path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c.Ours == "" {
+			assert.Empty(t, results, c.Path)
+		} else {
+			assert.Len(t, results, 1, c.Path)
+		}
+	}
+}
+
+func TestMergeOneSide(t *testing.T) {
+	base, ours, theirs := parseMerge(t)
+
+	merged, conflicts := Merge(base, base, theirs)
+	assert.Empty(t, conflicts)
+	assert.Empty(t, Diff(theirs, merged))
+
+	merged, conflicts = Merge(base, ours, base)
+	assert.Empty(t, conflicts)
+	assert.Empty(t, Diff(ours, merged))
+
+	merged, conflicts = Merge(base, theirs, theirs)
+	assert.Empty(t, conflicts)
+	assert.Empty(t, Diff(theirs, merged))
+}
+
+func TestMergeDoesNotModifyInputs(t *testing.T) {
+	base, ours, theirs := parseMerge(t)
+	merged, _ := Merge(base, ours, theirs)
+	(*merged.Dependencies)[0].Version = "0"
+	merged.Properties.Entries["java.version"] = "8"
+
+	_, fresh, _ := parseMerge(t)
+	assert.Empty(t, Diff(fresh, ours))
+	assert.Equal(t, "17", theirs.Properties.Entries["java.version"])
+}
@@ -0,0 +1,284 @@
+package gopom
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Metadata is the content of a maven-metadata.xml file. Repositories keep
+// one per groupId, listing its plugin prefixes, one per artifact, listing
+// its versions, and one per SNAPSHOT version, listing its timestamped
+// builds.
+type Metadata struct {
+	XMLName      xml.Name          `xml:"metadata" json:"-" yaml:"-"`
+	ModelVersion string            `xml:"modelVersion,attr,omitempty" json:"modelVersion,omitempty" yaml:"modelVersion,omitempty"`
+	GroupID      string            `xml:"groupId,omitempty" json:"groupId,omitempty" yaml:"groupId,omitempty"`
+	ArtifactID   string            `xml:"artifactId,omitempty" json:"artifactId,omitempty" yaml:"artifactId,omitempty"`
+	Version      string            `xml:"version,omitempty" json:"version,omitempty" yaml:"version,omitempty"`
+	Versioning   *Versioning       `xml:"versioning,omitempty" json:"versioning,omitempty" yaml:"versioning,omitempty"`
+	Plugins      *[]MetadataPlugin `xml:"plugins>plugin,omitempty" json:"plugins,omitempty" yaml:"plugins,omitempty"`
+}
+
+// Versioning lists the versions of an artifact, or the last build of a
+// SNAPSHOT version.
+type Versioning struct {
+	Latest   string    `xml:"latest,omitempty" json:"latest,omitempty" yaml:"latest,omitempty"`
+	Release  string    `xml:"release,omitempty" json:"release,omitempty" yaml:"release,omitempty"`
+	Snapshot *Snapshot `xml:"snapshot,omitempty" json:"snapshot,omitempty" yaml:"snapshot,omitempty"`
+	Versions *[]string `xml:"versions>version,omitempty" json:"versions,omitempty" yaml:"versions,omitempty"`
+	// LastUpdated is a UTC timestamp in the yyyyMMddHHmmss format.
+	LastUpdated      string             `xml:"lastUpdated,omitempty" json:"lastUpdated,omitempty" yaml:"lastUpdated,omitempty"`
+	SnapshotVersions *[]SnapshotVersion `xml:"snapshotVersions>snapshotVersion,omitempty" json:"snapshotVersions,omitempty" yaml:"snapshotVersions,omitempty"`
+}
+
+// Snapshot is the last build of a SNAPSHOT version.
+type Snapshot struct {
+	// Timestamp is a UTC timestamp in the yyyyMMdd.HHmmss format.
+	Timestamp   string `xml:"timestamp,omitempty" json:"timestamp,omitempty" yaml:"timestamp,omitempty"`
+	BuildNumber string `xml:"buildNumber,omitempty" json:"buildNumber,omitempty" yaml:"buildNumber,omitempty"`
+	// LocalCopy is set for SNAPSHOT versions installed locally, whose files
+	// are not timestamped.
+	LocalCopy string `xml:"localCopy,omitempty" json:"localCopy,omitempty" yaml:"localCopy,omitempty"`
+}
+
+// SnapshotVersion is the timestamped version of a file of a SNAPSHOT
+// version.
+type SnapshotVersion struct {
+	Classifier string `xml:"classifier,omitempty" json:"classifier,omitempty" yaml:"classifier,omitempty"`
+	Extension  string `xml:"extension,omitempty" json:"extension,omitempty" yaml:"extension,omitempty"`
+	Value      string `xml:"value,omitempty" json:"value,omitempty" yaml:"value,omitempty"`
+	// Updated is a UTC timestamp in the yyyyMMddHHmmss format.
+	Updated string `xml:"updated,omitempty" json:"updated,omitempty" yaml:"updated,omitempty"`
+}
+
+// MetadataPlugin maps a plugin prefix, as in `mvn dependency:tree`, to the
+// plugin of the group.
+type MetadataPlugin struct {
+	Name       string `xml:"name,omitempty" json:"name,omitempty" yaml:"name,omitempty"`
+	Prefix     string `xml:"prefix,omitempty" json:"prefix,omitempty" yaml:"prefix,omitempty"`
+	ArtifactID string `xml:"artifactId,omitempty" json:"artifactId,omitempty" yaml:"artifactId,omitempty"`
+}
+
+// ParseMetadata parses the maven-metadata.xml file at path.
+func ParseMetadata(path string) (*Metadata, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ParseMetadataReader(f)
+}
+
+// ParseMetadataReader parses the metadata read from r.
+func ParseMetadataReader(r io.Reader) (*Metadata, error) {
+	var m Metadata
+	if err := xml.NewDecoder(r).Decode(&m); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// Marshal marshals the metadata into a byte slice, indented like the files
+// Maven writes.
+func (m *Metadata) Marshal() ([]byte, error) {
+	b, err := xml.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal: %w", err)
+	}
+	return append([]byte(xml.Header), append(b, '\n')...), nil
+}
+
+// LoadMetadata reads the metadata of the group, artifact or SNAPSHOT
+// version, depending on which of artifactID and version are set, from a
+// local repository, and merges it. Local repositories keep the metadata of
+// every remote repository in maven-metadata-<id>.xml files, and the one of
+// locally installed artifacts in maven-metadata-local.xml. It returns an
+// error wrapping fs.ErrNotExist when there is none.
+func LoadMetadata(repository, groupID, artifactID, version string) (*Metadata, error) {
+	dir := filepath.Join(repository, filepath.FromSlash(strings.ReplaceAll(groupID, ".", "/")))
+	if artifactID != "" {
+		dir = filepath.Join(dir, artifactID)
+		if version != "" {
+			dir = filepath.Join(dir, version)
+		}
+	}
+	paths, err := filepath.Glob(filepath.Join(dir, "maven-metadata*.xml"))
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no metadata in %s: %w", dir, fs.ErrNotExist)
+	}
+	var all []*Metadata
+	for _, path := range paths {
+		m, err := ParseMetadata(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		all = append(all, m)
+	}
+	return MergeMetadata(all...), nil
+}
+
+// MergeMetadata merges the metadata of the same group, artifact or version
+// found in several repositories, like Maven does:
+//
+//   - versions are combined, and sorted in Maven order,
+//   - latest, release and lastUpdated come from the most recently updated
+//     metadata that has them,
+//   - the most recent snapshot, and the most recent version of each
+//     snapshot file, win,
+//   - plugins are combined by prefix.
+//
+// The arguments are not modified, and nil ones are skipped.
+func MergeMetadata(metadata ...*Metadata) *Metadata {
+	var merged *Metadata
+	for _, m := range metadata {
+		if m == nil {
+			continue
+		}
+		if merged == nil {
+			merged = &Metadata{ModelVersion: m.ModelVersion, GroupID: m.GroupID, ArtifactID: m.ArtifactID, Version: m.Version}
+		}
+		merged.merge(m)
+	}
+	return merged
+}
+
+func (m *Metadata) merge(other *Metadata) {
+	if other.Plugins != nil {
+		plugins := append([]MetadataPlugin(nil), derefSlice(m.Plugins)...)
+		m.Plugins = mergeByID(&plugins, other.Plugins, func(p MetadataPlugin) string { return p.Prefix })
+	}
+	v := other.Versioning
+	if v == nil {
+		return
+	}
+	if m.Versioning == nil {
+		m.Versioning = &Versioning{}
+	}
+	merged := m.Versioning
+	newer := v.LastUpdated >= merged.LastUpdated
+	if v.Latest != "" && (newer || merged.Latest == "") {
+		merged.Latest = v.Latest
+	}
+	if v.Release != "" && (newer || merged.Release == "") {
+		merged.Release = v.Release
+	}
+	if newer {
+		merged.LastUpdated = v.LastUpdated
+	}
+	if v.Versions != nil {
+		versions := append([]string(nil), derefSlice(merged.Versions)...)
+		versions = *mergeByID(&versions, v.Versions, func(s string) string { return s })
+		sort.SliceStable(versions, func(i, j int) bool { return CompareVersions(versions[i], versions[j]) < 0 })
+		merged.Versions = &versions
+	}
+	if s := v.Snapshot; s != nil && (merged.Snapshot == nil || s.Timestamp > merged.Snapshot.Timestamp) {
+		snapshot := *s
+		merged.Snapshot = &snapshot
+	}
+	if v.SnapshotVersions != nil {
+		files := append([]SnapshotVersion(nil), derefSlice(merged.SnapshotVersions)...)
+		index := map[string]int{}
+		for i, f := range files {
+			index[f.Classifier+":"+f.Extension] = i
+		}
+		for _, f := range *v.SnapshotVersions {
+			i, ok := index[f.Classifier+":"+f.Extension]
+			switch {
+			case !ok:
+				index[f.Classifier+":"+f.Extension] = len(files)
+				files = append(files, f)
+			case f.Updated > files[i].Updated:
+				files[i] = f
+			}
+		}
+		merged.SnapshotVersions = &files
+	}
+}
+
+// ErrNoVersion is returned when metadata does not resolve a version.
+var ErrNoVersion = errors.New("no matching version")
+
+// ResolveVersion resolves version with the artifact metadata m: LATEST is
+// the latest version, including SNAPSHOT ones, and RELEASE the latest
+// release. When the metadata does not say, the highest matching version
+// listed wins. Other versions are returned as is.
+func (m *Metadata) ResolveVersion(version string) (string, error) {
+	if version != "LATEST" && version != "RELEASE" {
+		return version, nil
+	}
+	release := version == "RELEASE"
+	if v := m.Versioning; v != nil {
+		if release && v.Release != "" {
+			return v.Release, nil
+		}
+		if !release && v.Latest != "" {
+			return v.Latest, nil
+		}
+	}
+	var highest string
+	if m.Versioning != nil {
+		for _, v := range derefSlice(m.Versioning.Versions) {
+			if release && strings.HasSuffix(v, "-SNAPSHOT") {
+				continue
+			}
+			if highest == "" || CompareVersions(v, highest) > 0 {
+				highest = v
+			}
+		}
+	}
+	if highest == "" {
+		return "", fmt.Errorf("failed to resolve %s of %s:%s: %w", version, m.GroupID, m.ArtifactID, ErrNoVersion)
+	}
+	return highest, nil
+}
+
+// ResolveSnapshot returns the timestamped version of the file with the
+// given extension, which defaults to jar, and classifier of the SNAPSHOT
+// version m is the metadata of, e.g. 1.0-20240415.093012-7 for 1.0-SNAPSHOT.
+// Locally installed SNAPSHOT versions, whose files are not timestamped,
+// resolve to the SNAPSHOT version itself.
+func (m *Metadata) ResolveSnapshot(extension, classifier string) (string, error) {
+	if extension == "" {
+		extension = "jar"
+	}
+	if !strings.HasSuffix(m.Version, "-SNAPSHOT") {
+		return "", fmt.Errorf("%s is not a SNAPSHOT version", m.Version)
+	}
+	v := m.Versioning
+	if v != nil {
+		for _, f := range derefSlice(v.SnapshotVersions) {
+			if f.Extension == extension && f.Classifier == classifier {
+				return f.Value, nil
+			}
+		}
+	}
+	switch {
+	case v == nil || v.Snapshot == nil:
+	case strings.TrimSpace(v.Snapshot.LocalCopy) == "true":
+		return m.Version, nil
+	case v.Snapshot.Timestamp != "" && v.Snapshot.BuildNumber != "":
+		return strings.TrimSuffix(m.Version, "SNAPSHOT") + v.Snapshot.Timestamp + "-" + v.Snapshot.BuildNumber, nil
+	}
+	return "", fmt.Errorf("failed to resolve %s of %s:%s: %w", m.Version, m.GroupID, m.ArtifactID, ErrNoVersion)
+}
+
+// PluginPrefix returns the artifactId of the plugin with the given prefix,
+// in the group metadata m.
+func (m *Metadata) PluginPrefix(prefix string) (string, bool) {
+	for _, p := range derefSlice(m.Plugins) {
+		if p.Prefix == prefix {
+			return p.ArtifactID, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,158 @@
+package gopom
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMetadata(t *testing.T) {
+	m, err := ParseMetadata("testdata/metadata/com/example/core/2.1-SNAPSHOT/maven-metadata-internal.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "1.1.0", m.ModelVersion)
+	assert.Equal(t, "2.1-SNAPSHOT", m.Version)
+	assert.Equal(t, "20240415.093012", m.Versioning.Snapshot.Timestamp)
+	assert.Equal(t, "7", m.Versioning.Snapshot.BuildNumber)
+	assert.Len(t, *m.Versioning.SnapshotVersions, 3)
+	assert.Equal(t, "sources", (*m.Versioning.SnapshotVersions)[1].Classifier)
+
+	b, err := m.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	src, err := os.ReadFile("testdata/metadata/com/example/core/2.1-SNAPSHOT/maven-metadata-internal.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The header differs in quotes only.
+	assert.Equal(t, strings.SplitN(string(src), "\n", 2)[1], strings.SplitN(string(b), "\n", 2)[1])
+
+	_, err = ParseMetadataReader(strings.NewReader("<metadata><versioning>"))
+	assert.Error(t, err)
+}
+
+func TestLoadMetadata(t *testing.T) {
+	m, err := LoadMetadata("testdata/metadata", "com.example", "core", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "core", m.ArtifactID)
+	assert.Equal(t, []string{"1.0", "1.1", "1.10", "2.0", "2.1-SNAPSHOT"}, *m.Versioning.Versions)
+	// The most recent metadata wins, the others fill in the gaps.
+	assert.Equal(t, "2.1-SNAPSHOT", m.Versioning.Latest)
+	assert.Equal(t, "2.0", m.Versioning.Release)
+	assert.Equal(t, "20240415093012", m.Versioning.LastUpdated)
+
+	m, err = LoadMetadata("testdata/metadata", "com.example", "core", "2.1-SNAPSHOT")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "20240415.093012", m.Versioning.Snapshot.Timestamp)
+	assert.Len(t, *m.Versioning.SnapshotVersions, 3)
+	assert.Equal(t, "2.1-20240415.093012-7", (*m.Versioning.SnapshotVersions)[0].Value)
+
+	m, err = LoadMetadata("testdata/metadata", "org.apache.maven.plugins", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, *m.Plugins, 3)
+
+	_, err = LoadMetadata("testdata/metadata", "com.example", "missing", "")
+	assert.True(t, errors.Is(err, fs.ErrNotExist))
+}
+
+func TestMergeMetadata(t *testing.T) {
+	older := &Metadata{Versioning: &Versioning{Latest: "1.0", Release: "1.0", LastUpdated: "20240101000000", Versions: &[]string{"1.0"}}}
+	newer := &Metadata{Versioning: &Versioning{Latest: "1.1-SNAPSHOT", LastUpdated: "20240201000000", Versions: &[]string{"1.1-SNAPSHOT", "1.0"}}}
+	m := MergeMetadata(newer, nil, older)
+	assert.Equal(t, "1.1-SNAPSHOT", m.Versioning.Latest)
+	assert.Equal(t, "1.0", m.Versioning.Release)
+	assert.Equal(t, "20240201000000", m.Versioning.LastUpdated)
+	assert.Equal(t, []string{"1.0", "1.1-SNAPSHOT"}, *m.Versioning.Versions)
+
+	// The arguments are not modified.
+	assert.Equal(t, []string{"1.1-SNAPSHOT", "1.0"}, *newer.Versioning.Versions)
+	assert.Equal(t, "", newer.Versioning.Release)
+
+	assert.Nil(t, MergeMetadata())
+	assert.Nil(t, MergeMetadata(nil))
+}
+
+func TestResolveVersion(t *testing.T) {
+	m, err := LoadMetadata("testdata/metadata", "com.example", "core", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resolve := func(m *Metadata, version string) string {
+		v, err := m.ResolveVersion(version)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+	assert.Equal(t, "2.1-SNAPSHOT", resolve(m, "LATEST"))
+	assert.Equal(t, "2.0", resolve(m, "RELEASE"))
+	assert.Equal(t, "1.1", resolve(m, "1.1"))
+
+	// Without latest and release, the highest listed version wins.
+	listed := &Metadata{Versioning: &Versioning{Versions: &[]string{"1.9", "1.10", "2.0-SNAPSHOT"}}}
+	assert.Equal(t, "2.0-SNAPSHOT", resolve(listed, "LATEST"))
+	assert.Equal(t, "1.10", resolve(listed, "RELEASE"))
+
+	_, err = (&Metadata{Versioning: &Versioning{Versions: &[]string{"1.0-SNAPSHOT"}}}).ResolveVersion("RELEASE")
+	assert.True(t, errors.Is(err, ErrNoVersion))
+	_, err = (&Metadata{}).ResolveVersion("LATEST")
+	assert.True(t, errors.Is(err, ErrNoVersion))
+}
+
+func TestResolveSnapshot(t *testing.T) {
+	m, err := LoadMetadata("testdata/metadata", "com.example", "core", "2.1-SNAPSHOT")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resolve := func(m *Metadata, extension, classifier string) string {
+		v, err := m.ResolveSnapshot(extension, classifier)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+	assert.Equal(t, "2.1-20240415.093012-7", resolve(m, "", ""))
+	assert.Equal(t, "2.1-20240415.093012-7", resolve(m, "pom", ""))
+	assert.Equal(t, "2.1-20240414.170501-6", resolve(m, "jar", "sources"))
+	// Files not listed fall back to the last build.
+	assert.Equal(t, "2.1-20240415.093012-7", resolve(m, "jar", "javadoc"))
+
+	local, err := ParseMetadata("testdata/metadata/com/example/core/2.1-SNAPSHOT/maven-metadata-local.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "2.1-SNAPSHOT", resolve(local, "jar", ""))
+	assert.Equal(t, "2.1-SNAPSHOT", resolve(local, "pom", ""))
+
+	_, err = (&Metadata{Version: "2.1-SNAPSHOT"}).ResolveSnapshot("jar", "")
+	assert.True(t, errors.Is(err, ErrNoVersion))
+	_, err = (&Metadata{Version: "2.0"}).ResolveSnapshot("jar", "")
+	assert.Error(t, err)
+}
+
+func TestPluginPrefix(t *testing.T) {
+	m, err := LoadMetadata("testdata/metadata", "org.apache.maven.plugins", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	artifactID, ok := m.PluginPrefix("dependency")
+	assert.True(t, ok)
+	assert.Equal(t, "maven-dependency-plugin", artifactID)
+	artifactID, ok = m.PluginPrefix("surefire")
+	assert.True(t, ok)
+	assert.Equal(t, "maven-surefire-plugin", artifactID)
+	_, ok = m.PluginPrefix("missing")
+	assert.False(t, ok)
+}
@@ -0,0 +1,281 @@
+package gopom
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Vulnerability is an OSV advisory, with the fields gopom uses. See
+// https://ossf.github.io/osv-schema/.
+type Vulnerability struct {
+	ID        string        `json:"id"`
+	Aliases   []string      `json:"aliases,omitempty"`
+	Summary   string        `json:"summary,omitempty"`
+	Details   string        `json:"details,omitempty"`
+	Withdrawn string        `json:"withdrawn,omitempty"`
+	Affected  []OSVAffected `json:"affected"`
+}
+
+// OSVAffected lists the affected versions of a package.
+type OSVAffected struct {
+	Package  OSVPackage `json:"package"`
+	Ranges   []OSVRange `json:"ranges,omitempty"`
+	Versions []string   `json:"versions,omitempty"`
+}
+
+// OSVPackage identifies a package. Maven packages are named
+// groupId:artifactId.
+type OSVPackage struct {
+	Ecosystem string `json:"ecosystem"`
+	Name      string `json:"name"`
+}
+
+// OSVRange is a range of affected versions, delimited by events.
+type OSVRange struct {
+	Type   string     `json:"type"`
+	Events []OSVEvent `json:"events"`
+}
+
+// OSVEvent is the version where a vulnerability was introduced, fixed or
+// last seen.
+type OSVEvent struct {
+	Introduced   string `json:"introduced,omitempty"`
+	Fixed        string `json:"fixed,omitempty"`
+	LastAffected string `json:"last_affected,omitempty"`
+	Limit        string `json:"limit,omitempty"`
+}
+
+// OSVDatabase holds the Maven advisories of an OSV database snapshot.
+type OSVDatabase struct {
+	// packages maps groupId:artifactId to the advisories affecting it.
+	packages map[string][]*Vulnerability
+}
+
+// LoadOSVDatabase loads OSV advisories from path: either a zip archive, like
+// the Maven ecosystem export at
+// https://osv-vulnerabilities.storage.googleapis.com/Maven/all.zip, or a
+// directory of JSON files. Withdrawn advisories and the packages of other
+// ecosystems are skipped.
+func LoadOSVDatabase(path string) (*OSVDatabase, error) {
+	db := &OSVDatabase{packages: map[string][]*Vulnerability{}}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		err = filepath.WalkDir(path, func(path string, d os.DirEntry, err error) error {
+			if err != nil || d.IsDir() || filepath.Ext(path) != ".json" {
+				return err
+			}
+			f, err := os.Open(path)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			return db.add(path, f)
+		})
+	} else {
+		err = db.loadZip(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+func (db *OSVDatabase) loadZip(path string) error {
+	z, err := zip.OpenReader(path)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer z.Close()
+	for _, f := range z.File {
+		if f.FileInfo().IsDir() || filepath.Ext(f.Name) != ".json" {
+			continue
+		}
+		r, err := f.Open()
+		if err != nil {
+			return fmt.Errorf("failed to open %s in %s: %w", f.Name, path, err)
+		}
+		err = db.add(f.Name, r)
+		r.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (db *OSVDatabase) add(name string, r io.Reader) error {
+	var v Vulnerability
+	if err := json.NewDecoder(r).Decode(&v); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", name, err)
+	}
+	if v.Withdrawn != "" {
+		return nil
+	}
+	seen := map[string]bool{}
+	for _, a := range v.Affected {
+		if a.Package.Ecosystem != "Maven" || seen[a.Package.Name] {
+			continue
+		}
+		seen[a.Package.Name] = true
+		db.packages[a.Package.Name] = append(db.packages[a.Package.Name], &v)
+	}
+	return nil
+}
+
+// Query returns the advisories affecting the version of c, sorted by ID.
+func (db *OSVDatabase) Query(c Coordinate) []*Vulnerability {
+	var vulns []*Vulnerability
+	for _, v := range db.packages[c.GroupID+":"+c.ArtifactID] {
+		if v.affects(c) {
+			vulns = append(vulns, v)
+		}
+	}
+	sort.Slice(vulns, func(i, j int) bool { return vulns[i].ID < vulns[j].ID })
+	return vulns
+}
+
+// VulnerableDependency is a dependency affected by an advisory.
+type VulnerableDependency struct {
+	// Dependency is the affected dependency, with its resolved scope.
+	Dependency Dependency
+	// Path lists the dependencies leading from the project to the affected
+	// one, which is the last element.
+	Path          []Coordinate
+	Vulnerability *Vulnerability
+	// FixedVersion is the lowest version above the dependency's one that
+	// the advisory does not affect, or an empty string if there is none.
+	FixedVersion string
+}
+
+func (v VulnerableDependency) String() string {
+	var path []string
+	for _, c := range v.Path {
+		path = append(path, c.String())
+	}
+	s := strings.Join(path, " > ") + ": " + v.Vulnerability.ID
+	if v.Vulnerability.Summary != "" {
+		s += " " + v.Vulnerability.Summary
+	}
+	if v.FixedVersion != "" {
+		s += " (fixed in " + v.FixedVersion + ")"
+	}
+	return s
+}
+
+// Check returns the dependencies of a resolved graph, see Resolve, that
+// are affected by advisories, in depth-first order.
+func (db *OSVDatabase) Check(graph []*DependencyNode) []VulnerableDependency {
+	var found []VulnerableDependency
+	seen := map[*DependencyNode]bool{}
+	var walk func(nodes []*DependencyNode, path []Coordinate)
+	walk = func(nodes []*DependencyNode, path []Coordinate) {
+		for _, n := range nodes {
+			if seen[n] {
+				continue
+			}
+			seen[n] = true
+			c := n.Dependency.Coordinate()
+			path := append(path[:len(path):len(path)], c)
+			for _, v := range db.Query(c) {
+				found = append(found, VulnerableDependency{Dependency: n.Dependency, Path: path, Vulnerability: v, FixedVersion: v.fixedVersion(c)})
+			}
+			walk(n.Dependencies, path)
+		}
+	}
+	walk(graph, nil)
+	return found
+}
+
+// affects tells whether the version of c is affected by v.
+func (v *Vulnerability) affects(c Coordinate) bool {
+	if c.Version == "" {
+		return false
+	}
+	for _, a := range v.Affected {
+		if a.Package.Ecosystem != "Maven" || a.Package.Name != c.GroupID+":"+c.ArtifactID {
+			continue
+		}
+		for _, version := range a.Versions {
+			if CompareVersions(version, c.Version) == 0 {
+				return true
+			}
+		}
+		for _, r := range a.Ranges {
+			if r.Type != "GIT" && r.affects(c.Version) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// fixedVersion returns the lowest fixed version above the version of c
+// that v does not affect.
+func (v *Vulnerability) fixedVersion(c Coordinate) string {
+	var fixed []string
+	for _, a := range v.Affected {
+		if a.Package.Name != c.GroupID+":"+c.ArtifactID {
+			continue
+		}
+		for _, r := range a.Ranges {
+			for _, e := range r.Events {
+				if e.Fixed != "" && CompareVersions(e.Fixed, c.Version) > 0 {
+					fixed = append(fixed, e.Fixed)
+				}
+			}
+		}
+	}
+	sort.Slice(fixed, func(i, j int) bool { return CompareVersions(fixed[i], fixed[j]) < 0 })
+	for _, version := range fixed {
+		if !v.affects(Coordinate{GroupID: c.GroupID, ArtifactID: c.ArtifactID, Version: version}) {
+			return version
+		}
+	}
+	return ""
+}
+
+// affects evaluates the events of r, in version order, for version.
+func (r OSVRange) affects(version string) bool {
+	events := append([]OSVEvent{}, r.Events...)
+	key := func(e OSVEvent) string {
+		return e.Introduced + e.Fixed + e.LastAffected + e.Limit
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		a, b := key(events[i]), key(events[j])
+		if a == "0" || b == "0" {
+			return a == "0" && b != "0"
+		}
+		return CompareVersions(a, b) < 0
+	})
+	affected := false
+	for _, e := range events {
+		switch {
+		case e.Introduced != "":
+			if e.Introduced == "0" || CompareVersions(version, e.Introduced) >= 0 {
+				affected = true
+			}
+		case e.Fixed != "":
+			if CompareVersions(version, e.Fixed) >= 0 {
+				affected = false
+			}
+		case e.LastAffected != "":
+			if CompareVersions(version, e.LastAffected) > 0 {
+				affected = false
+			}
+		case e.Limit != "":
+			if CompareVersions(version, e.Limit) >= 0 {
+				return false
+			}
+		}
+	}
+	return affected
+}
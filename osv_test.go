@@ -0,0 +1,98 @@
+package gopom
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOSVDatabaseCheck(t *testing.T) {
+	db, err := LoadOSVDatabase("testdata/osv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := Parse("testdata/policy/pom.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	graph, err := Resolve(p, ResolveOptions{Repository: testRepository})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, v := range db.Check(graph) {
+		got = append(got, v.String())
+	}
+	assert.Equal(t, []string{
+		"com.example:core:1.0 > com.example:gpl:2.0: GHSA-2222-gpl1-0001 Deserialization of untrusted data in gpl (fixed in 2.0.1)",
+		"com.example:core:1.0 > com.example:gpl:2.0: GHSA-2222-gpl1-0002 Path traversal in gpl (fixed in 2.0.2)",
+		"com.example:core:1.0 > com.example:util:1.1: GHSA-3333-util-0001 Denial of service in util",
+		"com.example:testkit:1.0 > com.example:fixtures:1.0: GHSA-5555-fixt-0001 Weak random numbers in fixtures",
+	}, got)
+}
+
+func TestOSVDatabaseQuery(t *testing.T) {
+	db, err := LoadOSVDatabase("testdata/osv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := func(version string) []string {
+		var ids []string
+		for _, v := range db.Query(Coordinate{GroupID: "com.example", ArtifactID: "gpl", Version: version}) {
+			ids = append(ids, v.ID)
+		}
+		return ids
+	}
+	assert.Equal(t, []string{"GHSA-2222-gpl1-0001"}, ids("0.9"))
+	assert.Equal(t, []string{"GHSA-2222-gpl1-0001", "GHSA-2222-gpl1-0002"}, ids("2.0.0"))
+	assert.Equal(t, []string{"GHSA-2222-gpl1-0002"}, ids("2.0.1"))
+	assert.Nil(t, ids("2.0.2"))
+	assert.Equal(t, []string{"GHSA-2222-gpl1-0001"}, ids("3.0.1"))
+	assert.Nil(t, ids("3.0.2"))
+	assert.Nil(t, ids("3.0.10"))
+	assert.Nil(t, db.Query(Coordinate{GroupID: "com.example", ArtifactID: "core", Version: "1.0"}))
+}
+
+func TestLoadOSVDatabaseZip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "all.zip")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	z := zip.NewWriter(f)
+	for _, name := range []string{"GHSA-3333-util-0001.json", "GHSA-4444-myst-0001.json"} {
+		b, err := os.ReadFile(filepath.Join("testdata/osv", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		w, err := z.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write(b); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := z.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := LoadOSVDatabase(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vulns := db.Query(Coordinate{GroupID: "com.example", ArtifactID: "mystery", Version: "0.9"})
+	if assert.Len(t, vulns, 1) {
+		assert.Equal(t, "GHSA-4444-myst-0001", vulns[0].ID)
+	}
+
+	_, err = LoadOSVDatabase("testdata/missing.zip")
+	assert.Error(t, err)
+}
@@ -0,0 +1,68 @@
+package gopom
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+)
+
+// ParseAll parses the POM files at paths concurrently, using at most workers
+// goroutines, or runtime.GOMAXPROCS(0) of them when workers is not positive.
+//
+// The returned projects are in the same order as paths. When some files
+// fail to parse, the error joins one error per failing file, and the
+// projects at their index are nil; the other projects are still returned.
+// Cancelling ctx stops parsing the remaining files, in which case the
+// returned error includes ctx.Err().
+func ParseAll(ctx context.Context, paths []string, workers int) ([]*Project, error) {
+	return parseAll(ctx, paths, workers, Parse)
+}
+
+func parseAll(ctx context.Context, paths []string, workers int, parse func(path string) (*Project, error)) ([]*Project, error) {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if workers > len(paths) {
+		workers = len(paths)
+	}
+
+	projects := make([]*Project, len(paths))
+	errs := make([]error, len(paths))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if ctx.Err() != nil {
+					continue
+				}
+				p, err := parse(paths[i])
+				if err != nil {
+					errs[i] = fmt.Errorf("failed to parse %s: %w", paths[i], err)
+					continue
+				}
+				projects[i] = p
+			}
+		}()
+	}
+
+dispatch:
+	for i := range paths {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		errs = append([]error{err}, errs...)
+	}
+	return projects, errors.Join(errs...)
+}
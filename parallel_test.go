@@ -0,0 +1,196 @@
+package gopom
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAll(t *testing.T) {
+	paths := []string{
+		"./testdata/reactor/pom.xml",
+		"./testdata/reactor/api/pom.xml",
+		"./testdata/missing.xml",
+		"./testdata/reactor/core/pom.xml",
+		filename,
+	}
+	projects, err := ParseAll(context.Background(), paths, 2)
+	if err == nil {
+		t.Fatal("expected an error for the missing file")
+	}
+	assert.Contains(t, err.Error(), "testdata/missing.xml")
+	assert.True(t, errors.Is(err, os.ErrNotExist))
+
+	assert.Equal(t, len(paths), len(projects))
+	assert.Equal(t, "root", projects[0].ArtifactID)
+	assert.Equal(t, "api", projects[1].ArtifactID)
+	assert.Nil(t, projects[2])
+	assert.Equal(t, "core", projects[3].ArtifactID)
+	assert.Equal(t, "test-application", projects[4].ArtifactID)
+}
+
+func TestParseAllCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := ParseAll(ctx, []string{filename, filename}, 1)
+	assert.True(t, errors.Is(err, context.Canceled))
+}
+
+const syntheticModule = `<?xml version="1.0" encoding="UTF-8"?>
+<project xmlns="http://maven.apache.org/POM/4.0.0" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:schemaLocation="http://maven.apache.org/POM/4.0.0 http://maven.apache.org/xsd/maven-4.0.0.xsd">
+  <modelVersion>4.0.0</modelVersion>
+  <parent>
+    <groupId>com.example</groupId>
+    <artifactId>root</artifactId>
+    <version>1.0.0</version>
+  </parent>
+  <artifactId>%s</artifactId>
+  <name>Synthetic module %[1]s</name>
+  <properties>
+    <java.version>17</java.version>
+    <guava.version>33.2.0-jre</guava.version>
+  </properties>
+  <dependencies>
+    %s
+    <dependency>
+      <groupId>com.google.guava</groupId>
+      <artifactId>guava</artifactId>
+      <version>${guava.version}</version>
+    </dependency>
+    <dependency>
+      <groupId>org.junit.jupiter</groupId>
+      <artifactId>junit-jupiter</artifactId>
+      <version>5.10.2</version>
+      <scope>test</scope>
+    </dependency>
+  </dependencies>
+  <build>
+    <plugins>
+      <plugin>
+        <artifactId>maven-compiler-plugin</artifactId>
+        <version>3.13.0</version>
+        <configuration>
+          <release>${java.version}</release>
+        </configuration>
+      </plugin>
+    </plugins>
+  </build>
+</project>
+`
+
+// writeSyntheticTree writes a reactor with n modules to dir, each module
+// depending on the previous one, and returns the paths of the module POMs.
+func writeSyntheticTree(tb testing.TB, dir string, n int) []string {
+	tb.Helper()
+	var modules, paths []string
+	for i := 0; i < n; i++ {
+		name := fmt.Sprintf("module-%04d", i)
+		dep := ""
+		if i > 0 {
+			dep = fmt.Sprintf("<dependency><groupId>com.example</groupId><artifactId>module-%04d</artifactId><version>1.0.0</version></dependency>", i-1)
+		}
+		path := filepath.Join(dir, name, "pom.xml")
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			tb.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(fmt.Sprintf(syntheticModule, name, dep)), 0o644); err != nil {
+			tb.Fatal(err)
+		}
+		modules = append(modules, "<module>"+name+"</module>")
+		paths = append(paths, path)
+	}
+	root := fmt.Sprintf(`<project xmlns="http://maven.apache.org/POM/4.0.0">
+  <modelVersion>4.0.0</modelVersion>
+  <groupId>com.example</groupId>
+  <artifactId>root</artifactId>
+  <version>1.0.0</version>
+  <packaging>pom</packaging>
+  <modules>%s</modules>
+</project>
+`, strings.Join(modules, ""))
+	if err := os.WriteFile(filepath.Join(dir, "pom.xml"), []byte(root), 0o644); err != nil {
+		tb.Fatal(err)
+	}
+	return paths
+}
+
+func TestParseAllSyntheticTree(t *testing.T) {
+	dir := t.TempDir()
+	paths := writeSyntheticTree(t, dir, 50)
+	projects, err := ParseAll(context.Background(), paths, 4)
+	if err != nil {
+		t.Fatalf("failed parsing the tree: %v", err)
+	}
+	for i, p := range projects {
+		assert.Equal(t, fmt.Sprintf("module-%04d", i), p.ArtifactID)
+	}
+
+	r, err := LoadReactor(dir)
+	if err != nil {
+		t.Fatalf("failed loading the reactor: %v", err)
+	}
+	assert.Equal(t, 51, len(r.Modules))
+	assert.Equal(t, "module-0049", r.Modules[50].Project.ArtifactID)
+}
+
+const syntheticModules = 1000
+
+func benchmarkParse(b *testing.B, parse func(paths []string) error) {
+	paths := writeSyntheticTree(b, b.TempDir(), syntheticModules)
+	var size int64
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			b.Fatal(err)
+		}
+		size += info.Size()
+	}
+	b.SetBytes(size)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := parse(paths); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.ReportMetric(float64(b.N*len(paths))/b.Elapsed().Seconds(), "poms/s")
+}
+
+func BenchmarkParseSequential(b *testing.B) {
+	benchmarkParse(b, func(paths []string) error {
+		for _, path := range paths {
+			if _, err := Parse(path); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func BenchmarkParseAll(b *testing.B) {
+	for _, workers := range []int{1, 4, 0} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			benchmarkParse(b, func(paths []string) error {
+				_, err := ParseAll(context.Background(), paths, workers)
+				return err
+			})
+		})
+	}
+}
+
+func BenchmarkLoadReactor(b *testing.B) {
+	dir := b.TempDir()
+	writeSyntheticTree(b, dir, syntheticModules)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := LoadReactor(dir); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.ReportMetric(float64(b.N*syntheticModules)/b.Elapsed().Seconds(), "poms/s")
+}
@@ -0,0 +1,393 @@
+package gopom
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ApplyProject rewrites the POM in b to match p, changing only the elements
+// that differ: the rest of the document, formatting and comments included,
+// is kept as is, like with ApplyEdits.
+//
+// List items are matched by key, like Merge does: the items p lacks are
+// removed along with their comments, and the new ones are appended to their
+// list. Other new elements are written after their preceding sibling,
+// indented like the document. Configurations and the lists Merge does not
+// merge by key, like licenses, are replaced as a whole when they differ.
+// The order of the existing elements is kept.
+func ApplyProject(b []byte, p *Project) ([]byte, error) {
+	current, err := parse(b)
+	if err != nil {
+		return nil, err
+	}
+	pt := &patcher{b: b, index: current.source, unit: indentUnit(b, current.source), nl: "\n"}
+	if bytes.Contains(b, []byte("\r\n")) {
+		pt.nl = "\r\n"
+	}
+	if err := pt.fields("project", reflect.ValueOf(current).Elem(), reflect.ValueOf(p).Elem()); err != nil {
+		return nil, err
+	}
+	return replace(b, pt.replacements), nil
+}
+
+// patcher collects the replacements turning the source of a project into
+// another project.
+type patcher struct {
+	b            []byte
+	index        *sourceIndex
+	unit, nl     string
+	replacements []replacement
+}
+
+// fields patches the child elements of the element at path, from the
+// struct cur to the struct want.
+func (pt *patcher) fields(path string, cur, want reflect.Value) error {
+	indent := indentation(pt.b, pt.index.Elements[path].Start) + pt.unit
+	// New elements go after the last existing sibling, or first when there
+	// is none.
+	at := -1
+	var pending strings.Builder
+	flush := func() {
+		if pending.Len() > 0 {
+			pt.insert(path, at, pending.String())
+			pending.Reset()
+		}
+	}
+
+	wanted := xmlFields(want)
+	for i, f := range xmlFields(cur) {
+		w, childPath := wanted[i].v, elementPath(path, f.name, 0)
+		s, ok := pt.index.Elements[childPath]
+		if !ok {
+			if w.IsZero() {
+				continue
+			}
+			text, err := pt.marshal(indent, func(e *xml.Encoder) error { return encodeField(e, f, w) })
+			if err != nil {
+				return err
+			}
+			pending.WriteString(text)
+			continue
+		}
+		flush()
+		at = pt.afterTrailing(s.End)
+		switch {
+		case mergeEqual(f.v, w):
+		case w.IsZero():
+			pt.remove(childPath)
+		default:
+			if err := pt.element(childPath, f, w); err != nil {
+				return err
+			}
+		}
+	}
+	flush()
+	return nil
+}
+
+// element patches the element at path, holding the field f, to want.
+func (pt *patcher) element(path string, f xmlField, want reflect.Value) error {
+	cur := f.v
+	t := cur.Type()
+	switch {
+	case t.Kind() == reflect.String || t.Kind() == reflect.Bool:
+		pt.setText(path, want)
+		return nil
+	case t == propertiesType && !cur.IsNil():
+		return pt.properties(path, cur.Interface().(*Properties), want.Interface().(*Properties))
+	case f.item != "" && mergeListed(t.Elem().Elem()):
+		return pt.list(path, f.item, cur, want)
+	case f.item == "" && t.Kind() == reflect.Pointer && t.Elem().Kind() == reflect.Struct && t != configurationType && !cur.IsNil():
+		return pt.fields(path, cur.Elem(), want.Elem())
+	}
+
+	// Replace the whole element.
+	s := pt.index.Elements[path]
+	indent := indentation(pt.b, s.Start)
+	text, err := pt.marshal(indent, func(e *xml.Encoder) error { return encodeField(e, f, want) })
+	if err != nil {
+		return err
+	}
+	pt.add(s.Start, s.End, strings.TrimPrefix(text, pt.nl+indent))
+	return nil
+}
+
+// list patches the items called item of the list at path, matching them by
+// key.
+func (pt *patcher) list(path, item string, cur, want reflect.Value) error {
+	var wanted []reflect.Value
+	if !want.IsNil() {
+		for i := 0; i < want.Elem().Len(); i++ {
+			wanted = append(wanted, want.Elem().Index(i))
+		}
+	}
+	byKey := map[string][]int{}
+	for i, w := range wanted {
+		key := mergeKey(w)
+		byKey[key] = append(byKey[key], i)
+	}
+
+	matched := make([]bool, len(wanted))
+	at := -1
+	if !cur.IsNil() {
+		for i := 0; i < cur.Elem().Len(); i++ {
+			c := cur.Elem().Index(i)
+			itemPath := elementPath(path, item, i)
+			key := mergeKey(c)
+			candidates := byKey[key]
+			if len(candidates) == 0 {
+				pt.remove(itemPath)
+				continue
+			}
+			j := candidates[0]
+			byKey[key], matched[j] = candidates[1:], true
+			at = pt.afterTrailing(pt.index.Elements[itemPath].End)
+			switch w := wanted[j]; {
+			case mergeEqual(c, w):
+			case c.Kind() == reflect.Struct:
+				if err := pt.fields(itemPath, c, w); err != nil {
+					return err
+				}
+			default:
+				pt.setText(itemPath, w)
+			}
+		}
+	}
+
+	indent := indentation(pt.b, pt.index.Elements[path].Start) + pt.unit
+	text, err := pt.marshal(indent, func(e *xml.Encoder) error {
+		for i, w := range wanted {
+			if !matched[i] {
+				if err := e.EncodeElement(w.Interface(), xml.StartElement{Name: xml.Name{Local: item}}); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if text != "" {
+		pt.insert(path, at, text)
+	}
+	return nil
+}
+
+// properties patches the properties at path, matching them by name.
+func (pt *patcher) properties(path string, cur, want *Properties) error {
+	at := -1
+	seen := map[string]bool{}
+	for _, key := range cur.Order {
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		keyPath := elementPath(path, key, 0)
+		value, ok := want.Entries[key]
+		if !ok {
+			pt.remove(keyPath)
+			continue
+		}
+		at = pt.afterTrailing(pt.index.Elements[keyPath].End)
+		if value != cur.Entries[key] {
+			pt.setText(keyPath, reflect.ValueOf(value))
+		}
+	}
+
+	indent := indentation(pt.b, pt.index.Elements[path].Start) + pt.unit
+	text, err := pt.marshal(indent, func(e *xml.Encoder) error {
+		for _, key := range want.Order {
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			c := want.comments[key]
+			if err := encodeLeading(e, c); err != nil {
+				return err
+			}
+			start := xml.StartElement{Name: xml.Name{Local: key}}
+			tokens := append([]xml.Token{start, xml.CharData(want.Entries[key]), start.End()}, c.trailingTokens()...)
+			for _, t := range tokens {
+				if err := e.EncodeToken(t); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if text != "" {
+		pt.insert(path, at, text)
+	}
+	return nil
+}
+
+// setText replaces the text of the element at path with the string or
+// boolean v.
+func (pt *patcher) setText(path string, v reflect.Value) {
+	var value string
+	if v.Kind() == reflect.Bool {
+		value = strconv.FormatBool(v.Bool())
+	} else {
+		value = escapeText(v.String())
+	}
+	s := pt.index.Elements[path]
+	if s.ContentStart == s.End {
+		// Self-closing element.
+		name := elementName(path)
+		pt.add(s.Start, s.End, "<"+name+">"+value+"</"+name+">")
+		return
+	}
+	pt.add(s.ContentStart, s.ContentEnd, value)
+}
+
+// insert inserts text, made of lines, in the element at path: at offset at,
+// or first when at is negative.
+func (pt *patcher) insert(path string, at int, text string) {
+	s := pt.index.Elements[path]
+	indent := indentation(pt.b, s.Start)
+	switch {
+	case s.ContentStart == s.End:
+		// Self-closing element.
+		name := elementName(path)
+		pt.add(s.Start, s.End, "<"+name+">"+text+pt.nl+indent+"</"+name+">")
+	case at >= 0:
+		pt.add(at, at, text)
+	case len(bytes.TrimSpace(pt.b[s.ContentStart:s.ContentEnd])) == 0:
+		pt.add(s.ContentStart, s.ContentEnd, text+pt.nl+indent)
+	default:
+		pt.add(s.ContentStart, s.ContentStart, text)
+	}
+}
+
+// insertAt returns the offset where insert puts a new child element called
+// name in the element at path, holding v: after its last preceding sibling
+// in model order, like fields does, or first when there is none. The
+// children of configurations and properties have no order, so they go
+// after the last child.
+func (pt *patcher) insertAt(path string, v reflect.Value, name string) int {
+	s := pt.index.Elements[path]
+	t := v.Type()
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || t == configurationType.Elem() || t == propertiesType.Elem() || t == configElementType.Elem() {
+		at := s.ContentEnd
+		for at > s.ContentStart && isSpace(pt.b[at-1]) {
+			at--
+		}
+		if at <= s.ContentStart {
+			return -1
+		}
+		return at
+	}
+
+	at := -1
+	for _, f := range xmlFields(reflect.New(t).Elem()) {
+		if f.name == name {
+			break
+		}
+		if sibling, ok := pt.index.Elements[elementPath(path, f.name, 0)]; ok {
+			at = pt.afterTrailing(sibling.End)
+		}
+	}
+	return at
+}
+
+// remove removes the element at path, along with the comments on its line
+// and the ones on their own lines right before it.
+func (pt *patcher) remove(path string) {
+	s := pt.index.Elements[path]
+	start := s.Start
+	for {
+		i := start
+		for i > 0 && isSpace(pt.b[i-1]) {
+			i--
+		}
+		c := bytes.LastIndex(pt.b[:i], []byte("<!--"))
+		if c < 0 || !bytes.HasSuffix(pt.b[:i], []byte("-->")) || !lineStart(pt.b, c) {
+			start = i
+			break
+		}
+		start = c
+	}
+	pt.add(start, pt.afterTrailing(s.End), "")
+}
+
+// afterTrailing returns the offset after the comments following offset on
+// the same line, if any.
+func (pt *patcher) afterTrailing(offset int) int {
+	for {
+		i := offset
+		for i < len(pt.b) && (pt.b[i] == ' ' || pt.b[i] == '\t') {
+			i++
+		}
+		if !bytes.HasPrefix(pt.b[i:], []byte("<!--")) {
+			return offset
+		}
+		end := bytes.Index(pt.b[i:], []byte("-->"))
+		if end < 0 {
+			return offset
+		}
+		offset = i + end + len("-->")
+	}
+}
+
+func (pt *patcher) add(start, end int, text string) {
+	pt.replacements = append(pt.replacements, replacement{start, end, text})
+}
+
+// marshal returns the elements encode writes, each on its own line
+// starting with a line break, as children of an element indented with
+// indent.
+func (pt *patcher) marshal(indent string, encode func(e *xml.Encoder) error) (string, error) {
+	parent := strings.TrimSuffix(indent, pt.unit)
+	start := xml.StartElement{Name: xml.Name{Local: "patch"}}
+	b, err := marshalIndent(parent, pt.unit, func(e *xml.Encoder) error {
+		if err := e.EncodeToken(start); err != nil {
+			return err
+		}
+		if err := encode(e); err != nil {
+			return fmt.Errorf("failed to marshal: %w", err)
+		}
+		return e.EncodeToken(start.End())
+	})
+	if err != nil {
+		return "", err
+	}
+	text := string(b)
+	text = strings.TrimPrefix(text, parent+"<patch>")
+	text = strings.TrimSuffix(strings.TrimSuffix(text, "</patch>"), "\n"+parent)
+	return strings.ReplaceAll(text, "\n", pt.nl), nil
+}
+
+// encodeField encodes v as the element of the field f, wrapping the items
+// of lists.
+func encodeField(e *xml.Encoder, f xmlField, v reflect.Value) error {
+	start := xml.StartElement{Name: xml.Name{Local: f.name}}
+	if f.item == "" {
+		return e.EncodeElement(v.Interface(), start)
+	}
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	for i := 0; !v.IsNil() && i < v.Elem().Len(); i++ {
+		if err := e.EncodeElement(v.Elem().Index(i).Interface(), xml.StartElement{Name: xml.Name{Local: f.item}}); err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(start.End())
+}
+
+// lineStart reports whether only whitespace precedes offset on its line.
+func lineStart(b []byte, offset int) bool {
+	i := offset - len(indentation(b, offset))
+	return i == 0 || b[i-1] == '\n'
+}
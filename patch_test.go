@@ -0,0 +1,66 @@
+package gopom
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyProject(t *testing.T) {
+	b, err := os.ReadFile("testdata/patch/pom.xml")
+	assert.NoError(t, err)
+	want, err := os.ReadFile("testdata/patch/want.xml")
+	assert.NoError(t, err)
+
+	p, err := parse(b)
+	assert.NoError(t, err)
+	unchanged, err := ApplyProject(b, p)
+	assert.NoError(t, err)
+	assert.Equal(t, string(b), string(unchanged))
+
+	p.Version = "1.1"
+	p.Properties.Entries["java.version"] = "21"
+	delete(p.Properties.Entries, "legacy")
+	p.Properties.Order = append(p.Properties.Order[:1], "project.build.sourceEncoding")
+	p.Properties.Entries["project.build.sourceEncoding"] = "UTF-8"
+	p.Properties.Comments("project.build.sourceEncoding").Leading = []string{"Source encoding."}
+	p.SCM = &Scm{URL: "https://example.com/patch"}
+	deps := *p.Dependencies
+	deps[0].Version = "33.2.0-jre"
+	junit := Dependency{GroupID: "org.junit.jupiter", ArtifactID: "junit-jupiter", Version: "5.10.2", Scope: "test"}
+	junit.Comments().Leading = []string{"Tests."}
+	*p.Dependencies = []Dependency{deps[0], junit}
+	(*p.Build.Plugins)[0].Configuration = &Configuration{RawConfiguration: "\n          <release>21</release>\n        "}
+
+	got, err := ApplyProject(b, p)
+	assert.NoError(t, err)
+	assert.Equal(t, string(want), string(got))
+
+	patched, err := parse(got)
+	assert.NoError(t, err)
+	assert.Empty(t, Diff(p, patched))
+}
+
+func TestApplyProjectMerge(t *testing.T) {
+	var projects [3]*Project
+	for i, name := range []string{"base", "ours", "theirs"} {
+		var err error
+		projects[i], err = Parse("testdata/merge/" + name + ".xml")
+		assert.NoError(t, err)
+	}
+	merged, _ := Merge(projects[0], projects[1], projects[2])
+
+	b, err := os.ReadFile("testdata/merge/ours.xml")
+	assert.NoError(t, err)
+	got, err := ApplyProject(b, merged)
+	assert.NoError(t, err)
+	assert.Contains(t, string(got), "        <!-- Only on our branch. -->\n        <ours>true</ours>\n")
+	assert.Contains(t, string(got), "        <!-- Mocks for the web tests. -->\n        <dependency>\n            <groupId>org.mockito</groupId>\n")
+
+	patched, err := parse(got)
+	assert.NoError(t, err)
+	assert.Empty(t, Diff(merged, patched))
+	assert.True(t, mergeEqual(reflect.ValueOf(merged).Elem(), reflect.ValueOf(patched).Elem()))
+}
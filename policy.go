@@ -0,0 +1,228 @@
+package gopom
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LicensePolicy tells which licenses dependencies may use. Identifiers are
+// SPDX license identifiers, and may end with * to match any identifier with
+// that prefix, like GPL-*.
+type LicensePolicy struct {
+	// Allow lists the licenses dependencies may use. When empty, all the
+	// licenses that are not denied are allowed.
+	Allow []string `json:"allow,omitempty" yaml:"allow,omitempty"`
+	// Deny lists the licenses dependencies must not use.
+	Deny []string `json:"deny,omitempty" yaml:"deny,omitempty"`
+	// Scopes are rules for the dependencies in a given Maven scope, which
+	// take precedence over Allow and Deny. For instance, denying GPL-* and
+	// allowing it in the test scope only lets test dependencies use it.
+	Scopes map[string]LicenseRule `json:"scopes,omitempty" yaml:"scopes,omitempty"`
+	// AllowUnknown accepts dependencies without licenses or with
+	// unrecognized ones, unless they are denied with a LicenseRef
+	// identifier.
+	AllowUnknown bool `json:"allowUnknown,omitempty" yaml:"allowUnknown,omitempty"`
+}
+
+// LicenseRule lists allowed and denied licenses, see LicensePolicy.
+type LicenseRule struct {
+	Allow []string `json:"allow,omitempty" yaml:"allow,omitempty"`
+	Deny  []string `json:"deny,omitempty" yaml:"deny,omitempty"`
+}
+
+// LicenseViolation is a dependency whose licenses break a policy.
+type LicenseViolation struct {
+	// Dependency is the offending dependency, with its resolved scope.
+	Dependency Dependency
+	// Path lists the dependencies leading from the project to the
+	// offending one, which is the last element.
+	Path []Coordinate
+	// Expression is the SPDX license expression of the dependency, or an
+	// empty string if it declares no license.
+	Expression string
+	// Licenses are the offending licenses.
+	Licenses []string
+}
+
+func (v LicenseViolation) String() string {
+	var path []string
+	for _, c := range v.Path {
+		path = append(path, c.String())
+	}
+	scope := v.Dependency.Scope
+	if scope == "" {
+		scope = "compile"
+	}
+	if v.Expression == "" {
+		return fmt.Sprintf("%s: no license (%s scope)", strings.Join(path, " > "), scope)
+	}
+	return fmt.Sprintf("%s: %s not allowed (%s scope)", strings.Join(path, " > "), strings.Join(v.Licenses, ", "), scope)
+}
+
+// ParseLicensePolicy reads a policy in YAML, or in JSON, like:
+//
+//	deny: [GPL-*, AGPL-*]
+//	scopes:
+//	  test:
+//	    allow: [GPL-*]
+func ParseLicensePolicy(r io.Reader) (*LicensePolicy, error) {
+	d := yaml.NewDecoder(r)
+	d.KnownFields(true)
+	var policy LicensePolicy
+	if err := d.Decode(&policy); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("failed to parse the license policy: %w", err)
+	}
+	return &policy, nil
+}
+
+// Check evaluates the licenses of every dependency of a resolved graph,
+// see Resolve, and returns the violations in depth-first order. A dependency
+// complies when its license expression can be satisfied with allowed
+// licenses: one of the alternatives of an OR, and all the terms of an
+// AND. Licenses with an exception, like GPL-2.0-only WITH
+// Classpath-exception-2.0, match both the whole term and the license.
+func (policy *LicensePolicy) Check(graph []*DependencyNode) []LicenseViolation {
+	var violations []LicenseViolation
+	seen := map[*DependencyNode]bool{}
+	var walk func(nodes []*DependencyNode, path []Coordinate)
+	walk = func(nodes []*DependencyNode, path []Coordinate) {
+		for _, n := range nodes {
+			if seen[n] {
+				continue
+			}
+			seen[n] = true
+			path := append(path[:len(path):len(path)], n.Dependency.Coordinate())
+			scope := n.Dependency.Scope
+			if scope == "" {
+				scope = "compile"
+			}
+			l := NormalizeLicenses(n.Licenses)
+			switch {
+			case l.Expression == "":
+				if !policy.AllowUnknown {
+					violations = append(violations, LicenseViolation{Dependency: n.Dependency, Path: path})
+				}
+			default:
+				if denied := policy.evaluate(parseLicenseExpression(l.Expression), scope); len(denied) > 0 {
+					violations = append(violations, LicenseViolation{Dependency: n.Dependency, Path: path, Expression: l.Expression, Licenses: denied})
+				}
+			}
+			walk(n.Dependencies, path)
+		}
+	}
+	walk(graph, nil)
+	return violations
+}
+
+// evaluate returns the licenses that prevent e from being satisfied in the
+// given scope, or nil if it is.
+func (policy *LicensePolicy) evaluate(e *licenseExpression, scope string) []string {
+	switch e.op {
+	case "":
+		if policy.allowed(e.term, scope) {
+			return nil
+		}
+		return []string{e.term}
+	case "OR":
+		var denied []string
+		for _, arg := range e.args {
+			d := policy.evaluate(arg, scope)
+			if d == nil {
+				return nil
+			}
+			denied = append(denied, d...)
+		}
+		return denied
+	}
+	var denied []string
+	for _, arg := range e.args {
+		denied = append(denied, policy.evaluate(arg, scope)...)
+	}
+	return denied
+}
+
+// allowed tells whether a license term is allowed in the given scope.
+func (policy *LicensePolicy) allowed(term, scope string) bool {
+	if rule, ok := policy.Scopes[scope]; ok {
+		if matchLicense(rule.Deny, term) {
+			return false
+		}
+		if matchLicense(rule.Allow, term) {
+			return true
+		}
+	}
+	if matchLicense(policy.Deny, term) {
+		return false
+	}
+	if matchLicense(policy.Allow, term) {
+		return true
+	}
+	if strings.HasPrefix(term, "LicenseRef-") {
+		return policy.AllowUnknown
+	}
+	return len(policy.Allow) == 0
+}
+
+// matchLicense tells whether one of the patterns matches term, or its
+// license when it has an exception.
+func matchLicense(patterns []string, term string) bool {
+	id, _, _ := strings.Cut(term, " WITH ")
+	for _, pattern := range patterns {
+		for _, s := range []string{term, id} {
+			if prefix, ok := strings.CutSuffix(pattern, "*"); ok && strings.HasPrefix(s, prefix) || pattern == s {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// licenseExpression is a parsed SPDX license expression: either a term,
+// like MIT or GPL-2.0-only WITH Classpath-exception-2.0, or an AND or OR
+// operation.
+type licenseExpression struct {
+	op   string
+	term string
+	args []*licenseExpression
+}
+
+// parseLicenseExpression parses an expression as built by
+// NormalizeLicenses, where AND binds tighter than OR.
+func parseLicenseExpression(s string) *licenseExpression {
+	tokens := strings.Fields(strings.NewReplacer("(", " ( ", ")", " ) ").Replace(s))
+	var parseOr func() *licenseExpression
+	parseTerm := func() *licenseExpression {
+		if len(tokens) > 0 && tokens[0] == "(" {
+			tokens = tokens[1:]
+			e := parseOr()
+			if len(tokens) > 0 && tokens[0] == ")" {
+				tokens = tokens[1:]
+			}
+			return e
+		}
+		var term []string
+		for len(tokens) > 0 && tokens[0] != "(" && tokens[0] != ")" && tokens[0] != "AND" && tokens[0] != "OR" {
+			term = append(term, tokens[0])
+			tokens = tokens[1:]
+		}
+		return &licenseExpression{term: strings.Join(term, " ")}
+	}
+	parseOp := func(op string, parse func() *licenseExpression) *licenseExpression {
+		e := &licenseExpression{op: op, args: []*licenseExpression{parse()}}
+		for len(tokens) > 0 && tokens[0] == op {
+			tokens = tokens[1:]
+			e.args = append(e.args, parse())
+		}
+		if len(e.args) == 1 {
+			return e.args[0]
+		}
+		return e
+	}
+	parseOr = func() *licenseExpression {
+		return parseOp("OR", func() *licenseExpression { return parseOp("AND", parseTerm) })
+	}
+	return parseOr()
+}
@@ -0,0 +1,83 @@
+package gopom
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLicensePolicyCheck(t *testing.T) {
+	p, err := Parse("testdata/policy/pom.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	graph, err := Resolve(p, ResolveOptions{Repository: testRepository})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open("testdata/policy/policy.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	policy, err := ParseLicensePolicy(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, v := range policy.Check(graph) {
+		got = append(got, v.String())
+	}
+	assert.Equal(t, []string{
+		"com.example:core:1.0 > com.example:gpl:2.0: GPL-3.0-only not allowed (compile scope)",
+		"com.example:core:1.0 > com.example:mystery:1.0: LicenseRef-Proprietary-Foo not allowed (compile scope)",
+		"com.example:testkit:1.0 > com.example:fixtures:1.0: no license (test scope)",
+	}, got)
+
+	policy.AllowUnknown = true
+	violations := policy.Check(graph)
+	assert.Len(t, violations, 1)
+	assert.Equal(t, "GPL-3.0-only", violations[0].Expression)
+}
+
+func TestLicensePolicyExpressions(t *testing.T) {
+	policy := &LicensePolicy{
+		Allow: []string{"Apache-2.0", "MIT", "CDDL-1.1", "GPL-2.0-only WITH Classpath-exception-2.0"},
+		Scopes: map[string]LicenseRule{
+			"provided": {Deny: []string{"CDDL-*"}},
+		},
+	}
+	for _, test := range []struct {
+		expression, scope string
+		want              []string
+	}{
+		{"Apache-2.0", "compile", nil},
+		{"EPL-2.0", "compile", []string{"EPL-2.0"}},
+		{"EPL-2.0 OR MIT", "compile", nil},
+		{"EPL-2.0 OR LGPL-2.1-only", "compile", []string{"EPL-2.0", "LGPL-2.1-only"}},
+		{"MIT AND (EPL-2.0 OR Apache-2.0)", "compile", nil},
+		{"MIT AND EPL-2.0", "compile", []string{"EPL-2.0"}},
+		{"GPL-2.0-only WITH Classpath-exception-2.0", "compile", nil},
+		{"GPL-2.0-only", "compile", []string{"GPL-2.0-only"}},
+		{"CDDL-1.1", "provided", []string{"CDDL-1.1"}},
+		{"CDDL-1.1 OR GPL-2.0-only WITH Classpath-exception-2.0", "provided", nil},
+		{"LicenseRef-Example", "compile", []string{"LicenseRef-Example"}},
+	} {
+		got := policy.evaluate(parseLicenseExpression(test.expression), test.scope)
+		assert.Equal(t, test.want, got, test.expression)
+	}
+}
+
+func TestParseLicensePolicy(t *testing.T) {
+	policy, err := ParseLicensePolicy(strings.NewReader(`{"allow": ["MIT"], "allowUnknown": true}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, &LicensePolicy{Allow: []string{"MIT"}, AllowUnknown: true}, policy)
+
+	_, err = ParseLicensePolicy(strings.NewReader("denied: [MIT]"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,284 @@
+package gopom
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// The polyglot YAML format of the Takari polyglot-maven extension maps the
+// elements of the POM to YAML the same way the YAML representation of a
+// Project does, see encoding.go, without the XML namespace attributes:
+//
+//	modelVersion: 4.0.0
+//	groupId: com.example
+//	artifactId: demo
+//	version: 1.0.0
+//	properties:
+//	  java.version: 17
+//	dependencies:
+//	  - {groupId: junit, artifactId: junit, version: 4.13.2, scope: test}
+//
+// Coordinates can also be written in short, as an id key or as the whole
+// parent, dependency, plugin or extension:
+//
+//	id: com.example:demo:1.0.0
+//	parent: com.example:parent:1.0.0
+//	dependencies:
+//	  - junit:junit:4.13.2
+//	  - {id: org.slf4j:slf4j-api:2.0.9, scope: provided}
+//
+// The id of the project can have a fourth part, its packaging, as in
+// com.example:demo:war:1.0.0. The ones of dependencies follow
+// ParseCoordinate.
+
+const (
+	polyglotGroupID    = "io.takari.polyglot"
+	polyglotArtifactID = "polyglot-yaml"
+)
+
+// polyglotFiles are the names of polyglot YAML POMs, in the order they are
+// looked for.
+var polyglotFiles = []string{"pom.yaml", "pom.yml"}
+
+// ParsePolyglotYAML parses the polyglot YAML POM read from r.
+func ParsePolyglotYAML(r io.Reader) (*Project, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return parsePolyglotYAML(b)
+}
+
+func parsePolyglotYAML(b []byte) (*Project, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(b, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) > 0 {
+		expanded, err := expandPolyglotIDs(doc.Content[0], "project")
+		if err != nil {
+			return nil, err
+		}
+		if expanded {
+			if b, err = yaml.Marshal(&doc); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	var p Project
+	d := yaml.NewDecoder(bytes.NewReader(b))
+	d.KnownFields(true)
+	if err := d.Decode(&p); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// polyglotItems maps the lists whose items can be written as coordinates
+// to what their items are.
+var polyglotItems = map[string]string{"dependencies": "dependency", "plugins": "plugin", "extensions": "extension"}
+
+// expandPolyglotIDs replaces the coordinate shorthands found in n, the node
+// of a project, dependency, plugin, extension or other element, with the
+// elements they stand for. It reports whether it found any.
+func expandPolyglotIDs(n *yaml.Node, what string) (bool, error) {
+	if n.Kind != yaml.MappingNode {
+		return false, nil
+	}
+	expanded := false
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		key, value := n.Content[i], n.Content[i+1]
+		switch {
+		case key.Value == "id" && what != "" && value.Kind == yaml.ScalarNode:
+			fields, err := polyglotID(value, what)
+			if err != nil {
+				return false, err
+			}
+			n.Content = append(n.Content[:i], append(fields, n.Content[i+2:]...)...)
+			i += len(fields) - 2
+			expanded = true
+		case key.Value == "parent" && value.Kind == yaml.ScalarNode:
+			fields, err := polyglotID(value, "parent")
+			if err != nil {
+				return false, err
+			}
+			n.Content[i+1] = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Content: fields}
+			expanded = true
+		case key.Value == "configuration" || key.Value == "properties":
+			// Free-form content.
+		case value.Kind == yaml.SequenceNode:
+			item := polyglotItems[key.Value]
+			for j, v := range value.Content {
+				if item != "" && v.Kind == yaml.ScalarNode {
+					fields, err := polyglotID(v, item)
+					if err != nil {
+						return false, err
+					}
+					value.Content[j] = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Content: fields}
+					expanded = true
+					continue
+				}
+				e, err := expandPolyglotIDs(v, item)
+				if err != nil {
+					return false, err
+				}
+				expanded = expanded || e
+			}
+		default:
+			e, err := expandPolyglotIDs(value, "")
+			if err != nil {
+				return false, err
+			}
+			expanded = expanded || e
+		}
+	}
+	return expanded, nil
+}
+
+// polyglotID returns the keys and values of the coordinates of what, a
+// project, parent, dependency, plugin or extension, held by the scalar n.
+func polyglotID(n *yaml.Node, what string) ([]*yaml.Node, error) {
+	c, err := ParseCoordinate(n.Value)
+	typeKey := "type"
+	switch {
+	case err != nil:
+	case what == "project":
+		typeKey = "packaging"
+		if c.Classifier != "" {
+			err = fmt.Errorf("invalid id %q: expected groupId:artifactId[:packaging]:version", n.Value)
+		}
+	case what != "dependency" && (c.Type != "" || c.Classifier != ""):
+		err = fmt.Errorf("invalid %s %q: expected groupId:artifactId[:version]", what, n.Value)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("line %d: %w", n.Line, err)
+	}
+
+	var fields []*yaml.Node
+	for _, f := range []struct{ key, value string }{
+		{"groupId", c.GroupID},
+		{"artifactId", c.ArtifactID},
+		{"version", c.Version},
+		{typeKey, c.Type},
+		{"classifier", c.Classifier},
+	} {
+		if f.value != "" {
+			fields = append(fields,
+				&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: f.key},
+				&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: f.value})
+		}
+	}
+	return fields, nil
+}
+
+// MarshalPolyglotYAML marshals the project in the polyglot YAML format.
+func (p *Project) MarshalPolyglotYAML() ([]byte, error) {
+	c := *p
+	c.Xmlns, c.Xsi, c.SchemaLocation = "", "", ""
+	var buf bytes.Buffer
+	e := yaml.NewEncoder(&buf)
+	e.SetIndent(2)
+	if err := e.Encode(&c); err != nil {
+		return nil, fmt.Errorf("failed to marshal: %w", err)
+	}
+	if err := e.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// isPolyglotYAML reports whether path names a polyglot YAML POM.
+func isPolyglotYAML(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	return ext == ".yaml" || ext == ".yml"
+}
+
+// parseFile parses the POM read from the file at path, in XML or, for
+// .yaml and .yml files, in polyglot YAML.
+func parseFile(path string, b []byte) (*Project, error) {
+	if isPolyglotYAML(path) {
+		return parsePolyglotYAML(b)
+	}
+	return parse(b)
+}
+
+// coreExtensions is the content of .mvn/extensions.xml.
+type coreExtensions struct {
+	XMLName    xml.Name    `xml:"extensions"`
+	Extensions []Extension `xml:"extension"`
+}
+
+// ReadCoreExtensions reads the core extensions declared in the
+// .mvn/extensions.xml file at path.
+func ReadCoreExtensions(path string) ([]Extension, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var e coreExtensions
+	if err := xml.Unmarshal(b, &e); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return e.Extensions, nil
+}
+
+// PolyglotYAMLEnabled reports whether the build dir belongs to declares the
+// polyglot YAML extension. Like Maven, it looks for .mvn/extensions.xml in
+// dir and its parents, and stops at the first .mvn directory found.
+func PolyglotYAMLEnabled(dir string) (bool, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return false, err
+	}
+	for {
+		if info, err := os.Stat(filepath.Join(dir, ".mvn")); err == nil && info.IsDir() {
+			extensions, err := ReadCoreExtensions(filepath.Join(dir, ".mvn", "extensions.xml"))
+			if errors.Is(err, fs.ErrNotExist) {
+				return false, nil
+			}
+			if err != nil {
+				return false, err
+			}
+			for _, e := range extensions {
+				if e.GroupID == polyglotGroupID && e.ArtifactID == polyglotArtifactID {
+					return true, nil
+				}
+			}
+			return false, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return false, nil
+		}
+		dir = parent
+	}
+}
+
+// ProjectFile returns the POM file of the project in dir: its pom.xml or,
+// when there is none and the build declares the polyglot YAML extension,
+// its pom.yaml or pom.yml. It returns the path to pom.xml when no POM is
+// found.
+func ProjectFile(dir string) string {
+	path := filepath.Join(dir, "pom.xml")
+	if _, err := os.Stat(path); err == nil {
+		return path
+	}
+	if enabled, _ := PolyglotYAMLEnabled(dir); enabled {
+		for _, name := range polyglotFiles {
+			if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+				return filepath.Join(dir, name)
+			}
+		}
+	}
+	return path
+}
@@ -0,0 +1,124 @@
+package gopom
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePolyglotYAML(t *testing.T) {
+	p, err := Parse("testdata/polyglot/pom.yaml")
+	if err != nil {
+		t.Fatalf("failed to parse: %v", err)
+	}
+	assert.Equal(t, "polyglot", p.ArtifactID)
+	assert.Equal(t, []string{"java.version", "junit.version"}, p.Properties.Order)
+	assert.Equal(t, "17", p.Properties.Entries["java.version"])
+	assert.Equal(t, []string{"app", "lib"}, *p.Modules)
+
+	results, err := p.Query("build.plugins[0].configuration.compilerArgs.arg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var args []string
+	for _, r := range results {
+		args = append(args, r.Text())
+	}
+	assert.Equal(t, []string{"-Xlint:all", "-Werror"}, args)
+
+	_, err = ParsePolyglotYAML(strings.NewReader("artifactId: demo\nartifact: typo\n"))
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "field artifact not found")
+	}
+}
+
+func TestParsePolyglotYAMLShorthands(t *testing.T) {
+	p, err := Parse("testdata/polyglot-shorthand/pom.yml")
+	if err != nil {
+		t.Fatalf("failed to parse: %v", err)
+	}
+	assert.Equal(t, []string{"io.takari.polyglot", "polyglot-yaml-example", "0.1.0-SNAPSHOT", "jar"}, []string{p.GroupID, p.ArtifactID, p.Version, p.Packaging})
+	assert.Equal(t, &Parent{GroupID: "io.takari", ArtifactID: "takari", Version: "27"}, p.Parent)
+	assert.Equal(t, []Dependency{
+		{GroupID: "junit", ArtifactID: "junit", Version: "4.13.2"},
+		{GroupID: "org.slf4j", ArtifactID: "slf4j-api", Version: "2.0.9", Scope: "provided"},
+		{GroupID: "org.slf4j", ArtifactID: "slf4j-simple", Version: "2.0.9", Scope: "test"},
+	}, *p.Dependencies)
+	assert.Equal(t, []Extension{{GroupID: "io.takari.maven", ArtifactID: "takari-lifecycle-extension", Version: "2.1.1"}}, *p.Build.Extensions)
+	plugins := *p.Build.Plugins
+	assert.Equal(t, "maven-compiler-plugin", plugins[0].ArtifactID)
+	assert.Equal(t, "3.11.0", plugins[0].Version)
+	assert.Equal(t, "default-test", (*plugins[1].Executions)[0].ID)
+	assert.Equal(t, "central", (*p.Repositories)[0].ID)
+	assert.Equal(t, "release", (*p.Profiles)[0].ID)
+	assert.Equal(t, "release-tools", (*(*p.Profiles)[0].Dependencies)[0].ArtifactID)
+
+	p, err = ParsePolyglotYAML(strings.NewReader("id: com.example:web:war:1.0\n"))
+	if err != nil {
+		t.Fatalf("failed to parse: %v", err)
+	}
+	assert.Equal(t, []string{"com.example", "web", "1.0", "war"}, []string{p.GroupID, p.ArtifactID, p.Version, p.Packaging})
+
+	_, err = ParsePolyglotYAML(strings.NewReader("parent: com.example:parent:pom:1.0\n"))
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "line 1: invalid parent")
+	}
+}
+
+func TestMarshalPolyglotYAML(t *testing.T) {
+	p, err := Parse("testdata/query.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := p.MarshalPolyglotYAML()
+	if err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+	assert.NotContains(t, string(b), "xmlns")
+	assert.True(t, strings.HasPrefix(string(b), "modelVersion: 4.0.0\n"))
+
+	roundTrip, err := ParsePolyglotYAML(bytes.NewReader(b))
+	if err != nil {
+		t.Fatalf("failed to parse: %v", err)
+	}
+	again, err := roundTrip.MarshalPolyglotYAML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, string(b), string(again))
+}
+
+func TestPolyglotYAMLEnabled(t *testing.T) {
+	enabled, err := PolyglotYAMLEnabled("testdata/polyglot/app")
+	assert.NoError(t, err)
+	assert.True(t, enabled)
+
+	enabled, err = PolyglotYAMLEnabled(t.TempDir())
+	assert.NoError(t, err)
+	assert.False(t, enabled)
+
+	assert.Equal(t, filepath.Join("testdata", "polyglot", "app", "pom.yaml"), ProjectFile("testdata/polyglot/app"))
+	assert.Equal(t, filepath.Join("testdata", "polyglot", "lib", "pom.xml"), ProjectFile("testdata/polyglot/lib"))
+	assert.Equal(t, filepath.Join("testdata", "reactor", "pom.xml"), ProjectFile("testdata/reactor"))
+}
+
+func TestLoadReactorPolyglot(t *testing.T) {
+	r, err := LoadReactor("./testdata/polyglot")
+	if err != nil {
+		t.Fatalf("failed loading the reactor: %v", err)
+	}
+	assert.Equal(t, []string{"polyglot", "lib", "app"}, moduleNames(r))
+	assert.Equal(t, "pom.yaml", filepath.Base(r.Root.Path))
+	assert.Equal(t, r.Root, r.Module("com.example", "app").Parent)
+	assert.Equal(t, r.Root, r.Module("com.example", "lib").Parent)
+
+	eff, err := Effective(r.Module("com.example", "app").Project, EffectiveOptions{Dir: "testdata/polyglot/app"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "1.0.0", (*eff.Dependencies)[0].Version)
+	assert.Equal(t, "5.10.0", (*eff.Dependencies)[1].Version)
+}
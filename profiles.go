@@ -0,0 +1,88 @@
+package gopom
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ActiveProfiles returns the profiles of p that are active when building the
+// project located in dir. ids lists profiles that are explicitly requested,
+// as with `mvn -P`; an ID prefixed with "!" or "-" deactivates the profile
+// instead.
+//
+// Besides explicit requests, only file based activation is evaluated, as the
+// JDK, OS and property conditions depend on the build environment. Profiles
+// marked activeByDefault are active when no other profile of p is.
+func (p *Project) ActiveProfiles(dir string, ids ...string) []*Profile {
+	if p.Profiles == nil {
+		return nil
+	}
+	var active []*Profile
+	for _, i := range activeProfiles(len(*p.Profiles), func(i int) (string, *Activation) {
+		return (*p.Profiles)[i].ID, (*p.Profiles)[i].Activation
+	}, dir, ids) {
+		active = append(active, &(*p.Profiles)[i])
+	}
+	return active
+}
+
+// activeProfiles returns the indexes of the active profiles among n ones,
+// described by profile, following the rules of Project.ActiveProfiles.
+func activeProfiles(n int, profile func(i int) (string, *Activation), dir string, ids []string) []int {
+	requested := map[string]bool{}
+	for _, id := range ids {
+		switch {
+		case strings.HasPrefix(id, "!"), strings.HasPrefix(id, "-"):
+			requested[id[1:]] = false
+		default:
+			requested[id] = true
+		}
+	}
+
+	var active, byDefault []int
+	for i := 0; i < n; i++ {
+		id, activation := profile(i)
+		on, explicit := requested[id]
+		switch {
+		case explicit && !on:
+			continue
+		case on, activation.activeFor(dir):
+			active = append(active, i)
+		case activation != nil && activation.ActiveByDefault:
+			byDefault = append(byDefault, i)
+		}
+	}
+	if len(active) == 0 {
+		return byDefault
+	}
+	return active
+}
+
+// activeFor reports whether the activation triggers for a project in dir.
+// Only file conditions are evaluated, and an activation with any other
+// condition never triggers.
+func (a *Activation) activeFor(dir string) bool {
+	if a == nil || a.File == nil || a.JDK != "" || a.OS != nil || a.Property != nil {
+		return false
+	}
+	resolve := func(path string) string {
+		path = strings.ReplaceAll(path, "${basedir}", dir)
+		path = strings.ReplaceAll(path, "${project.basedir}", dir)
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		return path
+	}
+	exists := func(path string) bool {
+		_, err := os.Stat(resolve(path))
+		return err == nil
+	}
+	switch {
+	case a.File.Exists != "":
+		return exists(a.File.Exists)
+	case a.File.Missing != "":
+		return !exists(a.File.Missing)
+	}
+	return false
+}
@@ -0,0 +1,35 @@
+package gopom
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func profileIDs(profiles []*Profile) []string {
+	var ids []string
+	for _, p := range profiles {
+		ids = append(ids, p.ID)
+	}
+	return ids
+}
+
+func TestActiveProfiles(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "marker"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	p := &Project{Profiles: &[]Profile{
+		{ID: "default", Activation: &Activation{ActiveByDefault: true}},
+		{ID: "marker", Activation: &Activation{File: &ActivationFile{Exists: "${basedir}/marker"}}},
+		{ID: "missing", Activation: &Activation{File: &ActivationFile{Missing: "nope"}}},
+		{ID: "jdk", Activation: &Activation{JDK: "17", File: &ActivationFile{Missing: "nope"}}},
+		{ID: "manual"},
+	}}
+
+	assert.Equal(t, []string{"marker", "missing"}, profileIDs(p.ActiveProfiles(dir)))
+	assert.Equal(t, []string{"missing", "manual"}, profileIDs(p.ActiveProfiles(dir, "manual", "!marker")))
+	assert.Equal(t, []string{"default"}, profileIDs(p.ActiveProfiles(dir, "-marker", "-missing")))
+}
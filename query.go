@@ -0,0 +1,533 @@
+package gopom
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Query is a compiled path query over a Project.
+//
+// A query is a dot separated list of steps, each selecting child elements
+// by name, like "build.plugins.artifactId". Lists are transparent: a step
+// naming a list, like "dependencies", selects each of its items. "*"
+// selects all the children of an element, and names containing dots, like
+// property names, can be quoted, as in `properties."java.version"`; they can
+// also be left unquoted when there is no ambiguity.
+//
+// Each step can be followed by selectors in brackets, applied in turn to
+// the elements it selected for each parent:
+//
+//	dependencies[0]                  the first item, [-1] is the last one
+//	dependencies[scope=test]         items whose scope is "test"
+//	dependencies[scope!=test]        items whose scope is not "test"
+//	dependencies[exclusions]         items that have exclusions
+//	profiles[activation.jdk=17]      conditions can be paths too
+//
+// Values can be quoted, and must be when they contain "]". Plugin and
+// execution configurations are walked like the rest of the model.
+type Query struct {
+	query string
+	steps []step
+}
+
+type step struct {
+	// name is the element name, or "*".
+	name string
+	// quoted is set for names that were quoted, and must not be joined
+	// with the following steps.
+	quoted    bool
+	selectors []selector
+}
+
+type selector struct {
+	// index is used when path is nil.
+	index int
+	path  []step
+	// op is "=", "!=" or empty for an existence check.
+	op    string
+	value string
+}
+
+// Result is an element selected by a query.
+type Result struct {
+	// Path is the element path of the result, e.g.
+	// "project/dependencies[0]/dependency[1]/version[0]".
+	Path string
+	// Value is the selected value: a string for text elements, a bool for
+	// the few boolean ones, a pointer to the model struct for the others,
+	// like *Dependency or *Properties, and a *ConfigElement within
+	// configurations.
+	Value any
+	// Position is where the element starts in the source the project was
+	// parsed from. It is not valid when unknown, see Project.Position.
+	Position Position
+}
+
+// Text returns the text of the result, for text elements and configuration
+// elements, and an empty string otherwise.
+func (r Result) Text() string {
+	switch v := r.Value.(type) {
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case *ConfigElement:
+		return v.Text
+	}
+	return ""
+}
+
+// ConfigElement is an element of a plugin configuration.
+type ConfigElement struct {
+	Name     string
+	Attrs    []xml.Attr
+	Text     string
+	Children []*ConfigElement
+}
+
+// Elements parses the configuration into a tree of elements.
+func (c *Configuration) Elements() ([]*ConfigElement, error) {
+	root, err := c.root()
+	if err != nil {
+		return nil, err
+	}
+	return root.Children, nil
+}
+
+// root parses the configuration into an element without name, holding the
+// top-level text and elements.
+func (c *Configuration) root() (*ConfigElement, error) {
+	root := &ConfigElement{}
+	stack := []*ConfigElement{root}
+	d := xml.NewDecoder(strings.NewReader(c.RawConfiguration))
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			root.Text = strings.TrimSpace(root.Text)
+			return root, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid configuration: %w", err)
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			e := &ConfigElement{Name: t.Name.Local, Attrs: t.Attr}
+			parent := stack[len(stack)-1]
+			parent.Children = append(parent.Children, e)
+			stack = append(stack, e)
+		case xml.EndElement:
+			e := stack[len(stack)-1]
+			e.Text = strings.TrimSpace(e.Text)
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			stack[len(stack)-1].Text += string(t)
+		}
+	}
+}
+
+// ParseQuery compiles a query.
+func ParseQuery(query string) (*Query, error) {
+	p := &queryParser{s: query}
+	steps, err := p.path()
+	if err == nil && p.i < len(p.s) {
+		err = p.errorf("unexpected %q", p.s[p.i])
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid query %q: %w", query, err)
+	}
+	return &Query{query: query, steps: steps}, nil
+}
+
+func (q *Query) String() string {
+	return q.query
+}
+
+// Query evaluates query over p, see ParseQuery and Query.Eval.
+func (p *Project) Query(query string) ([]Result, error) {
+	q, err := ParseQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	return q.Eval(p)
+}
+
+// Eval returns the elements of p selected by the query, in document order.
+// It only fails on configurations that are not valid XML.
+func (q *Query) Eval(p *Project) ([]Result, error) {
+	nodes, err := evalSteps([]node{{path: "project", v: reflect.ValueOf(p)}}, q.steps)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]Result, 0, len(nodes))
+	for _, n := range nodes {
+		pos, _ := p.Position(n.path)
+		results = append(results, Result{Path: n.path, Value: n.value(), Position: pos})
+	}
+	return results, nil
+}
+
+// node is an element of a project, as seen by queries.
+type node struct {
+	path string
+	v    reflect.Value
+}
+
+var configElementType = reflect.TypeOf(&ConfigElement{})
+
+func (n node) value() any {
+	if n.v.Kind() == reflect.Struct && n.v.CanAddr() {
+		return n.v.Addr().Interface()
+	}
+	return n.v.Interface()
+}
+
+// properties returns the properties n holds, if any.
+func (n node) properties() *Properties {
+	if n.v.Kind() != reflect.Struct || !n.v.CanAddr() {
+		return nil
+	}
+	props, _ := n.v.Addr().Interface().(*Properties)
+	return props
+}
+
+func (n node) text() string {
+	switch n.v.Kind() {
+	case reflect.String:
+		return n.v.String()
+	case reflect.Bool:
+		return strconv.FormatBool(n.v.Bool())
+	case reflect.Pointer:
+		if n.v.Type() == configElementType {
+			return n.v.Interface().(*ConfigElement).Text
+		}
+	}
+	return ""
+}
+
+// children returns the children of n named name, or all of them for "*".
+func (n node) children(name string) ([]node, error) {
+	v := n.v
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil, nil
+		}
+		if v.Type() == configElementType {
+			var children []node
+			counts := map[string]int{}
+			for _, c := range v.Interface().(*ConfigElement).Children {
+				path := elementPath(n.path, c.Name, counts[c.Name])
+				counts[c.Name]++
+				if name == "*" || name == c.Name {
+					children = append(children, node{path: path, v: reflect.ValueOf(c)})
+				}
+			}
+			return children, nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil, nil
+	}
+
+	switch m := v.Addr().Interface().(type) {
+	case *Properties:
+		var children []node
+		for _, key := range m.Order {
+			if name == "*" || name == key {
+				children = append(children, node{path: elementPath(n.path, key, 0), v: reflect.ValueOf(m.Entries[key])})
+			}
+		}
+		return children, nil
+	case *Configuration:
+		elements, err := m.Elements()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", n.path, err)
+		}
+		root := &ConfigElement{Children: elements}
+		return node{path: n.path, v: reflect.ValueOf(root)}.children(name)
+	}
+
+	var children []node
+	for _, f := range xmlFields(v) {
+		if name != "*" && name != f.name {
+			continue
+		}
+		fv := f.v
+		if fv.Kind() == reflect.Pointer {
+			if fv.IsNil() {
+				continue
+			}
+			fv = fv.Elem()
+		}
+		if f.item == "" {
+			if (fv.Kind() == reflect.String || fv.Kind() == reflect.Bool) && fv.IsZero() {
+				continue
+			}
+			children = append(children, node{path: elementPath(n.path, f.name, 0), v: fv})
+			continue
+		}
+		wrapper := elementPath(n.path, f.name, 0)
+		for i := 0; i < fv.Len(); i++ {
+			children = append(children, node{path: elementPath(wrapper, f.item, i), v: fv.Index(i)})
+		}
+	}
+	return children, nil
+}
+
+type xmlField struct {
+	// name is the element name, and item the name of the items of lists
+	// wrapped in it.
+	name, item string
+	v          reflect.Value
+}
+
+// xmlFields returns the fields of the struct v that are XML elements, in
+// order, including those of embedded structs.
+func xmlFields(v reflect.Value) []xmlField {
+	var fields []xmlField
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.Anonymous {
+			fields = append(fields, xmlFields(v.Field(i))...)
+			continue
+		}
+		tag, opts, _ := strings.Cut(sf.Tag.Get("xml"), ",")
+		if !sf.IsExported() || tag == "" || tag == "-" || strings.Contains(opts, "attr") || strings.Contains(opts, "innerxml") || sf.Name == "XMLName" {
+			continue
+		}
+		name, item, _ := strings.Cut(tag, ">")
+		fields = append(fields, xmlField{name: name, item: item, v: v.Field(i)})
+	}
+	return fields
+}
+
+func evalSteps(nodes []node, steps []step) ([]node, error) {
+	var results []node
+	for _, n := range nodes {
+		r, err := evalNode(n, steps)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, r...)
+	}
+	return results, nil
+}
+
+func evalNode(n node, steps []step) ([]node, error) {
+	if len(steps) == 0 {
+		return []node{n}, nil
+	}
+	s, rest := steps[0], steps[1:]
+	// Property names can contain dots: join the following steps to the
+	// name when that matches a property.
+	if props := n.properties(); props != nil && !s.quoted && s.name != "*" {
+		if key, skip := propertyKey(props, steps); skip > 0 {
+			s = step{name: key, quoted: true, selectors: steps[skip-1].selectors}
+			rest = steps[skip:]
+		}
+	}
+	children, err := n.children(s.name)
+	if err != nil {
+		return nil, err
+	}
+	if children, err = s.filter(children); err != nil {
+		return nil, err
+	}
+	return evalSteps(children, rest)
+}
+
+// propertyKey returns the longest property of props named by joining the
+// names of the first steps, and how many steps it took. Only the last of
+// them may have selectors.
+func propertyKey(props *Properties, steps []step) (string, int) {
+	n := 0
+	for n < len(steps) && !steps[n].quoted && steps[n].name != "*" {
+		n++
+		if len(steps[n-1].selectors) > 0 {
+			break
+		}
+	}
+	for ; n > 1; n-- {
+		names := make([]string, n)
+		for i := range names {
+			names[i] = steps[i].name
+		}
+		key := strings.Join(names, ".")
+		if _, ok := props.Entries[key]; ok {
+			return key, n
+		}
+	}
+	return "", 0
+}
+
+func (s step) filter(nodes []node) ([]node, error) {
+	for _, sel := range s.selectors {
+		if sel.path == nil {
+			i := sel.index
+			if i < 0 {
+				i += len(nodes)
+			}
+			if i < 0 || i >= len(nodes) {
+				nodes = nil
+			} else {
+				nodes = nodes[i : i+1]
+			}
+			continue
+		}
+		var kept []node
+		for _, n := range nodes {
+			ok, err := sel.matches(n)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				kept = append(kept, n)
+			}
+		}
+		nodes = kept
+	}
+	return nodes, nil
+}
+
+func (sel selector) matches(n node) (bool, error) {
+	values, err := evalSteps([]node{n}, sel.path)
+	if err != nil {
+		return false, err
+	}
+	switch sel.op {
+	case "":
+		return len(values) > 0, nil
+	case "=":
+		for _, v := range values {
+			if v.text() == sel.value {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+	for _, v := range values {
+		if v.text() == sel.value {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+type queryParser struct {
+	s string
+	i int
+}
+
+func (p *queryParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("at offset %d: %s", p.i, fmt.Sprintf(format, args...))
+}
+
+// path parses dot separated steps.
+func (p *queryParser) path() ([]step, error) {
+	var steps []step
+	for {
+		s, err := p.step()
+		if err != nil {
+			return nil, err
+		}
+		steps = append(steps, s)
+		if p.i >= len(p.s) || p.s[p.i] != '.' {
+			return steps, nil
+		}
+		p.i++
+	}
+}
+
+func (p *queryParser) step() (step, error) {
+	var s step
+	var err error
+	if p.i < len(p.s) && p.s[p.i] == '"' {
+		s.quoted = true
+		if s.name, err = p.quoted(); err != nil {
+			return s, err
+		}
+	} else {
+		s.name = p.token(".[]=!")
+	}
+	if s.name == "" {
+		return s, p.errorf("missing element name")
+	}
+	for p.i < len(p.s) && p.s[p.i] == '[' {
+		p.i++
+		sel, err := p.selector()
+		if err != nil {
+			return s, err
+		}
+		if p.i >= len(p.s) || p.s[p.i] != ']' {
+			return s, p.errorf("missing ]")
+		}
+		p.i++
+		s.selectors = append(s.selectors, sel)
+	}
+	return s, nil
+}
+
+func (p *queryParser) selector() (selector, error) {
+	start := p.i
+	if index, err := strconv.Atoi(p.token("]")); err == nil {
+		return selector{index: index}, nil
+	}
+	p.i = start
+
+	path, err := p.path()
+	if err != nil {
+		return selector{}, err
+	}
+	sel := selector{path: path}
+	switch {
+	case strings.HasPrefix(p.s[p.i:], "!="):
+		sel.op = "!="
+	case strings.HasPrefix(p.s[p.i:], "="):
+		sel.op = "="
+	default:
+		return sel, nil
+	}
+	p.i += len(sel.op)
+	if p.i < len(p.s) && p.s[p.i] == '"' {
+		sel.value, err = p.quoted()
+	} else {
+		sel.value = p.token("]")
+	}
+	return sel, err
+}
+
+// token consumes and returns the text up to one of the stop characters.
+func (p *queryParser) token(stop string) string {
+	start := p.i
+	for p.i < len(p.s) && !strings.ContainsRune(stop, rune(p.s[p.i])) {
+		p.i++
+	}
+	return strings.TrimSpace(p.s[start:p.i])
+}
+
+// quoted consumes a double quoted string, where quotes and backslashes are
+// escaped with backslashes.
+func (p *queryParser) quoted() (string, error) {
+	var b bytes.Buffer
+	for p.i++; p.i < len(p.s); p.i++ {
+		switch c := p.s[p.i]; c {
+		case '\\':
+			p.i++
+			if p.i < len(p.s) {
+				b.WriteByte(p.s[p.i])
+			}
+		case '"':
+			p.i++
+			return b.String(), nil
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", p.errorf("unterminated string")
+}
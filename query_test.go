@@ -0,0 +1,110 @@
+package gopom
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const queryFilename = "./testdata/query.xml"
+
+func queryTexts(t *testing.T, p *Project, query string) []string {
+	t.Helper()
+	results, err := p.Query(query)
+	if err != nil {
+		t.Fatalf("query %q failed: %v", query, err)
+	}
+	texts := []string{}
+	for _, r := range results {
+		texts = append(texts, r.Text())
+	}
+	return texts
+}
+
+func TestQuery(t *testing.T) {
+	p, err := Parse(queryFilename)
+	if err != nil {
+		t.Fatalf("failed parsing the file: %v", err)
+	}
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"artifactId", []string{"query"}},
+		{"dependencies.artifactId", []string{"guava", "junit-jupiter", "mockito-core"}},
+		{"dependencies[scope=test].artifactId", []string{"junit-jupiter", "mockito-core"}},
+		{"dependencies[scope!=test].artifactId", []string{"guava"}},
+		{"dependencies[exclusions].artifactId", []string{"junit-jupiter"}},
+		{"dependencies[exclusions.groupId=org.hamcrest].version", []string{"5.10.0"}},
+		{"dependencies[1].artifactId", []string{"junit-jupiter"}},
+		{"dependencies[-1].artifactId", []string{"mockito-core"}},
+		{"dependencies[scope=test][1].artifactId", []string{"mockito-core"}},
+		{"dependencies[7].artifactId", []string{}},
+		{`dependencies[artifactId="guava"].version`, []string{"33.0.0-jre"}},
+		{"build.plugins[artifactId=maven-compiler-plugin].configuration.release", []string{"${java.version}"}},
+		{"build.plugins.configuration.compilerArgs.arg", []string{"-Xlint:all", "-Werror"}},
+		{"build.plugins.configuration.compilerArgs.arg[1]", []string{"-Werror"}},
+		{"build.plugins.configuration.*", []string{"${java.version}", ""}},
+		{"properties.java.version", []string{"17"}},
+		{`properties."java.version"`, []string{"17"}},
+		{"properties.java", []string{"ambiguous"}},
+		{"profiles[id=jdk21].properties.java.version", []string{"21"}},
+		{"profiles[activation.jdk=21].id", []string{"jdk21"}},
+		{"profiles.dependencies.artifactId", []string{"mockito-junit-jupiter"}},
+		{"missing.element", []string{}},
+	}
+	for _, test := range tests {
+		t.Run(test.query, func(t *testing.T) {
+			assert.Equal(t, test.want, queryTexts(t, p, test.query))
+		})
+	}
+}
+
+func TestQueryResults(t *testing.T) {
+	p, err := Parse(queryFilename)
+	if err != nil {
+		t.Fatalf("failed parsing the file: %v", err)
+	}
+
+	results, err := p.Query("dependencies[artifactId=junit-jupiter]")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if assert.Len(t, results, 1) {
+		assert.Equal(t, "project/dependencies[0]/dependency[1]", results[0].Path)
+		assert.Same(t, &(*p.Dependencies)[1], results[0].Value)
+		assert.Equal(t, Position{Offset: 486, Line: 17, Column: 5}, results[0].Position)
+	}
+
+	results, err = p.Query("build.plugins.configuration.compilerArgs.arg[1]")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if assert.Len(t, results, 1) {
+		assert.Equal(t, "project/build[0]/plugins[0]/plugin[0]/configuration[0]/compilerArgs[0]/arg[1]", results[0].Path)
+		assert.Equal(t, "45:13", results[0].Position.String())
+	}
+
+	// Projects that were not parsed have no positions.
+	results, err = (&Project{ArtifactID: "built"}).Query("artifactId")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []Result{{Path: "project/artifactId[0]", Value: "built"}}, results)
+	assert.False(t, results[0].Position.IsValid())
+}
+
+func TestParseQueryErrors(t *testing.T) {
+	for _, query := range []string{
+		"",
+		"dependencies.",
+		"dependencies[scope=test",
+		"dependencies[]",
+		`properties."java.version`,
+		"dependencies]",
+	} {
+		_, err := ParseQuery(query)
+		assert.Error(t, err, query)
+	}
+}
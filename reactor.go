@@ -0,0 +1,392 @@
+package gopom
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	// ErrModuleNotFound is returned when a module listed in a POM has no
+	// POM.
+	ErrModuleNotFound = errors.New("module not found")
+	// ErrReactorCycle is returned when modules reference each other in a
+	// cycle, either through their module lists or their dependencies.
+	ErrReactorCycle = errors.New("cycle in the reactor")
+)
+
+// Module is a project loaded as part of a reactor.
+type Module struct {
+	// Path is the path to the module's POM file.
+	Path    string
+	Project *Project
+	// Parent is the module the project's Parent resolves to. It is nil when
+	// the project has no parent or its parent is not part of the reactor.
+	Parent *Module
+	// Profiles are the profiles of Project that were active when loading.
+	Profiles []*Profile
+}
+
+// Dir returns the directory containing the module's POM file.
+func (m *Module) Dir() string {
+	return filepath.Dir(m.Path)
+}
+
+// GroupID returns the module's groupId, inherited from the parent if the
+// project does not declare one.
+func (m *Module) GroupID() string {
+	if m.Project.GroupID == "" && m.Project.Parent != nil {
+		return m.Project.Parent.GroupID
+	}
+	return m.Project.GroupID
+}
+
+// Version returns the module's version, inherited from the parent if the
+// project does not declare one.
+func (m *Module) Version() string {
+	if m.Project.Version == "" && m.Project.Parent != nil {
+		return m.Project.Parent.Version
+	}
+	return m.Project.Version
+}
+
+func (m *Module) String() string {
+	return m.GroupID() + ":" + m.Project.ArtifactID
+}
+
+// Reactor is a multi-module project.
+type Reactor struct {
+	// Root is the module LoadReactor was called on.
+	Root *Module
+	// Modules holds every module of the reactor, including Root, in the
+	// order Maven would build them.
+	Modules []*Module
+}
+
+// Module returns the module with the given groupId and artifactId, or nil if
+// it is not part of the reactor.
+func (r *Reactor) Module(groupID, artifactID string) *Module {
+	for _, m := range r.Modules {
+		if m.GroupID() == groupID && m.Project.ArtifactID == artifactID {
+			return m
+		}
+	}
+	return nil
+}
+
+// LoadReactor loads the project in rootDir and, recursively, all of its
+// modules, including the ones added by active profiles (see
+// Project.ActiveProfiles for how profiles is interpreted).
+//
+// Modules are returned in reactor build order: a module comes after its
+// parent and after the modules it references as a dependency, plugin, plugin
+// dependency or build extension, whose coordinates can use expressions like
+// ${project.groupId}. Modules that do not depend on each other
+// keep the order they are declared in. POMs are parsed through a cache
+// shared by the package, see Cache.
+func LoadReactor(rootDir string, profiles ...string) (*Reactor, error) {
+	return loadReactor(rootDir, profiles, defaultCache.Parse)
+}
+
+func loadReactor(rootDir string, profiles []string, parse func(path string) (*Project, error)) (*Reactor, error) {
+	l := reactorLoader{
+		parse:    parse,
+		profiles: profiles,
+		byPath:   map[string]*Module{},
+		loading:  map[string]bool{},
+	}
+	path, err := filepath.Abs(ProjectFile(rootDir))
+	if err != nil {
+		return nil, err
+	}
+	p, err := parse(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %s: %w", path, err)
+	}
+	root, err := l.load(path, p)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range l.modules {
+		m.Parent = l.resolveParent(m)
+	}
+	ordered, err := buildOrder(l.modules)
+	if err != nil {
+		return nil, err
+	}
+	return &Reactor{Root: root, Modules: ordered}, nil
+}
+
+type reactorLoader struct {
+	parse    func(path string) (*Project, error)
+	profiles []string
+	// modules are in the order they were loaded.
+	modules []*Module
+	byPath  map[string]*Module
+	// loading holds the modules whose module lists are being walked.
+	loading map[string]bool
+}
+
+// load registers the project p, parsed from path, and loads its modules.
+// The modules of a project are parsed concurrently, but registered in the
+// order they are declared in.
+func (l *reactorLoader) load(path string, p *Project) (*Module, error) {
+	m := &Module{Path: path, Project: p}
+	m.Profiles = p.ActiveProfiles(m.Dir(), l.profiles...)
+	l.modules = append(l.modules, m)
+	l.byPath[path] = m
+
+	l.loading[path] = true
+	defer delete(l.loading, path)
+
+	var children []string
+	for _, name := range m.moduleNames() {
+		child, err := modulePath(m.Dir(), name)
+		if err != nil {
+			return nil, fmt.Errorf("module %q of %s: %w", name, path, err)
+		}
+		if child, err = filepath.Abs(child); err != nil {
+			return nil, err
+		}
+		if l.loading[child] {
+			return nil, fmt.Errorf("%w: %s lists itself as a module", ErrReactorCycle, child)
+		}
+		if _, ok := l.byPath[child]; ok {
+			return nil, fmt.Errorf("%s is listed more than once in the reactor", child)
+		}
+		children = append(children, child)
+	}
+
+	projects, err := parseAll(context.Background(), children, 0, l.parse)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the modules of %s: %w", path, err)
+	}
+	for i, child := range children {
+		if _, ok := l.byPath[child]; ok {
+			return nil, fmt.Errorf("%s is listed more than once in the reactor", child)
+		}
+		if _, err := l.load(child, projects[i]); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// moduleNames returns the modules declared by the project and its active
+// profiles.
+func (m *Module) moduleNames() []string {
+	var names []string
+	if m.Project.Modules != nil {
+		names = append(names, *m.Project.Modules...)
+	}
+	for _, profile := range m.Profiles {
+		if profile.Modules != nil {
+			names = append(names, *profile.Modules...)
+		}
+	}
+	return names
+}
+
+// modulePath returns the POM file of the module called name, relative to
+// dir. A module can either be a directory containing a POM, see
+// ProjectFile, or point to a POM file directly.
+func modulePath(dir, name string) (string, error) {
+	path := filepath.Join(dir, filepath.FromSlash(name))
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", ErrModuleNotFound, path)
+	}
+	if !info.IsDir() {
+		return path, nil
+	}
+	path = ProjectFile(path)
+	if _, err := os.Stat(path); err != nil {
+		return "", fmt.Errorf("%w: %s", ErrModuleNotFound, path)
+	}
+	return path, nil
+}
+
+// resolveParent returns the module m's parent points to through its
+// relativePath, as long as its coordinates match.
+func (l *reactorLoader) resolveParent(m *Module) *Module {
+	parent := m.Project.Parent
+	if parent == nil {
+		return nil
+	}
+	path := parentPOMPath(m.Dir(), parent.RelativePath)
+	candidate, ok := l.byPath[path]
+	if !ok || candidate == m {
+		return nil
+	}
+	if candidate.GroupID() != parent.GroupID || candidate.Project.ArtifactID != parent.ArtifactID {
+		return nil
+	}
+	return candidate
+}
+
+// references returns the groupId:artifactId keys of the projects m needs to
+// be built before it, with their expressions interpolated, see expand.
+func (m *Module) references() []string {
+	var refs []string
+	add := func(groupID, artifactID string) {
+		refs = append(refs, m.expand(groupID)+":"+m.expand(artifactID))
+	}
+	if m.Project.Parent != nil {
+		add(m.Project.Parent.GroupID, m.Project.Parent.ArtifactID)
+	}
+	addDependencies := func(deps *[]Dependency) {
+		if deps == nil {
+			return
+		}
+		for _, d := range *deps {
+			add(d.GroupID, d.ArtifactID)
+		}
+	}
+	addPlugins := func(b *BuildBase) {
+		if b == nil || b.Plugins == nil {
+			return
+		}
+		for _, p := range *b.Plugins {
+			groupID := p.GroupID
+			if groupID == "" {
+				groupID = "org.apache.maven.plugins"
+			}
+			add(groupID, p.ArtifactID)
+			addDependencies(p.Dependencies)
+		}
+	}
+
+	addDependencies(m.Project.Dependencies)
+	if b := m.Project.Build; b != nil {
+		addPlugins(&b.BuildBase)
+		if b.Extensions != nil {
+			for _, e := range *b.Extensions {
+				add(e.GroupID, e.ArtifactID)
+			}
+		}
+	}
+	for _, profile := range m.Profiles {
+		addDependencies(profile.Dependencies)
+		addPlugins(profile.Build)
+	}
+	return refs
+}
+
+// expand replaces the ${...} expressions in s with the coordinates of the
+// module, like ${project.groupId}, and the properties of its active
+// profiles, of its project and of its parents in the reactor.
+func (m *Module) expand(s string) string {
+	return expand(s, func(name string) (string, bool) {
+		if v, ok := m.property(name); ok {
+			return v, true
+		}
+		if strings.HasPrefix(name, "pom.") {
+			name = "project." + strings.TrimPrefix(name, "pom.")
+		}
+		var v string
+		switch name {
+		case "project.groupId":
+			v = m.GroupID()
+		case "project.artifactId":
+			v = m.Project.ArtifactID
+		case "project.version":
+			v = m.Version()
+		}
+		if parent := m.Project.Parent; parent != nil {
+			switch name {
+			case "project.parent.groupId":
+				v = parent.GroupID
+			case "project.parent.artifactId":
+				v = parent.ArtifactID
+			case "project.parent.version":
+				v = parent.Version
+			}
+		}
+		return v, v != ""
+	}, 0)
+}
+
+// property returns the value of the property called name for the module:
+// the one of its active profiles, of its project, or else of its closest
+// parent defining it.
+func (m *Module) property(name string) (string, bool) {
+	seen := map[*Module]bool{}
+	for ; m != nil && !seen[m]; m = m.Parent {
+		seen[m] = true
+		for i := len(m.Profiles) - 1; i >= 0; i-- {
+			if props := m.Profiles[i].Properties; props != nil {
+				if v, ok := props.Entries[name]; ok {
+					return v, true
+				}
+			}
+		}
+		if props := m.Project.Properties; props != nil {
+			if v, ok := props.Entries[name]; ok {
+				return v, true
+			}
+		}
+	}
+	return "", false
+}
+
+// buildOrder sorts modules topologically on their references. It visits the
+// modules depth first in declaration order, the same way Maven's project
+// sorter does, so independent modules keep their relative order.
+func buildOrder(modules []*Module) ([]*Module, error) {
+	byKey := map[string]*Module{}
+	for _, m := range modules {
+		byKey[m.String()] = m
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := map[*Module]int{}
+	var (
+		ordered []*Module
+		stack   []*Module
+		visit   func(m *Module) error
+	)
+	visit = func(m *Module) error {
+		switch state[m] {
+		case visited:
+			return nil
+		case visiting:
+			var cycle []string
+			for i := len(stack) - 1; i >= 0; i-- {
+				cycle = append([]string{stack[i].String()}, cycle...)
+				if stack[i] == m {
+					break
+				}
+			}
+			cycle = append(cycle, m.String())
+			return fmt.Errorf("%w: %s", ErrReactorCycle, strings.Join(cycle, " -> "))
+		}
+		state[m] = visiting
+		stack = append(stack, m)
+		for _, ref := range m.references() {
+			dep, ok := byKey[ref]
+			if !ok || dep == m {
+				continue
+			}
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[m] = visited
+		ordered = append(ordered, m)
+		return nil
+	}
+	for _, m := range modules {
+		if err := visit(m); err != nil {
+			return nil, err
+		}
+	}
+	return ordered, nil
+}
@@ -0,0 +1,71 @@
+package gopom
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func moduleNames(r *Reactor) []string {
+	var names []string
+	for _, m := range r.Modules {
+		names = append(names, m.Project.ArtifactID)
+	}
+	return names
+}
+
+func TestLoadReactor(t *testing.T) {
+	r, err := LoadReactor("./testdata/reactor")
+	if err != nil {
+		t.Fatalf("failed loading the reactor: %v", err)
+	}
+	assert.Equal(t, []string{"root", "api", "core", "tools", "app"}, moduleNames(r))
+	assert.Equal(t, "root", r.Root.Project.ArtifactID)
+
+	app := r.Module("com.example", "app")
+	if app == nil {
+		t.Fatal("module com.example:app not found")
+	}
+	assert.Equal(t, r.Root, app.Parent)
+	assert.Equal(t, "1.0.0", app.Version())
+	assert.Nil(t, r.Root.Parent)
+	assert.Equal(t, "extra", r.Root.Profiles[0].ID)
+}
+
+func TestLoadReactorProfiles(t *testing.T) {
+	r, err := LoadReactor("./testdata/reactor", "it")
+	if err != nil {
+		t.Fatalf("failed loading the reactor: %v", err)
+	}
+	assert.Equal(t, []string{"root", "api", "core", "app", "it"}, moduleNames(r))
+
+	r, err = LoadReactor("./testdata/reactor", "!extra")
+	if err != nil {
+		t.Fatalf("failed loading the reactor: %v", err)
+	}
+	assert.Equal(t, []string{"root", "api", "core", "app"}, moduleNames(r))
+}
+
+func TestLoadReactorCycle(t *testing.T) {
+	_, err := LoadReactor("./testdata/reactor-cycle")
+	if !errors.Is(err, ErrReactorCycle) {
+		t.Fatalf("expected a reactor cycle, got: %v", err)
+	}
+	assert.Contains(t, err.Error(), "com.example:a -> com.example:b -> com.example:a")
+}
+
+func TestLoadReactorMissingModule(t *testing.T) {
+	_, err := LoadReactor("./testdata/reactor-missing")
+	if !errors.Is(err, ErrModuleNotFound) {
+		t.Fatalf("expected a missing module, got: %v", err)
+	}
+}
+
+func TestLoadReactorInterpolated(t *testing.T) {
+	r, err := LoadReactor("./testdata/reactor-interpolated")
+	if err != nil {
+		t.Fatalf("failed loading the reactor: %v", err)
+	}
+	assert.Equal(t, []string{"root", "util", "lib", "app"}, moduleNames(r))
+}
@@ -0,0 +1,180 @@
+package gopom
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ResolveOptions configures how Resolve builds the dependency graph.
+type ResolveOptions struct {
+	// Repository is the local Maven repository the POMs of the
+	// dependencies are read from. It defaults to ~/.m2/repository.
+	Repository string
+	// Parse is used to load the POMs. It defaults to the Parse method of a
+	// cache shared by the package, and can be set to the one of another
+	// Cache.
+	Parse func(path string) (*Project, error)
+}
+
+// Resolve returns the dependency graph of p, which should be an effective
+// model, see Effective, by reading the POMs of the dependencies from a
+// local repository. It follows the rules of Maven:
+//
+//   - the nearest declaration of a dependency wins, and the first one
+//     among declarations at the same depth,
+//   - the scope of transitive dependencies derives from the scope of the
+//     dependency bringing them, and test and provided dependencies are not
+//     transitive,
+//   - optional and excluded transitive dependencies are left out,
+//   - the dependency management of p applies to transitive dependencies.
+//
+// The scope of every node is set to its resolved scope. Dependencies whose
+// POM is not in the repository, like system dependencies or the ones with a
+// version range, have neither licenses nor dependencies of their own.
+func Resolve(p *Project, opts ResolveOptions) ([]*DependencyNode, error) {
+	if opts.Repository == "" {
+		repository, err := LocalRepository()
+		if err != nil {
+			return nil, err
+		}
+		opts.Repository = repository
+	}
+	if opts.Parse == nil {
+		opts.Parse = defaultCache.Parse
+	}
+	if p.Dependencies == nil {
+		return nil, nil
+	}
+
+	managed := map[string]Dependency{}
+	if p.DependencyManagement != nil && p.DependencyManagement.Dependencies != nil {
+		for _, d := range *p.DependencyManagement.Dependencies {
+			managed[dependencyKey(d)] = d
+		}
+	}
+
+	type pending struct {
+		node       *DependencyNode
+		exclusions []Exclusion
+	}
+	var graph []*DependencyNode
+	var queue []pending
+	resolved := map[string]bool{}
+	for _, d := range *p.Dependencies {
+		key := dependencyKey(d)
+		if resolved[key] {
+			continue
+		}
+		resolved[key] = true
+		if d.Scope == "" {
+			d.Scope = "compile"
+		}
+		n := &DependencyNode{Dependency: d}
+		graph = append(graph, n)
+		queue = append(queue, pending{node: n, exclusions: derefExclusions(d.Exclusions)})
+	}
+
+	for len(queue) > 0 {
+		next := queue[0]
+		queue = queue[1:]
+		parent := next.node.Dependency
+		if parent.Scope == "system" || parent.Version == "" || strings.ContainsAny(parent.Version, "[(") {
+			continue
+		}
+		path := repositoryPOMPath(opts.Repository, parent.Coordinate())
+		pom, err := opts.Parse(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve %s: %w", parent.Coordinate(), err)
+		}
+		eff, err := Effective(pom, EffectiveOptions{Dir: filepath.Dir(path), Parse: opts.Parse, Repository: opts.Repository})
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve %s: %w", parent.Coordinate(), err)
+		}
+		if eff.Licenses != nil {
+			next.node.Licenses = *eff.Licenses
+		}
+		if eff.Dependencies == nil {
+			continue
+		}
+
+		for _, d := range *eff.Dependencies {
+			scope := transitiveScope(parent.Scope, d.Scope)
+			if scope == "" || strings.TrimSpace(d.Optional) == "true" || excluded(next.exclusions, d) {
+				continue
+			}
+			key := dependencyKey(d)
+			if resolved[key] {
+				continue
+			}
+			resolved[key] = true
+			if m, ok := managed[key]; ok && m.Version != "" {
+				d.Version = m.Version
+			}
+			d.Scope = scope
+			n := &DependencyNode{Dependency: d}
+			next.node.Dependencies = append(next.node.Dependencies, n)
+			exclusions := append(append([]Exclusion{}, next.exclusions...), derefExclusions(d.Exclusions)...)
+			queue = append(queue, pending{node: n, exclusions: exclusions})
+		}
+	}
+	return graph, nil
+}
+
+// LocalRepository returns the default location of the local Maven
+// repository, ~/.m2/repository.
+func LocalRepository() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate the local repository: %w", err)
+	}
+	return filepath.Join(home, ".m2", "repository"), nil
+}
+
+// transitiveScope returns the scope of a dependency with the given scope
+// brought by a dependency in parent scope, or an empty string if it is not
+// brought at all.
+func transitiveScope(parent, scope string) string {
+	if scope == "" {
+		scope = "compile"
+	}
+	if scope != "compile" && scope != "runtime" {
+		return ""
+	}
+	switch parent {
+	case "compile":
+		return scope
+	case "runtime", "provided", "test":
+		return parent
+	}
+	return ""
+}
+
+// excluded tells whether d matches one of the exclusions, which may use *
+// as groupId or artifactId.
+func excluded(exclusions []Exclusion, d Dependency) bool {
+	for _, e := range exclusions {
+		if (e.GroupID == "*" || e.GroupID == d.GroupID) && (e.ArtifactID == "*" || e.ArtifactID == d.ArtifactID) {
+			return true
+		}
+	}
+	return false
+}
+
+func derefExclusions(exclusions *[]Exclusion) []Exclusion {
+	if exclusions == nil {
+		return nil
+	}
+	return *exclusions
+}
+
+// repositoryPOMPath returns the path of the POM of c in a local repository.
+func repositoryPOMPath(repository string, c Coordinate) string {
+	return filepath.Join(repository, filepath.FromSlash(strings.ReplaceAll(c.GroupID, ".", "/")), c.ArtifactID, c.Version, c.ArtifactID+"-"+c.Version+".pom")
+}
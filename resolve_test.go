@@ -0,0 +1,59 @@
+package gopom
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testRepository = "testdata/repository"
+
+// dependencyTree formats the graph as a list of indented coordinates with
+// their scope.
+func dependencyTree(nodes []*DependencyNode, indent string) []string {
+	var tree []string
+	for _, n := range nodes {
+		tree = append(tree, indent+n.Dependency.Coordinate().String()+" "+n.Dependency.Scope)
+		tree = append(tree, dependencyTree(n.Dependencies, indent+"  ")...)
+	}
+	return tree
+}
+
+func TestResolve(t *testing.T) {
+	p, err := Parse("testdata/policy/pom.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	graph, err := Resolve(p, ResolveOptions{Repository: testRepository})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []string{
+		"com.example:core:1.0 compile",
+		"  com.example:gpl:2.0 compile",
+		"  com.example:util:1.1 runtime",
+		"  com.example:mystery:1.0 compile",
+		"com.example:testkit:1.0 test",
+		"  com.example:fixtures:1.0 test",
+	}, dependencyTree(graph, ""))
+
+	// The license of core is inherited from its parent in the repository.
+	assert.Equal(t, []License{{Name: "MIT License"}}, graph[0].Licenses)
+	assert.Nil(t, graph[1].Dependencies[0].Licenses)
+}
+
+func TestTransitiveScope(t *testing.T) {
+	for _, test := range []struct {
+		parent, scope, want string
+	}{
+		{"compile", "", "compile"},
+		{"compile", "runtime", "runtime"},
+		{"runtime", "compile", "runtime"},
+		{"provided", "compile", "provided"},
+		{"test", "runtime", "test"},
+		{"compile", "test", ""},
+		{"compile", "provided", ""},
+	} {
+		assert.Equal(t, test.want, transitiveScope(test.parent, test.scope), test)
+	}
+}
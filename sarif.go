@@ -0,0 +1,179 @@
+package gopom
+
+import (
+	"encoding/json"
+	"net/url"
+	"path/filepath"
+	"strings"
+)
+
+const sarifSchema = "https://json.schemastore.org/sarif-2.1.0.json"
+
+// ReportFile is a POM and the problems found in it, see Validate and Lint.
+type ReportFile struct {
+	// Path is the path of the POM. Relative paths are written as relative
+	// URIs, absolute ones as file URIs.
+	Path string
+	// Project is used to locate the problems in the POM, when it was
+	// parsed. It can be nil.
+	Project  *Project
+	Problems []Problem
+}
+
+// sarifLog is a SARIF 2.1.0 log, with the fields gopom writes.
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string             `json:"id"`
+	ShortDescription     sarifMessage       `json:"shortDescription"`
+	DefaultConfiguration sarifConfiguration `json:"defaultConfiguration"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId,omitempty"`
+	RuleIndex *int            `json:"ruleIndex,omitempty"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation  `json:"physicalLocation"`
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn"`
+}
+
+type sarifLogicalLocation struct {
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind"`
+}
+
+// sarifLevel converts a severity to a SARIF level.
+func sarifLevel(s Severity) string {
+	switch s {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	}
+	return "note"
+}
+
+// SARIF returns the problems of files as a SARIF 2.1.0 log, as used by
+// code scanning services. rules describe the rules of the problems, see
+// ValidationRules and LintRules.
+// Problems are located at the line and column of their element, or of its
+// closest ancestor in the POM when it is missing, like the version of a
+// plugin without one.
+func SARIF(rules []Rule, files []ReportFile) ([]byte, error) {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           "gopom",
+			InformationURI: "https://github.com/chainguard-dev/gopom",
+			Rules:          []sarifRule{},
+		}},
+		Results: []sarifResult{},
+	}
+	index := map[string]int{}
+	for i, r := range rules {
+		index[r.ID] = i
+		run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{
+			ID:                   r.ID,
+			ShortDescription:     sarifMessage{Text: r.Description},
+			DefaultConfiguration: sarifConfiguration{Level: sarifLevel(r.Severity)},
+		})
+	}
+	for _, f := range files {
+		uri := reportURI(f.Path)
+		for _, pb := range f.Problems {
+			result := sarifResult{
+				RuleID:  pb.Rule,
+				Level:   sarifLevel(pb.Severity),
+				Message: sarifMessage{Text: pb.Message},
+				Locations: []sarifLocation{{
+					PhysicalLocation: sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: uri}},
+					LogicalLocations: []sarifLogicalLocation{{FullyQualifiedName: pb.Path, Kind: "element"}},
+				}},
+			}
+			if i, ok := index[pb.Rule]; ok {
+				result.RuleIndex = &i
+			}
+			if pos, ok := f.Project.locate(pb.Path); ok {
+				result.Locations[0].PhysicalLocation.Region = &sarifRegion{StartLine: pos.Line, StartColumn: pos.Column}
+			}
+			run.Results = append(run.Results, result)
+		}
+	}
+	return json.MarshalIndent(sarifLog{Schema: sarifSchema, Version: "2.1.0", Runs: []sarifRun{run}}, "", "  ")
+}
+
+// reportURI returns the URI of the file at path.
+func reportURI(path string) string {
+	u := url.URL{Path: filepath.ToSlash(path)}
+	if filepath.IsAbs(path) {
+		u.Scheme = "file"
+		if !strings.HasPrefix(u.Path, "/") {
+			// Windows paths, like C:/pom.xml.
+			u.Path = "/" + u.Path
+		}
+	}
+	return u.String()
+}
+
+// locate returns the position of the element at path, or of its closest
+// ancestor in the source of p.
+func (p *Project) locate(path string) (Position, bool) {
+	if p == nil {
+		return Position{}, false
+	}
+	for {
+		if pos, ok := p.Position(path); ok {
+			return pos, true
+		}
+		i := strings.LastIndex(path, "/")
+		if i < 0 {
+			return Position{}, false
+		}
+		path = path[:i]
+	}
+}
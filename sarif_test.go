@@ -0,0 +1,111 @@
+package gopom
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSARIF(t *testing.T) {
+	p, err := Parse("testdata/lint/pom.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	problems, err := p.Lint(nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rules []Rule
+	for _, r := range LintRules() {
+		rules = append(rules, r.Rule)
+	}
+	b, err := SARIF(rules, []ReportFile{{Path: "testdata/lint/pom.xml", Project: p, Problems: problems}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var log sarifLog
+	if err := json.Unmarshal(b, &log); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "2.1.0", log.Version)
+	run := log.Runs[0]
+	assert.Len(t, run.Tool.Driver.Rules, len(LintRules()))
+	assert.Equal(t, "insecure-repository", run.Tool.Driver.Rules[5].ID)
+	assert.Equal(t, "error", run.Tool.Driver.Rules[5].DefaultConfiguration.Level)
+	assert.Len(t, run.Results, len(problems))
+
+	// The plugin without version is located at its element.
+	first := run.Results[0]
+	assert.Equal(t, "plugin-version", first.RuleID)
+	assert.Equal(t, 0, *first.RuleIndex)
+	assert.Equal(t, "warning", first.Level)
+	assert.Equal(t, "testdata/lint/pom.xml", first.Locations[0].PhysicalLocation.ArtifactLocation.URI)
+	assert.Equal(t, &sarifRegion{StartLine: 85, StartColumn: 13}, first.Locations[0].PhysicalLocation.Region)
+	assert.Equal(t, "project/build[0]/plugins[0]/plugin[0]", first.Locations[0].LogicalLocations[0].FullyQualifiedName)
+
+	// Unused properties are notes.
+	assert.Equal(t, "note", run.Results[5].Level)
+}
+
+func TestSARIFWithoutSource(t *testing.T) {
+	b, err := SARIF(nil, []ReportFile{{Path: "pom.xml", Problems: []Problem{{Severity: SeverityError, Path: "project/modelVersion[0]", Message: "'modelVersion' is missing."}}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var log sarifLog
+	if err := json.Unmarshal(b, &log); err != nil {
+		t.Fatal(err)
+	}
+	result := log.Runs[0].Results[0]
+	assert.Nil(t, result.RuleIndex)
+	assert.Nil(t, result.Locations[0].PhysicalLocation.Region)
+	assert.Equal(t, "error", result.Level)
+}
+
+func TestSARIFValidation(t *testing.T) {
+	p, err := Parse("testdata/invalid.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := SARIF(ValidationRules(), []ReportFile{{Path: "testdata/my pom.xml", Project: p, Problems: p.Validate()}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var log sarifLog
+	if err := json.Unmarshal(b, &log); err != nil {
+		t.Fatal(err)
+	}
+	results := log.Runs[0].Results
+	assert.Len(t, results, len(p.Validate()))
+	for _, result := range results {
+		assert.NotNil(t, result.RuleIndex, result.RuleID)
+		assert.NotNil(t, result.Locations[0].PhysicalLocation.Region, result.RuleID)
+	}
+	assert.Equal(t, "model-version", results[0].RuleID)
+	assert.Equal(t, "testdata/my%20pom.xml", results[0].Locations[0].PhysicalLocation.ArtifactLocation.URI)
+}
+
+func TestReportURI(t *testing.T) {
+	assert.Equal(t, "pom.xml", reportURI("pom.xml"))
+	assert.Equal(t, "app/pom.xml", reportURI("app/pom.xml"))
+	assert.Equal(t, "file:///work/app/pom.xml", reportURI("/work/app/pom.xml"))
+}
+
+func TestSARIFReport(t *testing.T) {
+	p, err := Parse("testdata/invalid.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := SARIF(ValidationRules(), []ReportFile{{Path: "testdata/invalid.xml", Project: p, Problems: p.Validate()}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := os.ReadFile("testdata/report/validate.sarif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, string(want), string(b))
+}
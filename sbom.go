@@ -0,0 +1,166 @@
+package gopom
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// DependencyNode is a node of a resolved dependency graph: a dependency,
+// what is known about its artifact, and its own dependencies.
+type DependencyNode struct {
+	Dependency Dependency
+	// Hashes are the digests of the artifact, if known.
+	Hashes []Hash
+	// Licenses are the licenses declared by the dependency's POM, if known.
+	Licenses []License
+	// Dependencies are the transitive dependencies brought by this one.
+	Dependencies []*DependencyNode
+}
+
+// Hash is the digest of an artifact. Algorithm uses the CycloneDX names:
+// MD5, SHA-1, SHA-256, SHA-384 or SHA-512.
+type Hash struct {
+	Algorithm string
+	Value     string
+}
+
+// SBOMOptions configures the generation of software bills of materials.
+type SBOMOptions struct {
+	// Graph is the resolved dependency graph of the project. When it is
+	// nil, the dependencies declared by the project are used: pass an
+	// effective project, see Effective, to include inherited and managed
+	// dependencies.
+	Graph []*DependencyNode
+	// SerialNumber identifies the document, like
+	// "urn:uuid:3e671687-395b-41f5-a30f-a58921a69b79". It is omitted when
+	// empty.
+	SerialNumber string
+	// Timestamp is when the document was created. It is omitted when zero,
+	// so the output only depends on the project.
+	Timestamp time.Time
+}
+
+// sbom is the document independent content of a bill of materials.
+type sbom struct {
+	root sbomPackage
+	// packages are sorted by ref, and do not include root.
+	packages []sbomPackage
+	// edges are sorted by from and to refs.
+	edges []sbomEdge
+}
+
+type sbomPackage struct {
+	Coordinate
+	ref string
+	// scope is the Maven scope through which the package is required the
+	// most, and optional whether it is only required optionally.
+	scope    string
+	optional bool
+	hashes   []Hash
+	licenses []License
+}
+
+type sbomEdge struct {
+	from, to string
+	scope    string
+	optional bool
+}
+
+// newSBOM gathers the packages and relationships of p's bill of materials.
+func newSBOM(p *Project, opts SBOMOptions) *sbom {
+	c := p.Coordinate()
+	s := &sbom{root: sbomPackage{Coordinate: c, ref: c.PURL()}}
+	if p.Licenses != nil {
+		s.root.licenses = *p.Licenses
+	}
+
+	graph := opts.Graph
+	if graph == nil && p.Dependencies != nil {
+		for _, d := range *p.Dependencies {
+			graph = append(graph, &DependencyNode{Dependency: d})
+		}
+	}
+
+	packages := map[string]*sbomPackage{}
+	edges := map[sbomEdge]bool{}
+	var walk func(from string, nodes []*DependencyNode, seen map[*DependencyNode]bool)
+	walk = func(from string, nodes []*DependencyNode, seen map[*DependencyNode]bool) {
+		for _, n := range nodes {
+			if seen[n] {
+				continue
+			}
+			d := n.Dependency
+			scope := d.Scope
+			if scope == "" {
+				scope = "compile"
+			}
+			optional := strings.TrimSpace(d.Optional) == "true"
+			c := d.Coordinate()
+			ref := c.PURL()
+			edges[sbomEdge{from: from, to: ref, scope: scope, optional: optional}] = true
+
+			pkg, ok := packages[ref]
+			if !ok {
+				pkg = &sbomPackage{Coordinate: c, ref: ref, scope: scope, optional: optional}
+				packages[ref] = pkg
+			} else {
+				if scopeRank(scope) < scopeRank(pkg.scope) {
+					pkg.scope = scope
+				}
+				pkg.optional = pkg.optional && optional
+			}
+			if len(pkg.hashes) == 0 {
+				pkg.hashes = n.Hashes
+			}
+			if len(pkg.licenses) == 0 {
+				pkg.licenses = n.Licenses
+			}
+
+			seen[n] = true
+			walk(ref, n.Dependencies, seen)
+			delete(seen, n)
+		}
+	}
+	walk(s.root.ref, graph, map[*DependencyNode]bool{})
+
+	for _, pkg := range packages {
+		if pkg.ref != s.root.ref {
+			s.packages = append(s.packages, *pkg)
+		}
+	}
+	sort.Slice(s.packages, func(i, j int) bool { return s.packages[i].ref < s.packages[j].ref })
+	for e := range edges {
+		if e.from != e.to {
+			s.edges = append(s.edges, e)
+		}
+	}
+	sort.Slice(s.edges, func(i, j int) bool {
+		a, b := s.edges[i], s.edges[j]
+		if a.from != b.from {
+			return a.from < b.from
+		}
+		if a.to != b.to {
+			return a.to < b.to
+		}
+		return scopeRank(a.scope) < scopeRank(b.scope)
+	})
+	return s
+}
+
+// scopeRank orders Maven scopes from the most to the least required.
+func scopeRank(scope string) int {
+	switch scope {
+	case "compile":
+		return 0
+	case "runtime":
+		return 1
+	case "provided":
+		return 2
+	case "system":
+		return 3
+	case "test":
+		return 4
+	}
+	return 5
+}
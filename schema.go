@@ -0,0 +1,128 @@
+package gopom
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+//go:generate sh -c "go run ./cmd/gopom schema > pom.schema.json"
+
+// SchemaID is the identifier of the JSON Schema returned by JSONSchema.
+const SchemaID = "https://github.com/chainguard-dev/gopom/pom.schema.json"
+
+// JSONSchema returns the JSON Schema of the JSON representation of a
+// Project, generated from the model types. It is also published as
+// pom.schema.json at the root of the repository.
+func JSONSchema() ([]byte, error) {
+	g := schemaGenerator{defs: map[string]any{}}
+	root := g.schema(reflect.TypeOf(Project{}))
+	schema := map[string]any{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"$id":     SchemaID,
+		"title":   "Maven POM",
+		"$ref":    root["$ref"],
+		"$defs":   g.defs,
+	}
+	b, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(b, '\n'), nil
+}
+
+type schemaGenerator struct {
+	defs map[string]any
+}
+
+// scalarSchema is the schema of values that are decoded as text.
+var scalarSchema = map[string]any{"type": []string{"string", "number", "boolean"}}
+
+func (g *schemaGenerator) schema(t reflect.Type) map[string]any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t {
+	case reflect.TypeOf(Properties{}):
+		return g.def("Properties", func() map[string]any {
+			return map[string]any{
+				"description":          "Properties, in document order.",
+				"type":                 "object",
+				"additionalProperties": scalarSchema,
+			}
+		})
+	case reflect.TypeOf(Configuration{}):
+		g.def("ConfigurationValue", func() map[string]any {
+			return map[string]any{
+				"description": "A configuration element: text, repeated elements, or an element with attributes (\"@\" fields), text (\"#text\") or children.",
+				"anyOf": []any{
+					scalarSchema,
+					map[string]any{"type": "array", "items": map[string]any{"$ref": "#/$defs/ConfigurationValue"}},
+					map[string]any{"type": "object", "additionalProperties": map[string]any{"$ref": "#/$defs/ConfigurationValue"}},
+				},
+			}
+		})
+		return g.def("Configuration", func() map[string]any {
+			return map[string]any{
+				"description": "A plugin configuration, as nested objects.",
+				"anyOf": []any{
+					scalarSchema,
+					map[string]any{
+						"type": "object",
+						"properties": map[string]any{
+							"@combine.children": map[string]any{"type": "string"},
+							"@combine.self":     map[string]any{"type": "string"},
+						},
+						"additionalProperties": map[string]any{"$ref": "#/$defs/ConfigurationValue"},
+					},
+				},
+			}
+		})
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Slice:
+		return map[string]any{"type": "array", "items": g.schema(t.Elem())}
+	case reflect.Struct:
+		return g.def(t.Name(), func() map[string]any {
+			properties := map[string]any{}
+			g.properties(t, properties)
+			return map[string]any{
+				"type":                 "object",
+				"properties":           properties,
+				"additionalProperties": false,
+			}
+		})
+	}
+	return map[string]any{}
+}
+
+// def registers the definition built by build under name, once, and
+// returns a reference to it.
+func (g *schemaGenerator) def(name string, build func() map[string]any) map[string]any {
+	if _, ok := g.defs[name]; !ok {
+		// Register the name first, for recursive types.
+		g.defs[name] = nil
+		g.defs[name] = build()
+	}
+	return map[string]any{"$ref": "#/$defs/" + name}
+}
+
+func (g *schemaGenerator) properties(t reflect.Type, properties map[string]any) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous {
+			g.properties(f.Type, properties)
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if !f.IsExported() || name == "" || name == "-" {
+			continue
+		}
+		properties[name] = g.schema(f.Type)
+	}
+}
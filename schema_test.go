@@ -0,0 +1,188 @@
+package gopom
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJSONSchemaUpToDate(t *testing.T) {
+	want, err := os.ReadFile("pom.schema.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := JSONSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, string(want), string(got), "pom.schema.json is out of date, run go generate")
+}
+
+func TestJSONSchemaMatchesModel(t *testing.T) {
+	b, err := JSONSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var schema map[string]any
+	if err := json.Unmarshal(b, &schema); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range []string{"testdata/example.xml", queryFilename} {
+		p, err := Parse(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := json.Marshal(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var doc any
+		if err := json.Unmarshal(b, &doc); err != nil {
+			t.Fatal(err)
+		}
+		assert.NoError(t, checkSchema(schema, schema, doc, "$"), path)
+	}
+}
+
+// checkSchemaFile validates the JSON document b against the schema at path.
+func checkSchemaFile(t *testing.T, path string, b []byte) {
+	t.Helper()
+	schemaJSON, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var schema map[string]any
+	if err := json.Unmarshal(schemaJSON, &schema); err != nil {
+		t.Fatal(err)
+	}
+	var doc any
+	if err := json.Unmarshal(b, &doc); err != nil {
+		t.Fatal(err)
+	}
+	assert.NoError(t, checkSchema(schema, schema, doc, "$"))
+}
+
+// checkSchema checks v against the subset of JSON Schema used by
+// JSONSchema and the schemas in testdata.
+func checkSchema(root, s map[string]any, v any, path string) error {
+	if ref, ok := s["$ref"].(string); ok {
+		def := root["$defs"].(map[string]any)[strings.TrimPrefix(ref, "#/$defs/")]
+		return checkSchema(root, def.(map[string]any), v, path)
+	}
+	if anyOf, ok := s["anyOf"].([]any); ok {
+		matches := 0
+		for _, alt := range anyOf {
+			if checkSchema(root, alt.(map[string]any), v, path) == nil {
+				matches++
+			}
+		}
+		if matches == 0 {
+			return fmt.Errorf("%s: no alternative matches", path)
+		}
+	}
+	if oneOf, ok := s["oneOf"].([]any); ok {
+		matches := 0
+		for _, alt := range oneOf {
+			if checkSchema(root, alt.(map[string]any), v, path) == nil {
+				matches++
+			}
+		}
+		if matches != 1 {
+			return fmt.Errorf("%s: %d alternatives match instead of one", path, matches)
+		}
+	}
+	if enum, ok := s["enum"].([]any); ok && !containsValue(enum, v) {
+		return fmt.Errorf("%s: %v is not one of %v", path, v, enum)
+	}
+	if pattern, ok := s["pattern"].(string); ok {
+		if str, ok := v.(string); ok && !regexp.MustCompile(pattern).MatchString(str) {
+			return fmt.Errorf("%s: %q does not match %s", path, str, pattern)
+		}
+	}
+
+	var types []any
+	switch typ := s["type"].(type) {
+	case string:
+		types = []any{typ}
+	case []any:
+		types = typ
+	}
+	var kind string
+	switch n := v.(type) {
+	case string:
+		kind = "string"
+	case bool:
+		kind = "boolean"
+	case float64:
+		kind = "number"
+		if n == float64(int64(n)) && contains(types, "integer") {
+			kind = "integer"
+		}
+	case []any:
+		kind = "array"
+	case map[string]any:
+		kind = "object"
+	}
+	if len(types) > 0 && !contains(types, kind) {
+		return fmt.Errorf("%s: %s is not one of %v", path, kind, types)
+	}
+
+	switch v := v.(type) {
+	case []any:
+		items, _ := s["items"].(map[string]any)
+		for i, item := range v {
+			if err := checkSchema(root, items, item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	case map[string]any:
+		required, _ := s["required"].([]any)
+		for _, key := range required {
+			if _, ok := v[key.(string)]; !ok {
+				return fmt.Errorf("%s: missing property %s", path, key)
+			}
+		}
+		properties, _ := s["properties"].(map[string]any)
+		for key, value := range v {
+			prop, ok := properties[key].(map[string]any)
+			if !ok {
+				switch additional := s["additionalProperties"].(type) {
+				case map[string]any:
+					prop = additional
+				case bool:
+					if !additional {
+						return fmt.Errorf("%s: unexpected property %s", path, key)
+					}
+				}
+			}
+			if err := checkSchema(root, prop, value, path+"."+key); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func containsValue(values []any, v any) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(values []any, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,203 @@
+package gopom
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// SettingsSecurity is the content of settings-security.xml, which holds the
+// master password the passwords of the settings are encrypted with.
+type SettingsSecurity struct {
+	XMLName xml.Name `xml:"settingsSecurity" json:"-" yaml:"-"`
+	// Master is the master password, itself encrypted, see
+	// MasterPassword.
+	Master string `xml:"master,omitempty" json:"master,omitempty" yaml:"master,omitempty"`
+	// Relocation is the path of the file actually holding the master
+	// password, like one on a removable drive.
+	Relocation string `xml:"relocation,omitempty" json:"relocation,omitempty" yaml:"relocation,omitempty"`
+}
+
+// masterPasswordKey is the password Maven encrypts master passwords with.
+const masterPasswordKey = "settings.security"
+
+// maxRelocations bounds the chain of relocated settings security files.
+const maxRelocations = 8
+
+// SettingsSecurityFile returns the default location of the settings
+// security file, ~/.m2/settings-security.xml.
+func SettingsSecurityFile() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate the settings security: %w", err)
+	}
+	return filepath.Join(home, ".m2", "settings-security.xml"), nil
+}
+
+// ParseSettingsSecurity parses the settings security file at path,
+// following its relocation if any.
+func ParseSettingsSecurity(path string) (*SettingsSecurity, error) {
+	for i := 0; i < maxRelocations; i++ {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		var s SettingsSecurity
+		if err := xml.Unmarshal(b, &s); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		if s.Relocation == "" {
+			return &s, nil
+		}
+		path = s.Relocation
+	}
+	return nil, fmt.Errorf("too many relocations of the settings security, last one to %s", path)
+}
+
+// MasterPassword returns the decrypted master password.
+func (s *SettingsSecurity) MasterPassword() (string, error) {
+	if s.Master == "" {
+		return "", errors.New("the settings security has no master password")
+	}
+	master, err := DecryptPassword(s.Master, masterPasswordKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt the master password: %w", err)
+	}
+	return master, nil
+}
+
+// DecryptPasswords decrypts, in place, the passwords and passphrases of
+// the servers and the passwords of the proxies of s, with the master
+// password. Values that are not encrypted are left as is.
+func (s *Settings) DecryptPasswords(master string) error {
+	var values []*string
+	if s.Servers != nil {
+		for i := range *s.Servers {
+			server := &(*s.Servers)[i]
+			values = append(values, &server.Password, &server.Passphrase)
+		}
+	}
+	if s.Proxies != nil {
+		for i := range *s.Proxies {
+			values = append(values, &(*s.Proxies)[i].Password)
+		}
+	}
+	for _, v := range values {
+		clear, err := DecryptPassword(*v, master)
+		if err != nil {
+			return err
+		}
+		*v = clear
+	}
+	return nil
+}
+
+// IsEncryptedPassword tells whether s holds a password encrypted by Maven,
+// between braces not escaped with a backslash. Text around the braces, like
+// a note about the password, is allowed.
+func IsEncryptedPassword(s string) bool {
+	_, ok := encryptedPassword(s)
+	return ok
+}
+
+// encryptedPassword returns the part of s between braces, if any.
+func encryptedPassword(s string) (string, bool) {
+	start := unescapedIndex(s, '{', 0)
+	if start < 0 {
+		return "", false
+	}
+	end := unescapedIndex(s, '}', start+1)
+	if end < 0 {
+		return "", false
+	}
+	return s[start+1 : end], true
+}
+
+func unescapedIndex(s string, c byte, from int) int {
+	for i := from; i < len(s); i++ {
+		if s[i] == c && (i == 0 || s[i-1] != '\\') {
+			return i
+		}
+	}
+	return -1
+}
+
+// DecryptPassword decrypts the password s, encrypted with password like
+// `mvn --encrypt-password` does, using the AES scheme of plexus-cipher. s
+// is returned as is when it is not encrypted, see IsEncryptedPassword.
+func DecryptPassword(s, password string) (string, error) {
+	encoded, ok := encryptedPassword(s)
+	if !ok {
+		return s, nil
+	}
+	b, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt password: %w", err)
+	}
+	// The salt, the length of the padding, the ciphertext and the padding.
+	if len(b) < passwordSaltSize+1 {
+		return "", errors.New("failed to decrypt password: too short")
+	}
+	salt, padding := b[:passwordSaltSize], int(b[passwordSaltSize])
+	end := len(b) - padding
+	if end <= passwordSaltSize+1 || (end-passwordSaltSize-1)%aes.BlockSize != 0 {
+		return "", errors.New("failed to decrypt password: invalid length")
+	}
+	encrypted := b[passwordSaltSize+1 : end]
+
+	block, iv := passwordCipher(password, salt)
+	clear := make([]byte, len(encrypted))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(clear, encrypted)
+	n := int(clear[len(clear)-1])
+	if n == 0 || n > aes.BlockSize || !bytes.Equal(clear[len(clear)-n:], bytes.Repeat([]byte{byte(n)}, n)) {
+		return "", errors.New("failed to decrypt password: wrong password")
+	}
+	return string(clear[:len(clear)-n]), nil
+}
+
+// EncryptPassword encrypts clear with password the way
+// `mvn --encrypt-password` does, and returns it between braces as found in
+// settings files. The result differs on every call, as it is salted.
+func EncryptPassword(clear, password string) (string, error) {
+	salt := make([]byte, passwordSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("failed to encrypt password: %w", err)
+	}
+	n := aes.BlockSize - len(clear)%aes.BlockSize
+	padded := append([]byte(clear), bytes.Repeat([]byte{byte(n)}, n)...)
+	block, iv := passwordCipher(password, salt)
+	encrypted := make([]byte, len(padded))
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(encrypted, padded)
+
+	// Random bytes pad the result to a multiple of the block size.
+	padding := aes.BlockSize - (passwordSaltSize+len(encrypted)+1)%aes.BlockSize
+	b := make([]byte, passwordSaltSize+1+len(encrypted)+padding)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to encrypt password: %w", err)
+	}
+	copy(b, salt)
+	b[passwordSaltSize] = byte(padding)
+	copy(b[passwordSaltSize+1:], encrypted)
+	return "{" + base64.StdEncoding.EncodeToString(b) + "}", nil
+}
+
+// passwordSaltSize is the size of the salt of encrypted passwords.
+const passwordSaltSize = 8
+
+// passwordCipher returns the AES cipher and the initialization vector
+// derived from password and salt: the SHA-256 digest of both holds the key
+// followed by the vector.
+func passwordCipher(password string, salt []byte) (cipher.Block, []byte) {
+	digest := sha256.Sum256(append([]byte(password), salt...))
+	// The key is 16 bytes long, so NewCipher cannot fail.
+	block, _ := aes.NewCipher(digest[:aes.BlockSize])
+	return block, digest[aes.BlockSize:]
+}
@@ -0,0 +1,117 @@
+package gopom
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// The fixtures of testdata/settings hold the master password
+// "master-secret", and passwords encrypted with it, in the format of
+// `mvn --encrypt-master-password` and `mvn --encrypt-password`. They were
+// produced with EncryptPassword, not by Maven, so they only show that
+// decryption reverses encryption.
+//
+// TODO: add known-answer vectors from real `mvn --encrypt-master-password`
+// and `mvn --encrypt-password` runs, along with the Maven version and the
+// clear texts used.
+
+func TestSettingsSecurity(t *testing.T) {
+	security, err := ParseSettingsSecurity("testdata/settings/settings-security.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	master, err := security.MasterPassword()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "master-secret", master)
+
+	s, err := ParseSettings("testdata/settings/encrypted.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.DecryptPasswords(master); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "deployer-secret", s.Server("releases").Password)
+	assert.Equal(t, "key-passphrase", s.Server("internal").Passphrase)
+	assert.Equal(t, `not\{encrypted\}`, s.Server("plain").Password)
+	assert.Equal(t, "proxy-secret", (*s.Proxies)[0].Password)
+
+	s, err = ParseSettings("testdata/settings/encrypted.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = s.DecryptPasswords("wrong")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "wrong password")
+}
+
+func TestSettingsSecurityRelocation(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "settings-security.xml")
+	relocation := "<settingsSecurity><relocation>testdata/settings/settings-security.xml</relocation></settingsSecurity>"
+	if err := os.WriteFile(path, []byte(relocation), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	security, err := ParseSettingsSecurity(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "{vb7KYbjuVNcHLIDJ1a3Y5jOwgQgNZZQvOrEuKMTEzWg=}", security.Master)
+
+	loop := "<settingsSecurity><relocation>" + path + "</relocation></settingsSecurity>"
+	if err := os.WriteFile(path, []byte(loop), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	_, err = ParseSettingsSecurity(path)
+	assert.Error(t, err)
+
+	_, err = (&SettingsSecurity{}).MasterPassword()
+	assert.Error(t, err)
+}
+
+func TestEncryptPassword(t *testing.T) {
+	for _, clear := range []string{"", "secret", "exactly16bytes!!", "pässwörd with unicode and more than one block"} {
+		encrypted, err := EncryptPassword(clear, "master")
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.True(t, IsEncryptedPassword(encrypted))
+		decrypted, err := DecryptPassword(encrypted, "master")
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, clear, decrypted)
+		again, err := EncryptPassword(clear, "master")
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.NotEqual(t, encrypted, again)
+	}
+}
+
+func TestDecryptPassword(t *testing.T) {
+	for _, test := range []struct {
+		in, want string
+		err      bool
+	}{
+		{in: "plain", want: "plain"},
+		{in: `\{escaped\}`, want: `\{escaped\}`},
+		{in: "{unclosed", want: "{unclosed"},
+		{in: "{not base64!}", err: true},
+		{in: "{AAAA}", err: true},
+		{in: "note {/qcs9AMtcXQH070R7U5+vj4Jz9nRiprKFeUxy3EoASI=} trailing", want: "proxy-secret"},
+	} {
+		got, err := DecryptPassword(test.in, "master-secret")
+		if test.err {
+			assert.Error(t, err, test.in)
+			continue
+		}
+		assert.NoError(t, err, test.in)
+		assert.Equal(t, test.want, got, test.in)
+	}
+}
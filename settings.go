@@ -0,0 +1,496 @@
+package gopom
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+)
+
+// Settings is the Maven configuration found in settings.xml files, see
+// LoadSettings.
+type Settings struct {
+	XMLName xml.Name `xml:"settings,omitempty" json:"-" yaml:"-"`
+	Xmlns   string   `xml:"xmlns,attr,omitempty" json:"xmlns,omitempty" yaml:"xmlns,omitempty"`
+	Xsi     string   `xml:"xsi,attr,omitempty" json:"xsi,omitempty" yaml:"xsi,omitempty"`
+	// XsiNS and SchemaLocationXSI are the names the attributes are
+	// marshaled with, see Project.
+	XsiNS             string             `xml:"xmlns:xsi,attr,omitempty" json:"-" yaml:"-"`
+	SchemaLocation    string             `xml:"schemaLocation,attr,omitempty" json:"schemaLocation,omitempty" yaml:"schemaLocation,omitempty"`
+	SchemaLocationXSI string             `xml:"xsi:schemaLocation,attr,omitempty" json:"-" yaml:"-"`
+	LocalRepository   string             `xml:"localRepository,omitempty" json:"localRepository,omitempty" yaml:"localRepository,omitempty"`
+	InteractiveMode   string             `xml:"interactiveMode,omitempty" json:"interactiveMode,omitempty" yaml:"interactiveMode,omitempty"`
+	UsePluginRegistry string             `xml:"usePluginRegistry,omitempty" json:"usePluginRegistry,omitempty" yaml:"usePluginRegistry,omitempty"`
+	Offline           string             `xml:"offline,omitempty" json:"offline,omitempty" yaml:"offline,omitempty"`
+	Proxies           *[]Proxy           `xml:"proxies>proxy,omitempty" json:"proxies,omitempty" yaml:"proxies,omitempty"`
+	Servers           *[]Server          `xml:"servers>server,omitempty" json:"servers,omitempty" yaml:"servers,omitempty"`
+	Mirrors           *[]Mirror          `xml:"mirrors>mirror,omitempty" json:"mirrors,omitempty" yaml:"mirrors,omitempty"`
+	Profiles          *[]SettingsProfile `xml:"profiles>profile,omitempty" json:"profiles,omitempty" yaml:"profiles,omitempty"`
+	ActiveProfiles    *[]string          `xml:"activeProfiles>activeProfile,omitempty" json:"activeProfiles,omitempty" yaml:"activeProfiles,omitempty"`
+	PluginGroups      *[]string          `xml:"pluginGroups>pluginGroup,omitempty" json:"pluginGroups,omitempty" yaml:"pluginGroups,omitempty"`
+}
+
+type Proxy struct {
+	ID            string `xml:"id,omitempty" json:"id,omitempty" yaml:"id,omitempty"`
+	Active        string `xml:"active,omitempty" json:"active,omitempty" yaml:"active,omitempty"`
+	Protocol      string `xml:"protocol,omitempty" json:"protocol,omitempty" yaml:"protocol,omitempty"`
+	Username      string `xml:"username,omitempty" json:"username,omitempty" yaml:"username,omitempty"`
+	Password      string `xml:"password,omitempty" json:"password,omitempty" yaml:"password,omitempty"`
+	Port          string `xml:"port,omitempty" json:"port,omitempty" yaml:"port,omitempty"`
+	Host          string `xml:"host,omitempty" json:"host,omitempty" yaml:"host,omitempty"`
+	NonProxyHosts string `xml:"nonProxyHosts,omitempty" json:"nonProxyHosts,omitempty" yaml:"nonProxyHosts,omitempty"`
+
+	comments *Comments
+}
+
+type Server struct {
+	ID                   string         `xml:"id,omitempty" json:"id,omitempty" yaml:"id,omitempty"`
+	Username             string         `xml:"username,omitempty" json:"username,omitempty" yaml:"username,omitempty"`
+	Password             string         `xml:"password,omitempty" json:"password,omitempty" yaml:"password,omitempty"`
+	PrivateKey           string         `xml:"privateKey,omitempty" json:"privateKey,omitempty" yaml:"privateKey,omitempty"`
+	Passphrase           string         `xml:"passphrase,omitempty" json:"passphrase,omitempty" yaml:"passphrase,omitempty"`
+	FilePermissions      string         `xml:"filePermissions,omitempty" json:"filePermissions,omitempty" yaml:"filePermissions,omitempty"`
+	DirectoryPermissions string         `xml:"directoryPermissions,omitempty" json:"directoryPermissions,omitempty" yaml:"directoryPermissions,omitempty"`
+	Configuration        *Configuration `xml:"configuration,omitempty" json:"configuration,omitempty" yaml:"configuration,omitempty"`
+
+	comments *Comments
+}
+
+type Mirror struct {
+	ID              string `xml:"id,omitempty" json:"id,omitempty" yaml:"id,omitempty"`
+	Name            string `xml:"name,omitempty" json:"name,omitempty" yaml:"name,omitempty"`
+	URL             string `xml:"url,omitempty" json:"url,omitempty" yaml:"url,omitempty"`
+	MirrorOf        string `xml:"mirrorOf,omitempty" json:"mirrorOf,omitempty" yaml:"mirrorOf,omitempty"`
+	Layout          string `xml:"layout,omitempty" json:"layout,omitempty" yaml:"layout,omitempty"`
+	MirrorOfLayouts string `xml:"mirrorOfLayouts,omitempty" json:"mirrorOfLayouts,omitempty" yaml:"mirrorOfLayouts,omitempty"`
+	Blocked         string `xml:"blocked,omitempty" json:"blocked,omitempty" yaml:"blocked,omitempty"`
+
+	comments *Comments
+}
+
+// SettingsProfile is a profile of the settings. Active settings profiles
+// apply to every project, see EffectiveOptions.
+type SettingsProfile struct {
+	ID                 string              `xml:"id,omitempty" json:"id,omitempty" yaml:"id,omitempty"`
+	Activation         *Activation         `xml:"activation,omitempty" json:"activation,omitempty" yaml:"activation,omitempty"`
+	Properties         *Properties         `xml:"properties,omitempty" json:"properties,omitempty" yaml:"properties,omitempty"`
+	Repositories       *[]Repository       `xml:"repositories>repository,omitempty" json:"repositories,omitempty" yaml:"repositories,omitempty"`
+	PluginRepositories *[]PluginRepository `xml:"pluginRepositories>pluginRepository,omitempty" json:"pluginRepositories,omitempty" yaml:"pluginRepositories,omitempty"`
+
+	comments *Comments
+}
+
+// ParseSettings parses the settings file at path. Like Parse, it keeps the
+// comments attached to proxies, servers, mirrors, profiles and properties.
+func ParseSettings(path string) (*Settings, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return parseSettings(b)
+}
+
+// ParseSettingsReader parses the settings read from r.
+func ParseSettingsReader(r io.Reader) (*Settings, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return parseSettings(b)
+}
+
+func parseSettings(b []byte) (*Settings, error) {
+	var s Settings
+	if err := xml.Unmarshal(b, &s); err != nil {
+		return nil, err
+	}
+	if _, err := scanSource(b, settingsCommentTargets(&s)); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// Marshal marshals the settings into a byte slice, the same way
+// Project.Marshal does.
+func (s *Settings) Marshal() ([]byte, error) {
+	c := s.Clone()
+	if c.SchemaLocation != "" {
+		c.SchemaLocationXSI, c.SchemaLocation = c.SchemaLocation, ""
+	}
+	if c.Xsi != "" {
+		c.XsiNS, c.Xsi = c.Xsi, ""
+	}
+	marshalled, err := marshalIndent("", "    ", func(e *xml.Encoder) error { return e.Encode(c) })
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal: %w", err)
+	}
+	return append([]byte(xml.Header), marshalled...), nil
+}
+
+// Clone returns a deep copy of the settings, including their comments.
+func (s *Settings) Clone() *Settings {
+	if s == nil {
+		return nil
+	}
+	c := deepCopy(reflect.ValueOf(s)).Interface().(*Settings)
+	copyComments(settingsCommentTargets(c), settingsCommentTargets(s))
+	return c
+}
+
+// UserSettingsFile returns the location of the user settings,
+// ~/.m2/settings.xml.
+func UserSettingsFile() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate the user settings: %w", err)
+	}
+	return filepath.Join(home, ".m2", "settings.xml"), nil
+}
+
+// GlobalSettingsFile returns the location of the global settings, in the
+// conf directory of the Maven installation pointed at by MAVEN_HOME or
+// M2_HOME, or an empty string when neither is set.
+func GlobalSettingsFile() string {
+	for _, env := range []string{"MAVEN_HOME", "M2_HOME"} {
+		if home := os.Getenv(env); home != "" {
+			return filepath.Join(home, "conf", "settings.xml")
+		}
+	}
+	return ""
+}
+
+// LoadSettings reads the global and user settings files, either of which
+// may be empty or missing, and merges them like Maven does: see
+// MergeSettings. ${env.NAME} expressions, as well as ${user.home}, are
+// then interpolated.
+//
+// To load the settings Maven would use, pass GlobalSettingsFile and
+// UserSettingsFile.
+func LoadSettings(global, user string) (*Settings, error) {
+	var files []*Settings
+	for _, path := range []string{global, user} {
+		if path == "" {
+			files = append(files, nil)
+			continue
+		}
+		s, err := ParseSettings(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			files = append(files, nil)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		files = append(files, s)
+	}
+	s := MergeSettings(files[0], files[1])
+	s.interpolate()
+	return s, nil
+}
+
+// MergeSettings merges the global and user settings, either of which may be
+// nil. User settings win: their values are kept when set, and proxies,
+// servers, mirrors and profiles of the global settings are appended unless
+// the user settings have one with the same ID. Active profiles and plugin
+// groups are combined. The arguments are not modified.
+func MergeSettings(global, user *Settings) *Settings {
+	if user == nil {
+		user = &Settings{}
+	}
+	merged := user.Clone()
+	if global == nil {
+		return merged
+	}
+	global = global.Clone()
+	for _, f := range []struct{ user, global *string }{
+		{&merged.LocalRepository, &global.LocalRepository},
+		{&merged.InteractiveMode, &global.InteractiveMode},
+		{&merged.UsePluginRegistry, &global.UsePluginRegistry},
+		{&merged.Offline, &global.Offline},
+	} {
+		if *f.user == "" {
+			*f.user = *f.global
+		}
+	}
+	merged.Proxies = mergeByID(merged.Proxies, global.Proxies, func(p Proxy) string { return p.ID })
+	merged.Servers = mergeByID(merged.Servers, global.Servers, func(s Server) string { return s.ID })
+	merged.Mirrors = mergeByID(merged.Mirrors, global.Mirrors, func(m Mirror) string { return m.ID })
+	merged.Profiles = mergeByID(merged.Profiles, global.Profiles, func(p SettingsProfile) string { return p.ID })
+	merged.ActiveProfiles = mergeByID(merged.ActiveProfiles, global.ActiveProfiles, func(s string) string { return s })
+	merged.PluginGroups = mergeByID(merged.PluginGroups, global.PluginGroups, func(s string) string { return s })
+	return merged
+}
+
+// mergeByID appends the elements of recessive to the dominant ones, unless
+// one of them has the same ID.
+func mergeByID[T any](dominant, recessive *[]T, id func(T) string) *[]T {
+	if recessive == nil || len(*recessive) == 0 {
+		return dominant
+	}
+	merged := append([]T(nil), derefSlice(dominant)...)
+	seen := map[string]bool{}
+	for _, e := range merged {
+		seen[id(e)] = true
+	}
+	for _, e := range *recessive {
+		if !seen[id(e)] {
+			seen[id(e)] = true
+			merged = append(merged, e)
+		}
+	}
+	return &merged
+}
+
+func derefSlice[T any](s *[]T) []T {
+	if s == nil {
+		return nil
+	}
+	return *s
+}
+
+// interpolate replaces the ${env.NAME} and ${user.home} expressions in the
+// values of s.
+func (s *Settings) interpolate() {
+	lookup := func(name string) (string, bool) {
+		if env, ok := strings.CutPrefix(name, "env."); ok {
+			return os.LookupEnv(env)
+		}
+		if name == "user.home" {
+			home, err := os.UserHomeDir()
+			return home, err == nil
+		}
+		return "", false
+	}
+	interpolateValue(reflect.ValueOf(s).Elem(), func(v string) string {
+		return expand(v, lookup, 0)
+	})
+}
+
+// LocalRepositoryPath returns the local repository of the settings, which
+// defaults to ~/.m2/repository, see LocalRepository.
+func (s *Settings) LocalRepositoryPath() (string, error) {
+	if s != nil && s.LocalRepository != "" {
+		return s.LocalRepository, nil
+	}
+	return LocalRepository()
+}
+
+// ActivatedProfiles returns the profiles of the settings that are active
+// when building the project located in dir: the ones listed in
+// ActiveProfiles or in ids, and the ones whose activation triggers, following
+// the rules of Project.ActiveProfiles.
+func (s *Settings) ActivatedProfiles(dir string, ids ...string) []*SettingsProfile {
+	if s == nil || s.Profiles == nil {
+		return nil
+	}
+	ids = append(append([]string(nil), derefSlice(s.ActiveProfiles)...), ids...)
+	var active []*SettingsProfile
+	for _, i := range activeProfiles(len(*s.Profiles), func(i int) (string, *Activation) {
+		return (*s.Profiles)[i].ID, (*s.Profiles)[i].Activation
+	}, dir, ids) {
+		active = append(active, &(*s.Profiles)[i])
+	}
+	return active
+}
+
+// Server returns the server with the given ID, which holds the credentials
+// of the repository with the same ID, or nil.
+func (s *Settings) Server(id string) *Server {
+	if s == nil || s.Servers == nil {
+		return nil
+	}
+	for i, server := range *s.Servers {
+		if server.ID == id {
+			return &(*s.Servers)[i]
+		}
+	}
+	return nil
+}
+
+// Proxy returns the first active proxy for protocol, which defaults to
+// http, or nil.
+func (s *Settings) Proxy(protocol string) *Proxy {
+	if s == nil || s.Proxies == nil {
+		return nil
+	}
+	if protocol == "" {
+		protocol = "http"
+	}
+	for i, p := range *s.Proxies {
+		if strings.TrimSpace(p.Active) == "false" {
+			continue
+		}
+		if p.Protocol == protocol || p.Protocol == "" && protocol == "http" {
+			return &(*s.Proxies)[i]
+		}
+	}
+	return nil
+}
+
+// Mirror returns the mirror to use instead of the repository with the given
+// ID and URL, or nil. A mirror whose mirrorOf is the ID wins over the ones
+// matching it through patterns: *, external:*, external:http:*, lists
+// separated by commas and exclusions prefixed with !.
+func (s *Settings) Mirror(id, repositoryURL string) *Mirror {
+	if s == nil || s.Mirrors == nil {
+		return nil
+	}
+	for i, m := range *s.Mirrors {
+		if m.MirrorOf == id {
+			return &(*s.Mirrors)[i]
+		}
+	}
+	for i, m := range *s.Mirrors {
+		if mirrorMatches(m.MirrorOf, id, repositoryURL) {
+			return &(*s.Mirrors)[i]
+		}
+	}
+	return nil
+}
+
+// mirrorMatches tells whether the mirrorOf pattern matches the repository,
+// like Maven's DefaultMirrorSelector.
+func mirrorMatches(pattern, id, repositoryURL string) bool {
+	matched := false
+	for _, p := range strings.Split(pattern, ",") {
+		p = strings.TrimSpace(p)
+		if excluded, ok := strings.CutPrefix(p, "!"); ok {
+			if excluded == id {
+				return false
+			}
+			continue
+		}
+		switch {
+		case p == id, p == "*":
+			matched = true
+		case p == "external:*":
+			matched = matched || externalRepository(repositoryURL)
+		case p == "external:http:*":
+			matched = matched || externalRepository(repositoryURL) && strings.HasPrefix(repositoryURL, "http://")
+		}
+	}
+	return matched
+}
+
+// externalRepository tells whether the repository at u is neither on the
+// local host nor a file.
+func externalRepository(u string) bool {
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return false
+	}
+	host := parsed.Hostname()
+	return parsed.Scheme != "file" && host != "localhost" && host != "127.0.0.1"
+}
+
+// profile returns the settings profile as a POM profile.
+func (p *SettingsProfile) profile() *Profile {
+	return &Profile{
+		ID:                 p.ID,
+		Activation:         p.Activation,
+		Properties:         p.Properties,
+		Repositories:       p.Repositories,
+		PluginRepositories: p.PluginRepositories,
+	}
+}
+
+// Comments returns the comments attached to the proxy. The result is never
+// nil, so it can be used to add new annotations.
+func (p *Proxy) Comments() *Comments {
+	if p.comments == nil {
+		p.comments = &Comments{}
+	}
+	return p.comments
+}
+
+// Comments returns the comments attached to the server. The result is never
+// nil, so it can be used to add new annotations.
+func (s *Server) Comments() *Comments {
+	if s.comments == nil {
+		s.comments = &Comments{}
+	}
+	return s.comments
+}
+
+// Comments returns the comments attached to the mirror. The result is never
+// nil, so it can be used to add new annotations.
+func (m *Mirror) Comments() *Comments {
+	if m.comments == nil {
+		m.comments = &Comments{}
+	}
+	return m.comments
+}
+
+// Comments returns the comments attached to the profile. The result is never
+// nil, so it can be used to add new annotations.
+func (p *SettingsProfile) Comments() *Comments {
+	if p.comments == nil {
+		p.comments = &Comments{}
+	}
+	return p.comments
+}
+
+func (p *Proxy) attached() *Comments { return p.comments }
+
+func (s *Server) attached() *Comments { return s.comments }
+
+func (m *Mirror) attached() *Comments { return m.comments }
+
+func (p *SettingsProfile) attached() *Comments { return p.comments }
+
+func (p Proxy) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	type proxy Proxy
+	return encodeCommented(e, start, proxy(p), p.comments)
+}
+
+func (s Server) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	type server Server
+	return encodeCommented(e, start, server(s), s.comments)
+}
+
+func (m Mirror) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	type mirror Mirror
+	return encodeCommented(e, start, mirror(m), m.comments)
+}
+
+func (p SettingsProfile) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	type profile SettingsProfile
+	return encodeCommented(e, start, profile(p), p.comments)
+}
+
+// settingsCommentTargets maps the element paths of s that can hold comments
+// to the model values they belong to.
+func settingsCommentTargets(s *Settings) map[string]commentable {
+	targets := map[string]commentable{}
+	const root = "settings"
+	addTargets(targets, root+"/proxies[0]", "proxy", s.Proxies)
+	addTargets(targets, root+"/servers[0]", "server", s.Servers)
+	addTargets(targets, root+"/mirrors[0]", "mirror", s.Mirrors)
+	addTargets(targets, root+"/profiles[0]", "profile", s.Profiles)
+	if s.Profiles != nil {
+		for i, profile := range *s.Profiles {
+			addPropertyTargets(targets, elementPath(root+"/profiles[0]", "profile", i)+"/properties[0]", profile.Properties)
+		}
+	}
+	return targets
+}
+
+// addTargets adds the elements of list, called name under parent, to
+// targets.
+func addTargets[T any, P interface {
+	*T
+	commentable
+}](targets map[string]commentable, parent, name string, list *[]T) {
+	if list == nil {
+		return
+	}
+	for i := range *list {
+		targets[elementPath(parent, name, i)] = P(&(*list)[i])
+	}
+}
@@ -0,0 +1,230 @@
+package gopom
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSettings(t *testing.T) {
+	s, err := ParseSettings("testdata/settings/settings.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "http://maven.apache.org/SETTINGS/1.2.0", s.Xmlns)
+	assert.Equal(t, "${env.GOPOM_TEST_HOME}/repository", s.LocalRepository)
+	assert.Equal(t, "false", s.Offline)
+	assert.Equal(t, "proxy.example.com", (*s.Proxies)[0].Host)
+	assert.Equal(t, "3128", (*s.Proxies)[0].Port)
+	assert.Len(t, *s.Servers, 2)
+	assert.Equal(t, "deployer", (*s.Servers)[0].Username)
+	assert.Contains(t, (*s.Servers)[1].Configuration.RawConfiguration, "<timeout>30000</timeout>")
+	assert.Equal(t, "external:*,!snapshots", (*s.Mirrors)[0].MirrorOf)
+	assert.Equal(t, []string{"java.version", "company.url"}, (*s.Profiles)[0].Properties.Order)
+	assert.Equal(t, "snapshots", (*(*s.Profiles)[0].Repositories)[0].ID)
+	assert.Equal(t, "env.CI", (*s.Profiles)[1].Activation.Property.Name)
+	assert.Equal(t, []string{"company", "strict"}, *s.ActiveProfiles)
+
+	assert.Equal(t, []string{"Deploys releases."}, (*s.Servers)[0].Comments().Leading)
+	assert.Equal(t, []string{"Proxies everything but the snapshots."}, (*s.Mirrors)[0].Comments().Trailing)
+	assert.Equal(t, []string{"Overrides the POM."}, (*s.Profiles)[0].Properties.Comments("java.version").Leading)
+}
+
+func TestSettingsRoundTrip(t *testing.T) {
+	s, err := ParseSettings("testdata/settings/settings.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := s.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := string(b)
+	assert.Contains(t, out, `<settings xmlns="http://maven.apache.org/SETTINGS/1.2.0" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:schemaLocation=`)
+	assert.Contains(t, out, "<!-- Deploys releases. -->\n        <server>")
+	assert.Contains(t, out, "<!-- Overrides the POM. -->\n                <java.version>21</java.version>")
+	// Marshal does not modify the settings.
+	assert.Equal(t, "http://www.w3.org/2001/XMLSchema-instance", s.Xsi)
+
+	again, err := ParseSettingsReader(strings.NewReader(out))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b2, err := again.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, out, string(b2))
+	assert.Equal(t, s.Servers, again.Servers)
+	assert.Equal(t, s.Profiles, again.Profiles)
+}
+
+func TestMergeSettings(t *testing.T) {
+	global, err := ParseSettings("testdata/settings/global.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	user, err := ParseSettings("testdata/settings/settings.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := MergeSettings(global, user)
+
+	assert.Equal(t, "${env.GOPOM_TEST_HOME}/repository", s.LocalRepository)
+	assert.Equal(t, "false", s.InteractiveMode)
+	var servers []string
+	for _, server := range *s.Servers {
+		servers = append(servers, server.ID+":"+server.Username)
+	}
+	assert.Equal(t, []string{"releases:deployer", "internal:", "thirdparty:admin"}, servers)
+	assert.Len(t, *s.Mirrors, 2)
+	assert.Len(t, *s.Profiles, 3)
+	assert.Equal(t, []string{"company", "strict", "global"}, *s.ActiveProfiles)
+	assert.Equal(t, []string{"org.sonatype.plugins"}, *s.PluginGroups)
+	assert.Equal(t, []string{"Deploys releases."}, (*s.Servers)[0].Comments().Leading)
+
+	// The inputs are not modified.
+	assert.Len(t, *user.Servers, 2)
+	assert.Nil(t, user.PluginGroups)
+
+	assert.Equal(t, user.Servers, MergeSettings(nil, user).Servers)
+	assert.Equal(t, global.Servers, MergeSettings(global, nil).Servers)
+}
+
+func TestLoadSettings(t *testing.T) {
+	t.Setenv("GOPOM_TEST_HOME", "/home/ci/.m2")
+	t.Setenv("GOPOM_TEST_PASSWORD", "secret")
+	s, err := LoadSettings("testdata/settings/global.xml", "testdata/settings/settings.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "/home/ci/.m2/repository", s.LocalRepository)
+	assert.Equal(t, "secret", s.Server("releases").Password)
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, home+"/.ssh/id_rsa", s.Server("internal").PrivateKey)
+	assert.Nil(t, s.Server("missing"))
+
+	repository, err := s.LocalRepositoryPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "/home/ci/.m2/repository", repository)
+
+	// Missing files are skipped.
+	s, err = LoadSettings("", filepath.Join(t.TempDir(), "settings.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Nil(t, s.Servers)
+	invalid := filepath.Join(t.TempDir(), "settings.xml")
+	if err := os.WriteFile(invalid, []byte("<settings><servers>"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	_, err = LoadSettings(invalid, "")
+	assert.Error(t, err)
+}
+
+func TestSettingsLookups(t *testing.T) {
+	global, err := ParseSettings("testdata/settings/global.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	user, err := ParseSettings("testdata/settings/settings.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := MergeSettings(global, user)
+
+	mirror := func(id, url string) string {
+		if m := s.Mirror(id, url); m != nil {
+			return m.ID
+		}
+		return ""
+	}
+	assert.Equal(t, "internal-mirror", mirror("central", "https://repo.maven.apache.org/maven2"))
+	assert.Equal(t, "", mirror("snapshots", "https://snapshots.example.com/maven2"))
+	assert.Equal(t, "", mirror("local", "file:///opt/repository"))
+	assert.Equal(t, "", mirror("dev", "http://localhost:8081/repository"))
+	assert.Equal(t, "internal-mirror", mirror("legacy", "http://legacy.example.com/maven2"))
+
+	blocker := &Settings{Mirrors: global.Mirrors}
+	assert.Equal(t, "maven-default-http-blocker", blocker.Mirror("legacy", "http://legacy.example.com/maven2").ID)
+	assert.Nil(t, blocker.Mirror("central", "https://repo.maven.apache.org/maven2"))
+	exact := &Settings{Mirrors: &[]Mirror{{ID: "all", MirrorOf: "*"}, {ID: "central-mirror", MirrorOf: "central"}}}
+	assert.Equal(t, "central-mirror", exact.Mirror("central", "").ID)
+
+	assert.Equal(t, "corporate", s.Proxy("https").ID)
+	assert.Nil(t, s.Proxy(""))
+	assert.Nil(t, (*Settings)(nil).Proxy("https"))
+}
+
+func TestSettingsActivatedProfiles(t *testing.T) {
+	global, err := ParseSettings("testdata/settings/global.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	user, err := ParseSettings("testdata/settings/settings.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := MergeSettings(global, user)
+	ids := func(profiles []*SettingsProfile) []string {
+		var ids []string
+		for _, p := range profiles {
+			ids = append(ids, p.ID)
+		}
+		return ids
+	}
+	assert.Equal(t, []string{"company", "global"}, ids(s.ActivatedProfiles(".")))
+	assert.Equal(t, []string{"company", "ci"}, ids(s.ActivatedProfiles(".", "ci", "!global")))
+	// Without active profiles, activeByDefault applies.
+	assert.Equal(t, []string{"global"}, ids((&Settings{Profiles: global.Profiles}).ActivatedProfiles(".")))
+	assert.Empty(t, ids(global.ActivatedProfiles(".", "!global")))
+	assert.Empty(t, ids(user.ActivatedProfiles(".", "-company")))
+}
+
+func TestEffectiveWithSettings(t *testing.T) {
+	s, err := LoadSettings("testdata/settings/global.xml", "testdata/settings/settings.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := Parse("testdata/settings/pom.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	eff, err := Effective(p, EffectiveOptions{Dir: "testdata/settings", Settings: s})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The strict POM profile is activated by the settings, which disables
+	// the profile active by default.
+	assert.Equal(t, "true", eff.Properties.Entries["strict"])
+	assert.NotContains(t, eff.Properties.Entries, "default")
+	// Settings profiles win over the POM, and take part in interpolation.
+	assert.Equal(t, "21", eff.Properties.Entries["java.version"])
+	assert.Equal(t, "set", eff.Properties.Entries["global.property"])
+	assert.Equal(t, "https://example.com/app", eff.Properties.Entries["site"])
+	var repositories []string
+	for _, r := range *eff.Repositories {
+		repositories = append(repositories, r.ID)
+	}
+	assert.Equal(t, []string{"central", "snapshots"}, repositories)
+
+	// The settings are not modified.
+	assert.Equal(t, "21", (*s.Profiles)[0].Properties.Entries["java.version"])
+	assert.Len(t, *(*s.Profiles)[0].Repositories, 1)
+
+	// Without settings, the POM is used as is.
+	eff, err = Effective(p, EffectiveOptions{Dir: "testdata/settings"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "17", eff.Properties.Entries["java.version"])
+	assert.Equal(t, "true", eff.Properties.Entries["default"])
+}
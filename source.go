@@ -0,0 +1,164 @@
+package gopom
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Position is a location in the source a project was parsed from.
+type Position struct {
+	// Offset is the byte offset, starting at 0.
+	Offset int
+	// Line and Column start at 1. Columns count bytes.
+	Line   int
+	Column int
+}
+
+func (p Position) String() string {
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
+}
+
+// IsValid reports whether the position is known.
+func (p Position) IsValid() bool {
+	return p.Line > 0
+}
+
+// span is the location of an element in the source: Start and End surround
+// the whole element, and ContentStart and ContentEnd its content.
+type span struct {
+	Start, ContentStart, ContentEnd, End int
+}
+
+// sourceIndex locates the elements of a parsed project in its source, by
+// element path.
+type sourceIndex struct {
+	Elements map[string]span
+	// Comments holds the text of the comments preceding or trailing each
+	// element, by path.
+	Comments map[string][]string
+	// Lines holds the offset of the start of each line.
+	Lines []int
+	// Text is the source itself.
+	Text []byte
+}
+
+// position converts offset to a Position.
+func (s *sourceIndex) position(offset int) Position {
+	line := sort.Search(len(s.Lines), func(i int) bool { return s.Lines[i] > offset })
+	return Position{Offset: offset, Line: line, Column: offset - s.Lines[line-1] + 1}
+}
+
+// Position returns where the element at path, in the form used by
+// Result.Path, starts in the source p was parsed from. It returns false for
+// projects that were not parsed, or built with Decode, and for elements that
+// were not in the source.
+func (p *Project) Position(path string) (Position, bool) {
+	if p.source == nil {
+		return Position{}, false
+	}
+	s, ok := p.source.Elements[path]
+	if !ok {
+		return Position{}, false
+	}
+	return p.source.position(s.Start), true
+}
+
+// scan walks the XML in b, attaching the comments it finds to the matching
+// elements of p and recording where each element is.
+func scan(b []byte, p *Project) error {
+	index, err := scanSource(b, commentTargets(p))
+	if err != nil {
+		return err
+	}
+	p.source = index
+	return nil
+}
+
+// scanSource walks the XML in b, attaching the comments it finds to the
+// matching targets, and indexes the elements.
+func scanSource(b []byte, targets map[string]commentable) (*sourceIndex, error) {
+	index := &sourceIndex{Elements: map[string]span{}, Comments: map[string][]string{}, Lines: []int{0}, Text: b}
+	for i, c := range b {
+		if c == '\n' {
+			index.Lines = append(index.Lines, i+1)
+		}
+	}
+
+	type frame struct {
+		path   string
+		start  int
+		inner  int
+		counts map[string]int
+	}
+	var (
+		stack   []frame
+		pending []string
+		// last is the path of the most recently closed sibling, if any.
+		last     string
+		sameLine bool
+	)
+	attach := func(path string, leading bool, texts ...string) {
+		if len(texts) > 0 {
+			index.Comments[path] = append(index.Comments[path], texts...)
+		}
+		t, ok := targets[path]
+		if !ok || len(texts) == 0 {
+			return
+		}
+		c := t.Comments()
+		if leading {
+			c.Leading = append(c.Leading, texts...)
+		} else {
+			c.Trailing = append(c.Trailing, texts...)
+		}
+	}
+
+	d := xml.NewDecoder(bytes.NewReader(b))
+	for {
+		offset := int(d.InputOffset())
+		tok, err := d.Token()
+		if err == io.EOF {
+			return index, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			path := t.Name.Local
+			if n := len(stack); n > 0 {
+				parent := stack[n-1]
+				path = elementPath(parent.path, t.Name.Local, parent.counts[t.Name.Local])
+				parent.counts[t.Name.Local]++
+			}
+			attach(path, true, pending...)
+			pending, last = nil, ""
+			stack = append(stack, frame{path: path, start: offset, inner: int(d.InputOffset()), counts: map[string]int{}})
+		case xml.EndElement:
+			if last != "" {
+				attach(last, false, pending...)
+			}
+			pending = nil
+			f := stack[len(stack)-1]
+			index.Elements[f.path] = span{Start: f.start, ContentStart: f.inner, ContentEnd: offset, End: int(d.InputOffset())}
+			last = f.path
+			stack = stack[:len(stack)-1]
+			sameLine = true
+		case xml.CharData:
+			if bytes.ContainsRune(t, '\n') {
+				sameLine = false
+			}
+		case xml.Comment:
+			text := strings.TrimSpace(string(t))
+			if last != "" && sameLine {
+				attach(last, false, text)
+			} else {
+				pending = append(pending, text)
+			}
+		}
+	}
+}
@@ -0,0 +1,315 @@
+package gopom
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// spdxDocument is an SPDX 2.3 document, with the fields gopom writes.
+type spdxDocument struct {
+	SPDXVersion          string                 `json:"spdxVersion"`
+	DataLicense          string                 `json:"dataLicense"`
+	SPDXID               string                 `json:"SPDXID"`
+	Name                 string                 `json:"name"`
+	DocumentNamespace    string                 `json:"documentNamespace"`
+	CreationInfo         spdxCreationInfo       `json:"creationInfo"`
+	Packages             []spdxPackage          `json:"packages"`
+	Relationships        []spdxRelationship     `json:"relationships"`
+	ExtractedLicenseInfo []spdxExtractedLicense `json:"hasExtractedLicensingInfos,omitempty"`
+}
+
+type spdxCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+type spdxPackage struct {
+	Name             string            `json:"name"`
+	SPDXID           string            `json:"SPDXID"`
+	VersionInfo      string            `json:"versionInfo,omitempty"`
+	Supplier         string            `json:"supplier,omitempty"`
+	Originator       string            `json:"originator,omitempty"`
+	DownloadLocation string            `json:"downloadLocation"`
+	FilesAnalyzed    bool              `json:"filesAnalyzed"`
+	Checksums        []spdxChecksum    `json:"checksums,omitempty"`
+	LicenseConcluded string            `json:"licenseConcluded"`
+	LicenseDeclared  string            `json:"licenseDeclared"`
+	CopyrightText    string            `json:"copyrightText"`
+	ExternalRefs     []spdxExternalRef `json:"externalRefs"`
+}
+
+type spdxChecksum struct {
+	Algorithm string `json:"algorithm"`
+	Value     string `json:"checksumValue"`
+}
+
+type spdxExternalRef struct {
+	Category string `json:"referenceCategory"`
+	Type     string `json:"referenceType"`
+	Locator  string `json:"referenceLocator"`
+}
+
+type spdxRelationship struct {
+	Element string `json:"spdxElementId"`
+	Type    string `json:"relationshipType"`
+	Related string `json:"relatedSpdxElement"`
+	Comment string `json:"comment,omitempty"`
+}
+
+type spdxExtractedLicense struct {
+	LicenseID     string   `json:"licenseId"`
+	Name          string   `json:"name"`
+	ExtractedText string   `json:"extractedText"`
+	SeeAlsos      []string `json:"seeAlsos,omitempty"`
+}
+
+// SPDXJSON returns the SPDX 2.3 bill of materials of p, in JSON. It has the
+// same packages as CycloneDXJSON, and a DEPENDS_ON relationship for every
+// dependency, commented with its Maven scope and whether it is optional,
+// like "scope: test" or "scope: compile, optional".
+//
+// Licenses are mapped to SPDX identifiers when recognized, and to
+// LicenseRef identifiers described in the document otherwise. The project
+// organization is recorded as the supplier of the project package and its
+// first developer as the originator.
+//
+// SPDX documents must have a creation time: when opts.Timestamp is zero,
+// the Unix epoch is used so the output only depends on its input. The
+// document namespace is derived from opts.SerialNumber, or from the
+// content of the document.
+func SPDXJSON(p *Project, opts SBOMOptions) ([]byte, error) {
+	b, err := json.MarshalIndent(newSPDX(p, opts), "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal: %w", err)
+	}
+	return append(b, '\n'), nil
+}
+
+// SPDXTagValue returns the SPDX 2.3 bill of materials of p in the
+// tag-value format, see SPDXJSON.
+func SPDXTagValue(p *Project, opts SBOMOptions) ([]byte, error) {
+	doc := newSPDX(p, opts)
+	var b bytes.Buffer
+	tag := func(name, value string) {
+		if value != "" {
+			fmt.Fprintf(&b, "%s: %s\n", name, value)
+		}
+	}
+	text := func(name, value string) {
+		fmt.Fprintf(&b, "%s: <text>%s</text>\n", name, value)
+	}
+
+	tag("SPDXVersion", doc.SPDXVersion)
+	tag("DataLicense", doc.DataLicense)
+	tag("SPDXID", doc.SPDXID)
+	tag("DocumentName", doc.Name)
+	tag("DocumentNamespace", doc.DocumentNamespace)
+	for _, c := range doc.CreationInfo.Creators {
+		tag("Creator", c)
+	}
+	tag("Created", doc.CreationInfo.Created)
+
+	for _, pkg := range doc.Packages {
+		b.WriteString("\n")
+		tag("PackageName", pkg.Name)
+		tag("SPDXID", pkg.SPDXID)
+		tag("PackageVersion", pkg.VersionInfo)
+		tag("PackageSupplier", pkg.Supplier)
+		tag("PackageOriginator", pkg.Originator)
+		tag("PackageDownloadLocation", pkg.DownloadLocation)
+		tag("FilesAnalyzed", fmt.Sprint(pkg.FilesAnalyzed))
+		for _, c := range pkg.Checksums {
+			tag("PackageChecksum", c.Algorithm+": "+c.Value)
+		}
+		tag("PackageLicenseConcluded", pkg.LicenseConcluded)
+		tag("PackageLicenseDeclared", pkg.LicenseDeclared)
+		tag("PackageCopyrightText", pkg.CopyrightText)
+		for _, r := range pkg.ExternalRefs {
+			tag("ExternalRef", r.Category+" "+r.Type+" "+r.Locator)
+		}
+	}
+
+	b.WriteString("\n")
+	for _, r := range doc.Relationships {
+		tag("Relationship", r.Element+" "+r.Type+" "+r.Related)
+		if r.Comment != "" {
+			text("RelationshipComment", r.Comment)
+		}
+	}
+
+	for _, l := range doc.ExtractedLicenseInfo {
+		b.WriteString("\n")
+		tag("LicenseID", l.LicenseID)
+		text("ExtractedText", l.ExtractedText)
+		tag("LicenseName", l.Name)
+		for _, u := range l.SeeAlsos {
+			tag("LicenseCrossReference", u)
+		}
+	}
+	return b.Bytes(), nil
+}
+
+func newSPDX(p *Project, opts SBOMOptions) *spdxDocument {
+	s := newSBOM(p, opts)
+	name := s.root.GroupID + ":" + s.root.ArtifactID
+	if s.root.Version != "" {
+		name += ":" + s.root.Version
+	}
+	created := opts.Timestamp
+	if created.IsZero() {
+		created = time.Unix(0, 0)
+	}
+	doc := &spdxDocument{
+		SPDXVersion: "SPDX-2.3",
+		DataLicense: "CC0-1.0",
+		SPDXID:      "SPDXRef-DOCUMENT",
+		Name:        name,
+		CreationInfo: spdxCreationInfo{
+			Created:  created.UTC().Format(time.RFC3339),
+			Creators: []string{"Tool: gopom"},
+		},
+		Relationships: []spdxRelationship{},
+	}
+
+	// ids maps package refs to their SPDX identifiers, and taken holds the
+	// identifiers in use.
+	ids, taken := map[string]string{}, map[string]bool{}
+	extracted := map[string]spdxExtractedLicense{}
+	addPackage := func(pkg sbomPackage) spdxPackage {
+		id := spdxID(pkg.ref)
+		for i := 2; taken[id]; i++ {
+			id = fmt.Sprintf("%s-%d", spdxID(pkg.ref), i)
+		}
+		taken[id] = true
+		ids[pkg.ref] = id
+
+		sp := spdxPackage{
+			Name:             pkg.ArtifactID,
+			SPDXID:           id,
+			VersionInfo:      pkg.Version,
+			DownloadLocation: "NOASSERTION",
+			LicenseConcluded: "NOASSERTION",
+			LicenseDeclared:  spdxLicenseExpression(pkg.licenses, extracted),
+			CopyrightText:    "NOASSERTION",
+			ExternalRefs:     []spdxExternalRef{{Category: "PACKAGE-MANAGER", Type: "purl", Locator: pkg.ref}},
+		}
+		for _, h := range pkg.hashes {
+			sp.Checksums = append(sp.Checksums, spdxChecksum{Algorithm: strings.ReplaceAll(h.Algorithm, "-", ""), Value: h.Value})
+		}
+		return sp
+	}
+
+	root := addPackage(s.root)
+	if p.Organization != nil && p.Organization.Name != "" {
+		root.Supplier = "Organization: " + p.Organization.Name
+	}
+	if p.Developers != nil && len(*p.Developers) > 0 {
+		root.Originator = spdxPerson((*p.Developers)[0])
+	}
+	doc.Packages = append(doc.Packages, root)
+	for _, pkg := range s.packages {
+		doc.Packages = append(doc.Packages, addPackage(pkg))
+	}
+
+	doc.Relationships = append(doc.Relationships, spdxRelationship{Element: doc.SPDXID, Type: "DESCRIBES", Related: root.SPDXID})
+	for _, e := range s.edges {
+		doc.Relationships = append(doc.Relationships, spdxDependency(ids[e.from], ids[e.to], e.scope, e.optional))
+	}
+
+	for _, l := range extracted {
+		doc.ExtractedLicenseInfo = append(doc.ExtractedLicenseInfo, l)
+	}
+	sort.Slice(doc.ExtractedLicenseInfo, func(i, j int) bool {
+		return doc.ExtractedLicenseInfo[i].LicenseID < doc.ExtractedLicenseInfo[j].LicenseID
+	})
+
+	doc.DocumentNamespace = spdxNamespace(doc, opts.SerialNumber)
+	return doc
+}
+
+// spdxDependency returns the DEPENDS_ON relationship of the package from
+// to its dependency to, commented with the Maven scope and whether the
+// dependency is optional.
+func spdxDependency(from, to, scope string, optional bool) spdxRelationship {
+	if scope == "" {
+		scope = "compile"
+	}
+	comment := "scope: " + scope
+	if optional {
+		comment += ", optional"
+	}
+	return spdxRelationship{Element: from, Type: "DEPENDS_ON", Related: to, Comment: comment}
+}
+
+// spdxNamespace returns the namespace of doc: the serial number, or a
+// digest of the document when there is none.
+func spdxNamespace(doc *spdxDocument, serialNumber string) string {
+	id := strings.TrimPrefix(serialNumber, "urn:uuid:")
+	if id == "" {
+		b, _ := json.Marshal(doc)
+		sum := sha256.Sum256(b)
+		id = hex.EncodeToString(sum[:16])
+	}
+	return "https://spdx.org/spdxdocs/" + spdxIDChars(doc.Name) + "-" + id
+}
+
+// spdxID returns the SPDX identifier of the package with the given ref.
+func spdxID(ref string) string {
+	return "SPDXRef-Package-" + spdxIDChars(strings.TrimPrefix(ref, "pkg:"))
+}
+
+// spdxIDChars replaces the characters SPDX identifiers cannot hold by
+// dashes.
+func spdxIDChars(s string) string {
+	return strings.Map(func(r rune) rune {
+		if 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9' || r == '.' || r == '-' {
+			return r
+		}
+		return '-'
+	}, s)
+}
+
+func spdxPerson(d Developer) string {
+	name := d.Name
+	if name == "" {
+		name = d.ID
+	}
+	if name == "" {
+		return ""
+	}
+	if d.Email == "" {
+		return "Person: " + name
+	}
+	return "Person: " + name + " (" + d.Email + ")"
+}
+
+// spdxLicenseExpression returns the SPDX expression declaring licenses.
+// Unrecognized licenses are added to extracted.
+func spdxLicenseExpression(licenses []License, extracted map[string]spdxExtractedLicense) string {
+	l := NormalizeLicenses(licenses)
+	if l.Expression == "" {
+		return "NOASSERTION"
+	}
+	for _, u := range l.Unrecognized() {
+		id := licenseRef(u)
+		name := u.Name
+		if name == "" {
+			name = u.URL
+		}
+		e := spdxExtractedLicense{LicenseID: id, Name: name, ExtractedText: name}
+		if u.URL != "" {
+			e.SeeAlsos = []string{u.URL}
+		}
+		extracted[id] = e
+	}
+	if strings.Contains(l.Expression, " ") {
+		return "(" + l.Expression + ")"
+	}
+	return l.Expression
+}
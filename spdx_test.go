@@ -0,0 +1,107 @@
+package gopom
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSPDX(t *testing.T) {
+	p, err := Parse(sbomFilename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := SBOMOptions{
+		Graph:        sbomGraph(t, p),
+		SerialNumber: "urn:uuid:3e671687-395b-41f5-a30f-a58921a69b79",
+		Timestamp:    time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+	}
+	b, err := SPDXJSON(p, opts)
+	if err != nil {
+		t.Fatalf("failed to generate the SBOM: %v", err)
+	}
+	want, err := os.ReadFile("testdata/sbom/bom.spdx.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, string(want), string(b))
+	checkSchemaFile(t, "testdata/spdx-2.3.schema.json", b)
+
+	b, err = SPDXTagValue(p, opts)
+	if err != nil {
+		t.Fatalf("failed to generate the SBOM: %v", err)
+	}
+	want, err = os.ReadFile("testdata/sbom/bom.spdx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, string(want), string(b))
+}
+
+func TestSPDXDeclared(t *testing.T) {
+	p, err := Parse(sbomFilename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	(*p.Licenses) = append(*p.Licenses, License{Name: "Example Commercial License", URL: "https://example.com/license"})
+	b, err := SPDXJSON(p, SBOMOptions{})
+	if err != nil {
+		t.Fatalf("failed to generate the SBOM: %v", err)
+	}
+	checkSchemaFile(t, "testdata/spdx-2.3.schema.json", b)
+
+	var doc spdxDocument
+	if err := json.Unmarshal(b, &doc); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "1970-01-01T00:00:00Z", doc.CreationInfo.Created)
+	assert.True(t, strings.HasPrefix(doc.DocumentNamespace, "https://spdx.org/spdxdocs/com.example-service-2.0.0-"))
+	root := doc.Packages[0]
+	assert.Equal(t, "(Apache-2.0 OR LicenseRef-Example-Commercial-License)", root.LicenseDeclared)
+	assert.Equal(t, "Organization: Example Inc.", root.Supplier)
+	assert.Equal(t, "Person: Jane Doe (jdoe@example.com)", root.Originator)
+	assert.Equal(t, []spdxExtractedLicense{{
+		LicenseID:     "LicenseRef-Example-Commercial-License",
+		Name:          "Example Commercial License",
+		ExtractedText: "Example Commercial License",
+		SeeAlsos:      []string{"https://example.com/license"},
+	}}, doc.ExtractedLicenseInfo)
+
+	var relationships []string
+	for _, r := range doc.Relationships {
+		relationships = append(relationships, r.Element+" "+r.Type+" "+r.Related+" "+r.Comment)
+	}
+	assert.Equal(t, []string{
+		"SPDXRef-DOCUMENT DESCRIBES SPDXRef-Package-maven-com.example-service-2.0.0 ",
+		"SPDXRef-Package-maven-com.example-service-2.0.0 DEPENDS_ON SPDXRef-Package-maven-com.google.guava-guava-33.0.0-jre scope: compile",
+		"SPDXRef-Package-maven-com.example-service-2.0.0 DEPENDS_ON SPDXRef-Package-maven-io.netty-netty-transport-native-epoll-4.1.100.Final-classifier-linux-x86-64 scope: compile, optional",
+		"SPDXRef-Package-maven-com.example-service-2.0.0 DEPENDS_ON SPDXRef-Package-maven-org.junit.jupiter-junit-jupiter-5.10.0 scope: test",
+	}, relationships)
+
+	again, err := SPDXJSON(p, SBOMOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, string(b), string(again))
+}
+
+func TestSPDXDependency(t *testing.T) {
+	for _, c := range []struct {
+		scope    string
+		optional bool
+		want     spdxRelationship
+	}{
+		{"", false, spdxRelationship{Element: "app", Type: "DEPENDS_ON", Related: "lib", Comment: "scope: compile"}},
+		{"compile", true, spdxRelationship{Element: "app", Type: "DEPENDS_ON", Related: "lib", Comment: "scope: compile, optional"}},
+		{"runtime", true, spdxRelationship{Element: "app", Type: "DEPENDS_ON", Related: "lib", Comment: "scope: runtime, optional"}},
+		{"provided", false, spdxRelationship{Element: "app", Type: "DEPENDS_ON", Related: "lib", Comment: "scope: provided"}},
+		{"test", true, spdxRelationship{Element: "app", Type: "DEPENDS_ON", Related: "lib", Comment: "scope: test, optional"}},
+		{"system", false, spdxRelationship{Element: "app", Type: "DEPENDS_ON", Related: "lib", Comment: "scope: system"}},
+	} {
+		assert.Equal(t, c.want, spdxDependency("app", "lib", c.scope, c.optional), c.scope)
+	}
+}
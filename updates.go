@@ -0,0 +1,358 @@
+package gopom
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// UpdateOptions configures Updates.
+type UpdateOptions struct {
+	// Repository is the local Maven repository the metadata is read from.
+	// It defaults to ~/.m2/repository.
+	Repository string
+	// Metadata returns the artifact metadata of groupID:artifactID, with
+	// the versions available. It defaults to reading it from Repository
+	// with LoadMetadata, and can be set to fetch it from remote
+	// repositories. Errors wrapping fs.ErrNotExist skip the artifact.
+	Metadata func(groupID, artifactID string) (*Metadata, error)
+	// PreReleases includes alpha, beta, milestone, release candidate and
+	// SNAPSHOT versions, see IsPreRelease.
+	PreReleases bool
+	// Rules ignores versions, see ParseUpdateRules.
+	Rules *UpdateRules
+}
+
+// UpdateSegment tells which part of a version an update changes.
+type UpdateSegment string
+
+const (
+	UpdatePatch UpdateSegment = "patch"
+	UpdateMinor UpdateSegment = "minor"
+	UpdateMajor UpdateSegment = "major"
+)
+
+// Update lists the newer versions available for a dependency, a plugin, an
+// extension or the parent of a project.
+type Update struct {
+	// Kind is dependency, plugin, extension or parent.
+	Kind string
+	// Path is the element path of the declaration, in the form used by
+	// Problem.Path.
+	Path       string
+	GroupID    string
+	ArtifactID string
+	// Version is the current version, with the properties of the project
+	// replaced.
+	Version string
+	// Patch is the newest version with the same major and minor versions,
+	// Minor the newest one with the same major version, and Major the
+	// newest one overall. They are empty when there is no such update.
+	Patch string
+	Minor string
+	Major string
+}
+
+// Latest returns the newest version available.
+func (u Update) Latest() string {
+	switch {
+	case u.Major != "":
+		return u.Major
+	case u.Minor != "":
+		return u.Minor
+	}
+	return u.Patch
+}
+
+// Segment returns the part of the version the newest update changes.
+func (u Update) Segment() UpdateSegment {
+	switch {
+	case u.Major != "":
+		return UpdateMajor
+	case u.Minor != "":
+		return UpdateMinor
+	}
+	return UpdatePatch
+}
+
+func (u Update) String() string {
+	return fmt.Sprintf("%s %s:%s %s -> %s (%s)", u.Kind, u.GroupID, u.ArtifactID, u.Version, u.Latest(), u.Segment())
+}
+
+// Updates reports the newer versions available for the dependencies,
+// managed dependencies, plugins, managed plugins and extensions of p and
+// its profiles, and for its parent, like `mvn versions:display-dependency-updates`
+// and its plugin and parent counterparts. Versions are ordered like Maven
+// does, see CompareVersions.
+//
+// Updates looks at the POM as written: versions referencing properties of
+// the project are expanded, and the ones that cannot be, version ranges and
+// elements without version are skipped. Elements that are up to date are
+// not reported.
+func (p *Project) Updates(opts UpdateOptions) ([]Update, error) {
+	if opts.Metadata == nil {
+		repository := opts.Repository
+		if repository == "" {
+			var err error
+			if repository, err = LocalRepository(); err != nil {
+				return nil, err
+			}
+		}
+		opts.Metadata = func(groupID, artifactID string) (*Metadata, error) {
+			return LoadMetadata(repository, groupID, artifactID, "")
+		}
+	}
+
+	var updates []Update
+	var err error
+	check := func(kind, path, groupID, artifactID, version string) {
+		version = lintExpand(p, version)
+		if err != nil || version == "" || strings.Contains(version, "${") || strings.ContainsAny(version, "[(") {
+			return
+		}
+		m, e := opts.Metadata(groupID, artifactID)
+		if errors.Is(e, fs.ErrNotExist) {
+			return
+		}
+		if e != nil {
+			err = fmt.Errorf("failed to read the metadata of %s:%s: %w", groupID, artifactID, e)
+			return
+		}
+		u := Update{Kind: kind, Path: path, GroupID: groupID, ArtifactID: artifactID, Version: version}
+		for _, v := range metadataVersions(m) {
+			if CompareVersions(v, version) <= 0 || (!opts.PreReleases && IsPreRelease(v)) || opts.Rules.Ignored(groupID, artifactID, v) {
+				continue
+			}
+			newest := &u.Patch
+			switch {
+			case versionSegment(v, 0).Cmp(versionSegment(version, 0)) != 0:
+				newest = &u.Major
+			case versionSegment(v, 1).Cmp(versionSegment(version, 1)) != 0:
+				newest = &u.Minor
+			}
+			if *newest == "" || CompareVersions(v, *newest) > 0 {
+				*newest = v
+			}
+		}
+		if u.Latest() != "" {
+			updates = append(updates, u)
+		}
+	}
+
+	if p.Parent != nil {
+		check("parent", "project/parent[0]", p.Parent.GroupID, p.Parent.ArtifactID, p.Parent.Version)
+	}
+	forEachDependencyList(p, func(path string, deps []Dependency) {
+		for i, d := range deps {
+			check("dependency", elementPath(path, "dependency", i), d.GroupID, d.ArtifactID, d.Version)
+		}
+	})
+	for _, s := range lintSections(p) {
+		paths, lists := s.pluginLists()
+		for i, plugins := range lists {
+			for j, pl := range plugins {
+				groupID := pl.GroupID
+				if groupID == "" {
+					groupID = "org.apache.maven.plugins"
+				}
+				check("plugin", elementPath(paths[i], "plugin", j), groupID, pl.ArtifactID, pl.Version)
+			}
+		}
+	}
+	if p.Build != nil && p.Build.Extensions != nil {
+		for i, x := range *p.Build.Extensions {
+			check("extension", elementPath("project/build[0]/extensions[0]", "extension", i), x.GroupID, x.ArtifactID, x.Version)
+		}
+	}
+	return updates, err
+}
+
+// metadataVersions returns the versions listed in m.
+func metadataVersions(m *Metadata) []string {
+	if m == nil || m.Versioning == nil {
+		return nil
+	}
+	return derefSlice(m.Versioning.Versions)
+}
+
+// UpdateRules are the versions Updates ignores, in the rules.xml format of
+// the versions-maven-plugin. See
+// https://www.mojohaus.org/versions/versions-maven-plugin/version-rules.html.
+type UpdateRules struct {
+	XMLName xml.Name `xml:"ruleset"`
+	// IgnoreVersions apply to every artifact.
+	IgnoreVersions []IgnoreVersion `xml:"ignoreVersions>ignoreVersion"`
+	Rules          []UpdateRule    `xml:"rules>rule"`
+}
+
+// UpdateRule ignores versions of the artifacts it matches. Its groupId and
+// artifactId may contain * and ? wildcards, and an empty artifactId matches
+// every artifact of the group.
+type UpdateRule struct {
+	GroupID        string          `xml:"groupId,attr"`
+	ArtifactID     string          `xml:"artifactId,attr"`
+	IgnoreVersions []IgnoreVersion `xml:"ignoreVersions>ignoreVersion"`
+}
+
+// IgnoreVersion is a version to ignore. Its type is exact, the default,
+// regex, for a regular expression matching the whole version, or range,
+// for a Maven version range like [2.0,3.0).
+type IgnoreVersion struct {
+	Type    string `xml:"type,attr,omitempty"`
+	Version string `xml:",chardata"`
+
+	pattern *regexp.Regexp
+	ranges  []versionInterval
+}
+
+// ParseUpdateRules parses a rules.xml file of the versions-maven-plugin.
+func ParseUpdateRules(r io.Reader) (*UpdateRules, error) {
+	var rules UpdateRules
+	if err := xml.NewDecoder(r).Decode(&rules); err != nil {
+		return nil, fmt.Errorf("failed to parse update rules: %w", err)
+	}
+	if err := compileIgnoreVersions(rules.IgnoreVersions); err != nil {
+		return nil, err
+	}
+	for _, rule := range rules.Rules {
+		if err := compileIgnoreVersions(rule.IgnoreVersions); err != nil {
+			return nil, err
+		}
+	}
+	return &rules, nil
+}
+
+func compileIgnoreVersions(ignored []IgnoreVersion) error {
+	for i := range ignored {
+		v := &ignored[i]
+		v.Version = strings.TrimSpace(v.Version)
+		var err error
+		switch v.Type {
+		case "", "exact":
+		case "regex":
+			v.pattern, err = regexp.Compile("^(?:" + v.Version + ")$")
+		case "range":
+			v.ranges, err = parseVersionRange(v.Version)
+		default:
+			err = fmt.Errorf("unknown type %q", v.Type)
+		}
+		if err != nil {
+			return fmt.Errorf("invalid ignored version %q: %w", v.Version, err)
+		}
+	}
+	return nil
+}
+
+// Ignored tells whether the rules ignore the given version of
+// groupID:artifactID. The versions ignored by every rule matching the
+// artifact are, whatever their order.
+func (r *UpdateRules) Ignored(groupID, artifactID, version string) bool {
+	if r == nil {
+		return false
+	}
+	if ignoresVersion(r.IgnoreVersions, version) {
+		return true
+	}
+	for _, rule := range r.Rules {
+		if matchWildcard(rule.GroupID, groupID) && (rule.ArtifactID == "" || matchWildcard(rule.ArtifactID, artifactID)) &&
+			ignoresVersion(rule.IgnoreVersions, version) {
+			return true
+		}
+	}
+	return false
+}
+
+func ignoresVersion(ignored []IgnoreVersion, version string) bool {
+	for _, v := range ignored {
+		switch {
+		case v.pattern != nil:
+			if v.pattern.MatchString(version) {
+				return true
+			}
+		case v.ranges != nil:
+			for _, r := range v.ranges {
+				if r.contains(version) {
+					return true
+				}
+			}
+		case v.Version == version:
+			return true
+		}
+	}
+	return false
+}
+
+// matchWildcard matches s against pattern, where * matches any characters
+// and ? a single one.
+func matchWildcard(pattern, s string) bool {
+	ok, err := path.Match(pattern, s)
+	return err == nil && ok
+}
+
+// versionInterval is an interval of a Maven version range. Empty bounds
+// are unbounded.
+type versionInterval struct {
+	lower, upper                   string
+	lowerInclusive, upperInclusive bool
+}
+
+func (r versionInterval) contains(version string) bool {
+	if r.lower != "" {
+		c := CompareVersions(version, r.lower)
+		if c < 0 || (c == 0 && !r.lowerInclusive) {
+			return false
+		}
+	}
+	if r.upper != "" {
+		c := CompareVersions(version, r.upper)
+		if c > 0 || (c == 0 && !r.upperInclusive) {
+			return false
+		}
+	}
+	return true
+}
+
+// parseVersionRange parses a Maven version range, a comma-separated list of
+// intervals like [1.0,2.0), (,1.0] or [1.5].
+func parseVersionRange(s string) ([]versionInterval, error) {
+	var ranges []versionInterval
+	s = strings.TrimSpace(s)
+	for s != "" {
+		if s[0] != '[' && s[0] != '(' {
+			return nil, errors.New("version ranges start with [ or (")
+		}
+		end := strings.IndexAny(s, "])")
+		if end < 0 {
+			return nil, errors.New("unterminated version range")
+		}
+		r := versionInterval{lowerInclusive: s[0] == '[', upperInclusive: s[end] == ']'}
+		bounds := strings.Split(s[1:end], ",")
+		switch len(bounds) {
+		case 1:
+			if !r.lowerInclusive || !r.upperInclusive || strings.TrimSpace(bounds[0]) == "" {
+				return nil, errors.New("single versions are written [version]")
+			}
+			r.lower = strings.TrimSpace(bounds[0])
+			r.upper = r.lower
+		case 2:
+			r.lower, r.upper = strings.TrimSpace(bounds[0]), strings.TrimSpace(bounds[1])
+			if r.lower != "" && r.upper != "" && CompareVersions(r.lower, r.upper) > 0 {
+				return nil, errors.New("the lower bound is greater than the upper one")
+			}
+		default:
+			return nil, errors.New("version ranges have at most two bounds")
+		}
+		ranges = append(ranges, r)
+		s = strings.TrimPrefix(strings.TrimSpace(s[end+1:]), ",")
+		s = strings.TrimSpace(s)
+	}
+	if ranges == nil {
+		return nil, errors.New("empty version range")
+	}
+	return ranges, nil
+}
@@ -0,0 +1,170 @@
+package gopom
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdates(t *testing.T) {
+	p, err := Parse("testdata/updates/pom.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	updates, err := p.Updates(UpdateOptions{Repository: "testdata/updates/repository"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []Update{
+		{Kind: "parent", Path: "project/parent[0]", GroupID: "com.example", ArtifactID: "parent", Version: "5", Major: "6"},
+		{Kind: "dependency", Path: "project/dependencies[0]/dependency[0]", GroupID: "com.example", ArtifactID: "core", Version: "1.2.0", Patch: "1.2.2", Minor: "1.3.0", Major: "3.0.0"},
+		{Kind: "dependency", Path: "project/dependencyManagement[0]/dependencies[0]/dependency[0]", GroupID: "com.example", ArtifactID: "managed", Version: "1.0", Patch: "1.0.1"},
+		{Kind: "plugin", Path: "project/build[0]/plugins[0]/plugin[0]", GroupID: "org.apache.maven.plugins", ArtifactID: "maven-compiler-plugin", Version: "3.11.0", Minor: "3.13.0"},
+		{Kind: "extension", Path: "project/build[0]/extensions[0]/extension[0]", GroupID: "kr.motd.maven", ArtifactID: "os-maven-plugin", Version: "1.7.0", Patch: "1.7.1"},
+	}, updates)
+
+	assert.Equal(t, "3.0.0", updates[1].Latest())
+	assert.Equal(t, UpdateMajor, updates[1].Segment())
+	assert.Equal(t, UpdateMinor, updates[3].Segment())
+	assert.Equal(t, UpdatePatch, updates[4].Segment())
+	assert.Equal(t, "dependency com.example:core 1.2.0 -> 3.0.0 (major)", updates[1].String())
+	pos, ok := p.Position(updates[1].Path)
+	assert.True(t, ok)
+	assert.Equal(t, 24, pos.Line)
+}
+
+func TestUpdatesPreReleases(t *testing.T) {
+	p, err := Parse("testdata/updates/pom.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	updates, err := p.Updates(UpdateOptions{Repository: "testdata/updates/repository", PreReleases: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	latest := map[string]string{}
+	for _, u := range updates {
+		latest[u.ArtifactID] = u.Patch + " " + u.Minor + " " + u.Major
+	}
+	assert.Equal(t, "1.2.2 1.4.0-RC1 3.0.0", latest["core"])
+	assert.Equal(t, "1.0.1 1.1-SNAPSHOT ", latest["managed"])
+	assert.Equal(t, " 3.13.0 4.0.0-beta-1", latest["maven-compiler-plugin"])
+}
+
+func TestUpdatesRules(t *testing.T) {
+	p, err := Parse("testdata/updates/pom.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rules := parseUpdateRulesFile(t, "testdata/updates/rules.xml")
+	updates, err := p.Updates(UpdateOptions{Repository: "testdata/updates/repository", PreReleases: true, Rules: rules})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "core", updates[1].ArtifactID)
+	assert.Equal(t, "1.2.1", updates[1].Patch)
+	assert.Equal(t, "1.4.0-RC1", updates[1].Minor)
+	assert.Equal(t, "2.0.0", updates[1].Major)
+	assert.Equal(t, "maven-compiler-plugin", updates[3].ArtifactID)
+	assert.Equal(t, "", updates[3].Major)
+}
+
+func TestUpdatesMetadata(t *testing.T) {
+	p, err := Parse("testdata/updates/pom.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var requested []string
+	updates, err := p.Updates(UpdateOptions{Metadata: func(groupID, artifactID string) (*Metadata, error) {
+		requested = append(requested, groupID+":"+artifactID)
+		return &Metadata{Versioning: &Versioning{Versions: &[]string{"1.0", "99"}}}, nil
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, updates, 8)
+	assert.Equal(t, []string{
+		"com.example:parent", "com.example:core", "com.example:util", "com.example:missing", "com.example:managed",
+		"org.apache.maven.plugins:maven-compiler-plugin", "org.apache.maven.plugins:maven-surefire-plugin", "kr.motd.maven:os-maven-plugin",
+	}, requested)
+
+	failure := errors.New("unreachable")
+	_, err = p.Updates(UpdateOptions{Metadata: func(groupID, artifactID string) (*Metadata, error) {
+		return nil, failure
+	}})
+	assert.True(t, errors.Is(err, failure))
+	assert.Contains(t, err.Error(), "com.example:parent")
+}
+
+func TestParseUpdateRules(t *testing.T) {
+	rules := parseUpdateRulesFile(t, "testdata/updates/rules.xml")
+	assert.True(t, rules.Ignored("org.example", "any", "2.0-beta-1"))
+	assert.True(t, rules.Ignored("com.example", "core", "3.0"))
+	assert.True(t, rules.Ignored("com.example", "core", "3"))
+	assert.False(t, rules.Ignored("com.example", "core", "2.9"))
+	assert.False(t, rules.Ignored("com.example", "util", "3.0"))
+	assert.True(t, rules.Ignored("com.example", "util", "1.2.2"))
+	assert.False(t, rules.Ignored("org.example", "util", "1.2.2"))
+	assert.False(t, (*UpdateRules)(nil).Ignored("com.example", "core", "3.0"))
+
+	for _, invalid := range []string{
+		`<ruleset><ignoreVersions><ignoreVersion type="regex">(</ignoreVersion></ignoreVersions></ruleset>`,
+		`<ruleset><ignoreVersions><ignoreVersion type="glob">1.*</ignoreVersion></ignoreVersions></ruleset>`,
+		`<ruleset><rules><rule groupId="g"><ignoreVersions><ignoreVersion type="range">[2.0,1.0]</ignoreVersion></ignoreVersions></rule></rules></ruleset>`,
+		`<ruleset><ignoreVersions>`,
+	} {
+		_, err := ParseUpdateRules(strings.NewReader(invalid))
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestVersionRange(t *testing.T) {
+	for _, c := range []struct {
+		r       string
+		in, out []string
+	}{
+		{"[1.0,2.0)", []string{"1.0", "1.5", "2.0-SNAPSHOT"}, []string{"0.9", "2.0", "2.1"}},
+		{"(1.0,2.0]", []string{"1.0.1", "2.0"}, []string{"1.0", "2.0.1"}},
+		{"[1.5]", []string{"1.5", "1.5.0"}, []string{"1.5.1"}},
+		{"(,1.0],[1.2,)", []string{"0.1", "1.0", "1.2", "10"}, []string{"1.1"}},
+	} {
+		ranges, err := parseVersionRange(c.r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		contains := func(v string) bool {
+			for _, r := range ranges {
+				if r.contains(v) {
+					return true
+				}
+			}
+			return false
+		}
+		for _, v := range c.in {
+			assert.True(t, contains(v), "%s in %s", v, c.r)
+		}
+		for _, v := range c.out {
+			assert.False(t, contains(v), "%s not in %s", v, c.r)
+		}
+	}
+	for _, invalid := range []string{"", "1.0", "[1.0,2.0", "(1.0)", "[1,2,3]", "[]"} {
+		_, err := parseVersionRange(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func parseUpdateRulesFile(t *testing.T, path string) *UpdateRules {
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	rules, err := ParseUpdateRules(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rules
+}
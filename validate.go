@@ -0,0 +1,289 @@
+package gopom
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Severity is the severity of a Problem.
+type Severity int
+
+const (
+	SeverityError Severity = iota
+	SeverityWarning
+	SeverityInfo
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	case SeverityInfo:
+		return "info"
+	}
+	return fmt.Sprintf("Severity(%d)", int(s))
+}
+
+// Problem is an issue found in a POM.
+type Problem struct {
+	Severity Severity
+	// Path is the path of the offending element, in the form used to attach
+	// comments, e.g. "project/dependencies[0]/dependency[1]/version[0]".
+	Path    string
+	Message string
+	// Rule is the ID of the rule that reported the problem, see
+	// ValidationRules and LintRules.
+	Rule string
+}
+
+// Rule describes a check of Validate or Lint, for reports.
+type Rule struct {
+	// ID identifies the rule in problems, configurations and suppressions.
+	ID          string
+	Description string
+	// Severity is the default severity of the problems of the rule.
+	Severity Severity
+}
+
+func (p Problem) String() string {
+	if p.Rule != "" {
+		return fmt.Sprintf("%s: %s [%s]", p.Severity, p.Message, p.Rule)
+	}
+	return fmt.Sprintf("%s: %s", p.Severity, p.Message)
+}
+
+var validID = regexp.MustCompile(`^[A-Za-z0-9_\-.]+$`)
+
+var validScopes = map[string]bool{
+	"compile":  true,
+	"provided": true,
+	"runtime":  true,
+	"test":     true,
+	"system":   true,
+	"import":   true,
+}
+
+// Validate checks p against the rules Maven applies to the POMs it reads,
+// such as required coordinates, valid scopes and unique declarations.
+// Rules that only make sense on the effective model, like dependencies
+// missing a version, are left out; run Validate on the result of Effective
+// to check them as well.
+func (p *Project) Validate() []Problem {
+	v := validator{}
+	v.project(p)
+	return v.problems
+}
+
+// ValidationRules returns the rules of Validate.
+func ValidationRules() []Rule {
+	return []Rule{
+		{ID: "model-version", Severity: SeverityError, Description: "The model version must be 4.0.0."},
+		{ID: "required", Severity: SeverityError, Description: "Coordinates and other required elements must be present."},
+		{ID: "invalid-id", Severity: SeverityError, Description: "Group and artifact IDs may only contain letters, digits, '_', '-' and '.'."},
+		{ID: "parent-self", Severity: SeverityError, Description: "A project cannot be its own parent."},
+		{ID: "aggregator-packaging", Severity: SeverityError, Description: "Projects with modules must have the pom packaging."},
+		{ID: "duplicate-module", Severity: SeverityError, Description: "Modules must be listed once."},
+		{ID: "duplicate-declaration", Severity: SeverityWarning, Description: "Dependencies and plugins must be declared once."},
+		{ID: "invalid-scope", Severity: SeverityError, Description: "Dependency scopes must be valid, and import only applies to managed pom dependencies."},
+		{ID: "system-path", Severity: SeverityError, Description: "Only system dependencies may have a systemPath."},
+		{ID: "duplicate-id", Severity: SeverityError, Description: "Profile and repository IDs must be unique."},
+	}
+}
+
+type validator struct {
+	problems []Problem
+}
+
+func (v *validator) add(severity Severity, rule, path, format string, args ...any) {
+	v.problems = append(v.problems, Problem{
+		Severity: severity,
+		Path:     path,
+		Message:  fmt.Sprintf(format, args...),
+		Rule:     rule,
+	})
+}
+
+// required reports value as missing when it is empty.
+func (v *validator) required(path, field, value, context string) bool {
+	if strings.TrimSpace(value) != "" {
+		return true
+	}
+	v.add(SeverityError, "required", path, "'%s'%s is missing.", field, context)
+	return false
+}
+
+// id checks that value is a valid groupId or artifactId.
+func (v *validator) id(path, field, value string) {
+	if strings.Contains(value, "${") || validID.MatchString(value) {
+		return
+	}
+	v.add(SeverityError, "invalid-id", path, "'%s' with value '%s' does not match a valid id pattern.", field, value)
+}
+
+func (v *validator) project(p *Project) {
+	const root = "project"
+	if v.required(root+"/modelVersion[0]", "modelVersion", p.ModelVersion, "") && p.ModelVersion != "4.0.0" {
+		v.add(SeverityError, "model-version", root+"/modelVersion[0]", "'modelVersion' must be one of [4.0.0] but is '%s'.", p.ModelVersion)
+	}
+	if v.required(root+"/groupId[0]", "groupId", effectiveGroupID(p), "") {
+		v.id(root+"/groupId[0]", "groupId", effectiveGroupID(p))
+	}
+	if v.required(root+"/artifactId[0]", "artifactId", p.ArtifactID, "") {
+		v.id(root+"/artifactId[0]", "artifactId", p.ArtifactID)
+	}
+	v.required(root+"/version[0]", "version", effectiveVersion(p), "")
+
+	if p.Parent != nil {
+		path := root + "/parent[0]"
+		v.required(path+"/groupId[0]", "parent.groupId", p.Parent.GroupID, "")
+		v.required(path+"/artifactId[0]", "parent.artifactId", p.Parent.ArtifactID, "")
+		v.required(path+"/version[0]", "parent.version", p.Parent.Version, "")
+		if p.Parent.GroupID == effectiveGroupID(p) && p.Parent.ArtifactID == p.ArtifactID {
+			v.add(SeverityError, "parent-self", path, "The parent element cannot have the same groupId:artifactId as the project.")
+		}
+	}
+
+	if p.Modules != nil && len(*p.Modules) > 0 && p.Packaging != "pom" {
+		v.add(SeverityError, "aggregator-packaging", root+"/packaging[0]", "Aggregator projects require 'pom' as packaging.")
+	}
+	v.modules(root+"/modules[0]", p.Modules)
+
+	v.dependencies(root+"/dependencies[0]", "dependencies.dependency", p.Dependencies, false)
+	if p.DependencyManagement != nil {
+		v.dependencies(root+"/dependencyManagement[0]/dependencies[0]", "dependencyManagement.dependencies.dependency", p.DependencyManagement.Dependencies, true)
+	}
+	v.repositories(root+"/repositories[0]", "repositories.repository", "repository", p.Repositories)
+	v.pluginRepositories(root+"/pluginRepositories[0]", p.PluginRepositories)
+	if p.Build != nil {
+		v.build(root+"/build[0]", "build", &p.Build.BuildBase)
+	}
+
+	if p.Profiles != nil {
+		ids := map[string]bool{}
+		for i, profile := range *p.Profiles {
+			path := elementPath(root+"/profiles[0]", "profile", i)
+			if v.required(path+"/id[0]", "profiles.profile.id", profile.ID, "") {
+				if ids[profile.ID] {
+					v.add(SeverityError, "duplicate-id", path+"/id[0]", "'profiles.profile.id' must be unique but found duplicate profile with id %s", profile.ID)
+				}
+				ids[profile.ID] = true
+			}
+			v.modules(path+"/modules[0]", profile.Modules)
+			v.dependencies(path+"/dependencies[0]", "profiles.profile["+profile.ID+"].dependencies.dependency", profile.Dependencies, false)
+			if profile.DependencyManagement != nil {
+				v.dependencies(path+"/dependencyManagement[0]/dependencies[0]", "profiles.profile["+profile.ID+"].dependencyManagement.dependencies.dependency", profile.DependencyManagement.Dependencies, true)
+			}
+			v.repositories(path+"/repositories[0]", "profiles.profile["+profile.ID+"].repositories.repository", "repository", profile.Repositories)
+			if profile.Build != nil {
+				v.build(path+"/build[0]", "profiles.profile["+profile.ID+"].build", profile.Build)
+			}
+		}
+	}
+}
+
+func (v *validator) modules(parent string, modules *[]string) {
+	if modules == nil {
+		return
+	}
+	seen := map[string]bool{}
+	for i, m := range *modules {
+		if seen[m] {
+			v.add(SeverityError, "duplicate-module", elementPath(parent, "module", i), "'modules.module[%d]' specifies duplicate child module %s", i, m)
+		}
+		seen[m] = true
+	}
+}
+
+func (v *validator) dependencies(parent, field string, deps *[]Dependency, managed bool) {
+	if deps == nil {
+		return
+	}
+	seen := map[string]bool{}
+	for i, d := range *deps {
+		path := elementPath(parent, "dependency", i)
+		context := " for " + d.GroupID + ":" + d.ArtifactID
+		if v.required(path+"/groupId[0]", field+".groupId", d.GroupID, context) {
+			v.id(path+"/groupId[0]", field+".groupId", d.GroupID)
+		}
+		if v.required(path+"/artifactId[0]", field+".artifactId", d.ArtifactID, context) {
+			v.id(path+"/artifactId[0]", field+".artifactId", d.ArtifactID)
+		}
+
+		key := dependencyKey(d)
+		if seen[key] {
+			v.add(SeverityWarning, "duplicate-declaration", path, "'%s.(groupId:artifactId:type:classifier)' must be unique: %s -> duplicate declaration of version %s", field, key, d.Version)
+		}
+		seen[key] = true
+
+		switch {
+		case d.Scope == "" || strings.Contains(d.Scope, "${"):
+		case !validScopes[d.Scope]:
+			v.add(SeverityError, "invalid-scope", path+"/scope[0]", "'%s.scope'%s has an invalid value '%s'.", field, context, d.Scope)
+		case d.Scope == "import" && (!managed || d.Type != "pom"):
+			v.add(SeverityError, "invalid-scope", path+"/scope[0]", "'%s.scope'%s: the 'import' scope is only valid for dependencies of type 'pom' in dependencyManagement.", field, context)
+		}
+		if d.Scope == "system" && d.SystemPath == "" {
+			v.add(SeverityError, "required", path, "'%s.systemPath'%s is missing.", field, context)
+		}
+		if d.SystemPath != "" && d.Scope != "system" && !managed {
+			v.add(SeverityError, "system-path", path+"/systemPath[0]", "'%s.systemPath'%s must be omitted. This field may only be specified for a dependency with system scope.", field, context)
+		}
+	}
+}
+
+func (v *validator) repositories(parent, field, name string, repos *[]Repository) {
+	if repos == nil {
+		return
+	}
+	seen := map[string]bool{}
+	for i, r := range *repos {
+		path := elementPath(parent, name, i)
+		if v.required(path+"/id[0]", field+".id", r.ID, "") {
+			if seen[r.ID] {
+				v.add(SeverityError, "duplicate-id", path+"/id[0]", "'%s.id' must be unique: %s -> %s", field, r.ID, r.URL)
+			}
+			seen[r.ID] = true
+		}
+		v.required(path+"/url[0]", field+".url", r.URL, " for "+r.ID)
+	}
+}
+
+func (v *validator) pluginRepositories(parent string, repos *[]PluginRepository) {
+	if repos == nil {
+		return
+	}
+	converted := make([]Repository, 0, len(*repos))
+	for _, r := range *repos {
+		converted = append(converted, Repository{ID: r.ID, URL: r.URL})
+	}
+	v.repositories(parent, "pluginRepositories.pluginRepository", "pluginRepository", &converted)
+}
+
+func (v *validator) build(parent, field string, b *BuildBase) {
+	v.plugins(parent+"/plugins[0]", field+".plugins.plugin", b.Plugins)
+	if b.PluginManagement != nil {
+		v.plugins(parent+"/pluginManagement[0]/plugins[0]", field+".pluginManagement.plugins.plugin", b.PluginManagement.Plugins)
+	}
+}
+
+func (v *validator) plugins(parent, field string, plugins *[]Plugin) {
+	if plugins == nil {
+		return
+	}
+	seen := map[string]bool{}
+	for i, p := range *plugins {
+		path := elementPath(parent, "plugin", i)
+		if !v.required(path+"/artifactId[0]", field+".artifactId", p.ArtifactID, " for "+p.GroupID+":") {
+			continue
+		}
+		key := pluginKey(p)
+		if seen[key] {
+			v.add(SeverityWarning, "duplicate-declaration", path, "'%s.(groupId:artifactId)' must be unique but found duplicate declaration of plugin %s", field, key)
+		}
+		seen[key] = true
+		v.dependencies(path+"/dependencies[0]", field+".dependencies.dependency", p.Dependencies, false)
+	}
+}
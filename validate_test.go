@@ -0,0 +1,42 @@
+package gopom
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	p, err := Parse("./testdata/invalid.xml")
+	if err != nil {
+		t.Fatalf("failed parsing the file: %v", err)
+	}
+	var got []string
+	for _, problem := range p.Validate() {
+		got = append(got, problem.Path+" "+problem.String())
+	}
+	assert.Equal(t, []string{
+		"project/modelVersion[0] error: 'modelVersion' must be one of [4.0.0] but is '4.0.1'. [model-version]",
+		"project/groupId[0] error: 'groupId' with value 'com example' does not match a valid id pattern. [invalid-id]",
+		"project/version[0] error: 'version' is missing. [required]",
+		"project/packaging[0] error: Aggregator projects require 'pom' as packaging. [aggregator-packaging]",
+		"project/modules[0]/module[1] error: 'modules.module[1]' specifies duplicate child module a [duplicate-module]",
+		"project/dependencies[0]/dependency[0]/groupId[0] error: 'dependencies.dependency.groupId' for :no-group is missing. [required]",
+		"project/dependencies[0]/dependency[1]/scope[0] error: 'dependencies.dependency.scope' for com.example:lib has an invalid value 'compiled'. [invalid-scope]",
+		"project/dependencies[0]/dependency[2] warning: 'dependencies.dependency.(groupId:artifactId:type:classifier)' must be unique: com.example:lib:jar: -> duplicate declaration of version 1.1 [duplicate-declaration]",
+		"project/dependencies[0]/dependency[3] error: 'dependencies.dependency.systemPath' for com.sun:tools is missing. [required]",
+		"project/repositories[0]/repository[0]/url[0] error: 'repositories.repository.url' for central is missing. [required]",
+		"project/build[0]/plugins[0]/plugin[0]/artifactId[0] error: 'build.plugins.plugin.artifactId' for org.example: is missing. [required]",
+		"project/profiles[0]/profile[1]/id[0] error: 'profiles.profile.id' must be unique but found duplicate profile with id dup [duplicate-id]",
+	}, got)
+}
+
+func TestValidateValid(t *testing.T) {
+	for _, path := range []string{commentsFilename, "./testdata/reactor/pom.xml", "./testdata/effective/child/pom.xml"} {
+		p, err := Parse(path)
+		if err != nil {
+			t.Fatalf("failed parsing the file: %v", err)
+		}
+		assert.Empty(t, p.Validate(), path)
+	}
+}
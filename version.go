@@ -0,0 +1,262 @@
+package gopom
+
+import (
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// CompareVersions compares two Maven versions the way Maven orders them,
+// returning -1, 0 or 1. Versions are split into numbers and qualifiers at
+// dots, dashes and transitions between digits and letters. Numbers compare
+// numerically, well-known qualifiers in the order alpha < beta < milestone
+// < rc < snapshot < release < sp, with the usual aliases like cr for rc or
+// final for a release, and other qualifiers come last, alphabetically.
+// Trailing zeros and release qualifiers do not count, so 1.0.0, 1 and
+// 1-final are the same version.
+func CompareVersions(a, b string) int {
+	return parseVersion(a).compare(parseVersion(b))
+}
+
+// versionItem is a part of a parsed version: a *big.Int number, a string
+// qualifier or a versionList.
+type versionItem interface {
+	// compare compares the item with another one, which may be nil when
+	// the other version has fewer items.
+	compare(other versionItem) int
+	isNull() bool
+}
+
+type versionInt struct{ n *big.Int }
+
+func (i versionInt) isNull() bool { return i.n.Sign() == 0 }
+
+func (i versionInt) compare(other versionItem) int {
+	switch o := other.(type) {
+	case nil:
+		if i.isNull() {
+			return 0
+		}
+		return 1
+	case versionInt:
+		return i.n.Cmp(o.n)
+	}
+	return 1
+}
+
+type versionString struct{ s string }
+
+// versionQualifiers are the well-known qualifiers, in order. The empty
+// string stands for releases.
+var versionQualifiers = []string{"alpha", "beta", "milestone", "rc", "snapshot", "", "sp"}
+
+var versionAliases = map[string]string{"ga": "", "final": "", "release": "", "cr": "rc"}
+
+func newVersionString(s string, followedByDigit bool) versionString {
+	if followedByDigit && len(s) == 1 {
+		switch s {
+		case "a":
+			s = "alpha"
+		case "b":
+			s = "beta"
+		case "m":
+			s = "milestone"
+		}
+	}
+	if alias, ok := versionAliases[s]; ok {
+		s = alias
+	}
+	return versionString{s}
+}
+
+// comparable returns a key ordering qualifiers: their index for the
+// well-known ones, and the qualifier after the last index otherwise.
+func (s versionString) comparable() string {
+	for i, q := range versionQualifiers {
+		if q == s.s {
+			return strconv.Itoa(i)
+		}
+	}
+	return strconv.Itoa(len(versionQualifiers)) + "-" + s.s
+}
+
+func (s versionString) isNull() bool { return s.s == "" }
+
+func (s versionString) compare(other versionItem) int {
+	switch o := other.(type) {
+	case nil:
+		return strings.Compare(s.comparable(), versionString{}.comparable())
+	case versionString:
+		return strings.Compare(s.comparable(), o.comparable())
+	}
+	return -1
+}
+
+type versionList []versionItem
+
+func (l versionList) isNull() bool { return len(l) == 0 }
+
+func (l versionList) compare(other versionItem) int {
+	switch o := other.(type) {
+	case nil:
+		if len(l) == 0 {
+			return 0
+		}
+		return l[0].compare(nil)
+	case versionInt:
+		return -1
+	case versionString:
+		return 1
+	case versionList:
+		for i := 0; i < len(l) || i < len(o); i++ {
+			var left, right versionItem
+			if i < len(l) {
+				left = l[i]
+			}
+			if i < len(o) {
+				right = o[i]
+			}
+			var c int
+			if left == nil {
+				if right != nil {
+					c = -right.compare(nil)
+				}
+			} else {
+				c = left.compare(right)
+			}
+			if c != 0 {
+				return c
+			}
+		}
+	}
+	return 0
+}
+
+// normalize removes the trailing null items of l.
+func (l *versionList) normalize() {
+	for i := len(*l) - 1; i >= 0; i-- {
+		item := (*l)[i]
+		if item.isNull() {
+			*l = append((*l)[:i], (*l)[i+1:]...)
+		} else if _, ok := item.(*versionList); !ok {
+			break
+		}
+	}
+}
+
+// parseVersion parses a version into nested lists: a new list starts at
+// each dash and at each transition between digits and letters.
+func parseVersion(version string) versionList {
+	version = strings.ToLower(version)
+	root := &versionList{}
+	list := root
+	stack := []*versionList{root}
+	parseItem := func(digits bool, s string) versionItem {
+		if digits {
+			n, _ := new(big.Int).SetString(s, 10)
+			return versionInt{n}
+		}
+		return newVersionString(s, false)
+	}
+	push := func() {
+		sub := &versionList{}
+		*list = append(*list, sub)
+		list = sub
+		stack = append(stack, sub)
+	}
+
+	digits := false
+	start := 0
+	for i := 0; i < len(version); i++ {
+		c := version[i]
+		switch {
+		case c == '.' || c == '-':
+			if i == start {
+				*list = append(*list, versionInt{new(big.Int)})
+			} else {
+				*list = append(*list, parseItem(digits, version[start:i]))
+			}
+			start = i + 1
+			if c == '-' {
+				push()
+			}
+		case '0' <= c && c <= '9':
+			if !digits && i > start {
+				*list = append(*list, newVersionString(version[start:i], true))
+				start = i
+				push()
+			}
+			digits = true
+		default:
+			if digits && i > start {
+				*list = append(*list, parseItem(true, version[start:i]))
+				start = i
+				push()
+			}
+			digits = false
+		}
+	}
+	if len(version) > start {
+		*list = append(*list, parseItem(digits, version[start:]))
+	}
+	for i := len(stack) - 1; i >= 0; i-- {
+		stack[i].normalize()
+	}
+	return derefVersionLists(*root)
+}
+
+// derefVersionLists replaces the *versionList items of l, needed while
+// parsing, by versionList values.
+func derefVersionLists(l versionList) versionList {
+	out := make(versionList, len(l))
+	for i, item := range l {
+		if sub, ok := item.(*versionList); ok {
+			item = derefVersionLists(*sub)
+		}
+		out[i] = item
+	}
+	return out
+}
+
+// IsPreRelease tells whether version has an alpha, beta, milestone, release
+// candidate or SNAPSHOT qualifier, like 2.0-RC1, 1.0-M2 or 3.0-SNAPSHOT.
+func IsPreRelease(version string) bool {
+	return parseVersion(version).preRelease()
+}
+
+func (l versionList) preRelease() bool {
+	for _, item := range l {
+		switch i := item.(type) {
+		case versionString:
+			for _, q := range versionQualifiers {
+				if q == "" {
+					break
+				}
+				if q == i.s {
+					return true
+				}
+			}
+		case versionList:
+			if i.preRelease() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// versionSegment returns the i-th number of version, like 2 for the minor
+// version, at index 1, of 1.2.3, or 0 when there is none.
+func versionSegment(version string, i int) *big.Int {
+	l := parseVersion(version)
+	for j, item := range l {
+		n, ok := item.(versionInt)
+		if !ok {
+			break
+		}
+		if j == i {
+			return n.n
+		}
+	}
+	return new(big.Int)
+}
@@ -0,0 +1,55 @@
+package gopom
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompareVersionsOrder(t *testing.T) {
+	for _, versions := range [][]string{
+		{
+			"1-alpha2snapshot", "1-alpha2", "1-alpha-123", "1-beta-2", "1-beta123", "1-m2", "1-m11",
+			"1-rc", "1-cr2", "1-rc123", "1-SNAPSHOT", "1", "1-sp", "1-sp2", "1-sp123", "1-abc",
+			"1-def", "1-pom-1", "1-1-snapshot", "1-1", "1-2", "1-123",
+		},
+		{
+			"2.0", "2-1", "2.0.a", "2.0.0.a", "2.0.2", "2.0.123", "2.1.0", "2.1-a", "2.1b", "2.1-c",
+			"2.1-1", "2.1.0.1", "2.2", "2.123", "11.a2", "11.a11", "11.b2", "11.b11", "11.m2",
+			"11.m11", "11", "11.a", "11b", "11c", "11m",
+		},
+		{"1.0-SNAPSHOT", "1.0", "1.0.1", "1.10", "10.0", "100000000000000000000"},
+	} {
+		for i := 1; i < len(versions); i++ {
+			for j := 0; j < i; j++ {
+				assert.Equal(t, -1, CompareVersions(versions[j], versions[i]), "%s < %s", versions[j], versions[i])
+				assert.Equal(t, 1, CompareVersions(versions[i], versions[j]), "%s > %s", versions[i], versions[j])
+			}
+		}
+	}
+}
+
+func TestCompareVersionsEqual(t *testing.T) {
+	for _, versions := range [][]string{
+		{"1", "1.0", "1.0.0", "1-0", "1.0-0", "1ga", "1-ga", "1.0.ga", "1-final", "1.0-FINAL", "1-release"},
+		{"1a1", "1-a1", "1.0-a1", "1-alpha-1", "1alpha1", "1-ALPHA1"},
+		{"1b2", "1-beta-2", "1.0.0-BETA2"},
+		{"1m3", "1-milestone-3", "1-M3"},
+		{"1rc", "1cr", "1-rc", "1.0-CR"},
+		{"1x", "1-x", "1.0-x", "1.0.0-x"},
+		{"", "0"},
+	} {
+		for _, v := range versions[1:] {
+			assert.Equal(t, 0, CompareVersions(versions[0], v), "%s = %s", versions[0], v)
+		}
+	}
+}
+
+func TestIsPreRelease(t *testing.T) {
+	for _, v := range []string{"1.0-alpha", "1.0-a1", "2.0.0-beta-2", "1.0.0.Beta1", "1-M3", "1.0-milestone-1", "2.0-RC1", "2.0-cr2", "1.0-SNAPSHOT", "1.0-alpha-1-SNAPSHOT"} {
+		assert.True(t, IsPreRelease(v), v)
+	}
+	for _, v := range []string{"1.0", "1.0-final", "1.0.GA", "1-sp1", "32.1.3-jre", "2.0.a", "1.0-1", ""} {
+		assert.False(t, IsPreRelease(v), v)
+	}
+}